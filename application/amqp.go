@@ -5,6 +5,11 @@ import (
 	"fmt"
 	"github.com/streadway/amqp"
 	"go-web-crawler-service/domain"
+	"go-web-crawler-service/infrastructure"
+	"go-web-crawler-service/metrics"
+	"go-web-crawler-service/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"log"
 	"time"
 )
@@ -13,47 +18,101 @@ const (
 	consumerTag = "web-crawler"
 )
 
-var (
-	rateLimitMilliseconds = 200
-)
+// laneConsumerTag is a tag of the consumer of the lane, every lane is consumed separately
+func laneConsumerTag(priority domain.Priority) string {
+	return consumerTag + "." + string(priority)
+}
+
+type retryPublisher interface {
+	Retry(d amqp.Delivery, reason error) error
+	Park(d amqp.Delivery, reason error) error
+}
 
 type amqpApp struct {
-	ch            *amqp.Channel
-	queueName     string
-	processor     domain.ChannelCrawlerProcessor
+	ch             *amqp.Channel
+	queueName      string
+	processor      domain.ChannelCrawlerProcessor
+	jobRepository  domain.JobRepository
+	retryPublisher retryPublisher
+	// rateLimiter spaces the crawls of the same host, nil rate limiter disables it
+	rateLimiter   domain.HostRateLimiter
+	maxAttempts   int
 	workersAmount int
+	// laneWeights are shares of the turns of the lanes, see laneSelector
+	laneWeights map[domain.Priority]int
+	// queueDepthInterval is how often the depth of the lanes is exported, 0 disables it
+	queueDepthInterval time.Duration
 }
 
 func NewAmqpApplication(
 	ch *amqp.Channel,
 	queueName string,
 	processor domain.ChannelCrawlerProcessor,
+	jobRepository domain.JobRepository,
+	retryPublisher retryPublisher,
+	rateLimiter domain.HostRateLimiter,
+	maxAttempts int,
 	workersAmount int,
+	laneWeights map[domain.Priority]int,
+	queueDepthInterval time.Duration,
 ) *amqpApp {
-	return &amqpApp{ch: ch, queueName: queueName, processor: processor, workersAmount: workersAmount}
+	return &amqpApp{
+		ch:                 ch,
+		queueName:          queueName,
+		processor:          processor,
+		jobRepository:      jobRepository,
+		retryPublisher:     retryPublisher,
+		rateLimiter:        rateLimiter,
+		maxAttempts:        maxAttempts,
+		workersAmount:      workersAmount,
+		laneWeights:        laneWeights,
+		queueDepthInterval: queueDepthInterval,
+	}
 }
 
 func (a *amqpApp) Run(ctx context.Context, notifyStart func(), notifyEnd func()) error {
 	log.Println("Starting Crawler worker")
 
-	urlsToProcess, err := a.ch.Consume(a.queueName, consumerTag, false, false, false, false, nil)
+	// Every lane consumer holds at most as many messages as there are workers, the rest waits in the queue,
+	// so the depth of the lanes is visible and the workers of other replicas could take them
+	err := a.ch.Qos(a.workersAmount, 0, false)
 	if err != nil {
-		return fmt.Errorf("failed to spawn a consumer, %w", err)
+		return fmt.Errorf("failed to set prefetch, %w", err)
+	}
+
+	lanes := make([]lane, 0, len(domain.Priorities))
+	for _, priority := range domain.Priorities {
+		queueName := infrastructure.LaneQueueName(a.queueName, priority)
+		deliveries, err := a.ch.Consume(queueName, laneConsumerTag(priority), false, false, false, false, nil)
+		if err != nil {
+			return fmt.Errorf("failed to spawn a consumer of %s queue, %w", queueName, err)
+		}
+
+		lanes = append(lanes, lane{priority: priority, weight: a.laneWeights[priority], deliveries: deliveries})
 	}
 
 	go func() {
 		<-ctx.Done()
-		_ = a.ch.Cancel(consumerTag, false)
+		for _, priority := range domain.Priorities {
+			_ = a.ch.Cancel(laneConsumerTag(priority), false)
+		}
 	}()
 
+	if a.queueDepthInterval > 0 {
+		notifyStart()
+		go func() {
+			defer notifyEnd()
+			a.monitorQueueDepth(ctx)
+		}()
+	}
+
 	log.Printf("Spawning %d workers\n", a.workersAmount)
 
-	rateLimiter := time.Tick(time.Duration(rateLimitMilliseconds) * time.Millisecond)
 	for i := 0; i < a.workersAmount; i++ {
 		notifyStart()
 		go func() {
 			defer notifyEnd()
-			a.spawnConsumer(ctx, urlsToProcess, rateLimiter)
+			a.spawnConsumer(ctx, newLaneSelector(lanes))
 			log.Println("channel closed")
 		}()
 	}
@@ -61,41 +120,202 @@ func (a *amqpApp) Run(ctx context.Context, notifyStart func(), notifyEnd func())
 	return nil
 }
 
-func (a *amqpApp) spawnConsumer(ctx context.Context, urlsToProcess <-chan amqp.Delivery, rateLimiter <-chan time.Time) {
-	for d := range urlsToProcess {
-		url, err := domain.NewURL(string(d.Body))
+func (a *amqpApp) spawnConsumer(ctx context.Context, lanes *laneSelector) {
+	for {
+		d, open := lanes.receive()
+		if !open {
+			return
+		}
+
+		request, err := infrastructure.NewCrawlRequestFromDelivery(d)
 		if err != nil {
-			nackErr := d.Nack(false, false)
+			ackErr := a.parkInvalid(d, err)
+			if ackErr != nil {
+				log.Println("failed to ack/nack message")
+			}
+			continue
+		}
+
+		err = a.waitForHost(ctx, request.Url.Host())
+		if err != nil {
+			// Worker is stopping, the message is left to another worker
+			nackErr := nack(d, true)
 			if nackErr != nil {
 				log.Println("failed to ack/nack message")
 			}
+			continue
 		}
 
-		<-rateLimiter
+		a.consume(ctx, d, *request)
+	}
+}
 
-		log.Printf("Starting processing message with url: %s\n", *url)
+// monitorQueueDepth exports amount of the messages waiting in every lane until the context is done,
+// the growing depth of the low priority lanes shows they are starved
+func (a *amqpApp) monitorQueueDepth(ctx context.Context) {
+	ticker := time.NewTicker(a.queueDepthInterval)
+	defer ticker.Stop()
 
-		start := time.Now()
-		processErr := a.processor.Crawl(ctx, *url)
-		elapsed := time.Since(start)
+	for {
+		for _, priority := range domain.Priorities {
+			queue, err := a.ch.QueueInspect(infrastructure.LaneQueueName(a.queueName, priority))
+			if err != nil {
+				log.Printf("Could not inspect %s lane, %v\n", priority, err)
+				continue
+			}
 
-		log.Printf("Processing message with url: %s took %s\n", *url, elapsed)
+			metrics.QueueDepth.WithLabelValues(string(priority)).Set(float64(queue.Messages))
+		}
 
-		var ackErr error
-		if processErr != nil {
-			log.Printf("Failed to consume a message with url, %v\n", processErr)
-			ackErr = d.Nack(
-				false,
-				false,
-			) // Message could end up in dead letter queue, we could also configure messages to be rerouted to the processor queue after some time.
-			// It mostly fails because of timeouts
-		} else {
-			log.Printf("Successfully processed message with url: %s\n", *url)
-			ackErr = d.Ack(false)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
 		}
+	}
+}
+
+// consume crawls the requested url in the span continuing the trace of the request publisher
+func (a *amqpApp) consume(ctx context.Context, d amqp.Delivery, request domain.CrawlRequest) {
+	ctx, span := tracing.Tracer().Start(
+		infrastructure.ExtractTraceContext(ctx, d),
+		"consume crawl request",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("url", string(request.Url)),
+			attribute.String("priority", string(request.Priority)),
+			attribute.Int("attempt", infrastructure.DeliveryAttempt(d)),
+		),
+	)
+
+	log.Printf("Starting processing message with url: %s\n", request.Url)
+
+	metrics.WorkersInFlight.Inc()
+	start := time.Now()
+	processErr := a.processor.Crawl(ctx, request)
+	elapsed := time.Since(start)
+	metrics.WorkersInFlight.Dec()
+	a.observeHost(ctx, request.Url.Host(), elapsed, processErr)
+
+	log.Printf("Processing message with url: %s took %s\n", request.Url, elapsed)
+	metrics.CrawlDuration.WithLabelValues(crawlOutcome(processErr)).Observe(elapsed.Seconds())
+
+	var ackErr error
+	if processErr != nil {
+		log.Printf("Failed to consume a message with url, kind: %s, %v\n", domain.CrawlErrorKindOf(processErr), processErr)
+		ackErr = a.handleFailure(ctx, d, request, processErr)
+	} else {
+		log.Printf("Successfully processed message with url: %s\n", request.Url)
+		ackErr = ack(d)
+	}
+
+	if ackErr != nil {
+		log.Println("failed to ack/nack message")
+	}
+
+	tracing.End(span, processErr)
+}
+
+func (a *amqpApp) waitForHost(ctx context.Context, host string) error {
+	if a.rateLimiter == nil {
+		return nil
+	}
+
+	return a.rateLimiter.Wait(ctx, host)
+}
+
+func (a *amqpApp) observeHost(ctx context.Context, host string, latency time.Duration, processErr error) {
+	if a.rateLimiter == nil {
+		return
+	}
+
+	a.rateLimiter.Observe(ctx, host, latency, processErr)
+}
+
+// handleFailure moves failed message to the delayed retry queue, or parks it when the failure is permanent
+// or the message ran out of attempts. Original message is acknowledged only when its copy was published,
+// otherwise it's requeued.
+func (a *amqpApp) handleFailure(
+	ctx context.Context,
+	d amqp.Delivery,
+	request domain.CrawlRequest,
+	processErr error,
+) error {
+	attempt := infrastructure.DeliveryAttempt(d)
 
-		if ackErr != nil {
-			log.Println("failed to ack/nack message")
+	if isRetryable(processErr) && attempt < a.maxAttempts {
+		err := a.retryPublisher.Retry(d, processErr)
+		if err != nil {
+			log.Printf("Could not schedule retry of url %s, %v\n", request.Url, err)
+			return nack(d, true)
 		}
+
+		log.Printf("Scheduled retry %d of url: %s\n", attempt, request.Url)
+		domain.UpdateJobStatus(ctx, a.jobRepository, request.JobID, domain.JobStatusQueued, processErr.Error())
+		return ack(d)
+	}
+
+	err := a.retryPublisher.Park(d, processErr)
+	if err != nil {
+		log.Printf("Could not park url %s, %v\n", request.Url, err)
+		return nack(d, true)
 	}
+
+	log.Printf("Parked url: %s after %d attempts\n", request.Url, attempt)
+	domain.UpdateJobStatus(ctx, a.jobRepository, request.JobID, domain.JobStatusFailed, processErr.Error())
+	return ack(d)
+}
+
+// parkInvalid parks the message that could not be decoded, it's acknowledged only when its copy was published,
+// otherwise it's requeued
+func (a *amqpApp) parkInvalid(d amqp.Delivery, decodeErr error) error {
+	log.Printf("Parking invalid message, %v\n", decodeErr)
+
+	err := a.retryPublisher.Park(d, domain.NewCrawlError(domain.CrawlErrorInvalidData, decodeErr))
+	if err != nil {
+		log.Printf("Could not park invalid message, %v\n", err)
+		return nack(d, true)
+	}
+
+	return ack(d)
+}
+
+// isRetryable tells whether crawling the url again could succeed, it depends on the kind of the failure
+func isRetryable(err error) bool {
+	return domain.CrawlErrorKindOf(err).IsRetryable()
+}
+
+func crawlOutcome(err error) string {
+	switch {
+	case err == nil:
+		return metrics.OutcomeSuccess
+	case isRetryable(err):
+		return metrics.OutcomeRetryableError
+	default:
+		return metrics.OutcomePermanentError
+	}
+}
+
+func ack(d amqp.Delivery) error {
+	err := d.Ack(false)
+	if err == nil {
+		metrics.Messages.WithLabelValues(metrics.MessageAck).Inc()
+	}
+
+	return err
+}
+
+func nack(d amqp.Delivery, requeue bool) error {
+	err := d.Nack(false, requeue)
+	if err != nil {
+		return err
+	}
+
+	if requeue {
+		metrics.Messages.WithLabelValues(metrics.MessageRequeue).Inc()
+	} else {
+		metrics.Messages.WithLabelValues(metrics.MessageNack).Inc()
+	}
+
+	return nil
 }
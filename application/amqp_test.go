@@ -0,0 +1,161 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go-web-crawler-service/domain"
+	"go-web-crawler-service/infrastructure"
+	"go-web-crawler-service/metrics"
+	"testing"
+)
+
+type retryPublisherMock struct {
+	mock.Mock
+}
+
+func (m *retryPublisherMock) Retry(d amqp.Delivery, reason error) error {
+	return m.Called(d, reason).Error(0)
+}
+
+func (m *retryPublisherMock) Park(d amqp.Delivery, reason error) error {
+	return m.Called(d, reason).Error(0)
+}
+
+type acknowledgerMock struct {
+	mock.Mock
+}
+
+func (m *acknowledgerMock) Ack(tag uint64, multiple bool) error {
+	return m.Called(tag, multiple).Error(0)
+}
+
+func (m *acknowledgerMock) Nack(tag uint64, multiple bool, requeue bool) error {
+	return m.Called(tag, multiple, requeue).Error(0)
+}
+
+func (m *acknowledgerMock) Reject(tag uint64, requeue bool) error {
+	return m.Called(tag, requeue).Error(0)
+}
+
+func TestAmqpApp_HandleFailure(t *testing.T) {
+	timeoutErr := fmt.Errorf("could not crawl channel, error: %w", context.DeadlineExceeded)
+	elementErr := fmt.Errorf("could not crawl channel, error: %w", domain.ErrElementNotFound)
+	blockedErr := domain.NewCrawlError(domain.CrawlErrorBlocked, errors.New("captcha"))
+	notFoundErr := domain.NewCrawlError(domain.CrawlErrorNotFound, errors.New("status code: 404"))
+
+	testCases := []struct {
+		name           string
+		attempt        int32
+		processErr     error
+		expectedMethod string
+	}{
+		{name: "timeout is retried", attempt: 1, processErr: timeoutErr, expectedMethod: "Retry"},
+		{name: "last attempt is parked", attempt: 3, processErr: timeoutErr, expectedMethod: "Park"},
+		{name: "permanent error is parked", attempt: 1, processErr: elementErr, expectedMethod: "Park"},
+		{name: "blocked crawl is retried", attempt: 1, processErr: blockedErr, expectedMethod: "Retry"},
+		{name: "missing page is parked", attempt: 1, processErr: notFoundErr, expectedMethod: "Park"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(
+			testCase.name, func(t *testing.T) {
+				acknowledger := &acknowledgerMock{}
+				acknowledger.On("Ack", uint64(1), false).Return(nil)
+
+				d := amqp.Delivery{
+					Acknowledger: acknowledger,
+					DeliveryTag:  1,
+					Headers:      amqp.Table{infrastructure.AttemptHeader: testCase.attempt},
+					Body:         []byte("https://google.com/"),
+				}
+
+				publisher := &retryPublisherMock{}
+				publisher.On(testCase.expectedMethod, d, testCase.processErr).Return(nil)
+
+				app := NewAmqpApplication(nil, "queue", nil, nil, publisher, nil, 3, 1, nil, 0)
+				err := app.handleFailure(
+					context.Background(),
+					d,
+					*domain.NewCrawlRequest("", "https://google.com/", "", domain.PriorityNormal),
+					testCase.processErr,
+				)
+
+				require.NoError(t, err)
+				publisher.AssertExpectations(t)
+				acknowledger.AssertExpectations(t)
+			},
+		)
+	}
+}
+
+func TestAmqpApp_HandleFailure_RetryNotPublished_RequeuesMessage(t *testing.T) {
+	acknowledger := &acknowledgerMock{}
+	acknowledger.On("Nack", uint64(1), false, true).Return(nil)
+
+	d := amqp.Delivery{Acknowledger: acknowledger, DeliveryTag: 1, Body: []byte("https://google.com/")}
+	processErr := errors.New("crawler error")
+
+	publisher := &retryPublisherMock{}
+	publisher.On("Retry", d, processErr).Return(errors.New("publish error"))
+
+	requeued := testutil.ToFloat64(metrics.Messages.WithLabelValues(metrics.MessageRequeue))
+
+	app := NewAmqpApplication(nil, "queue", nil, nil, publisher, nil, 3, 1, nil, 0)
+	request := domain.NewCrawlRequest("", "https://google.com/", "", domain.PriorityNormal)
+	err := app.handleFailure(context.Background(), d, *request, processErr)
+
+	require.NoError(t, err)
+	acknowledger.AssertExpectations(t)
+	require.Equal(t, requeued+1, testutil.ToFloat64(metrics.Messages.WithLabelValues(metrics.MessageRequeue)))
+}
+
+func TestAmqpApp_ParkInvalid(t *testing.T) {
+	testCases := []struct {
+		name        string
+		parkErr     error
+		expectedAck string
+		args        []interface{}
+	}{
+		{name: "parked message is acknowledged", expectedAck: "Ack", args: []interface{}{uint64(1), false}},
+		{
+			name:        "message not parked is requeued",
+			parkErr:     errors.New("publish error"),
+			expectedAck: "Nack",
+			args:        []interface{}{uint64(1), false, true},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(
+			testCase.name, func(t *testing.T) {
+				acknowledger := &acknowledgerMock{}
+				acknowledger.On(testCase.expectedAck, testCase.args...).Return(nil)
+
+				d := amqp.Delivery{Acknowledger: acknowledger, DeliveryTag: 1, Body: []byte("not-a-url")}
+				decodeErr := errors.New("message contains invalid url")
+
+				publisher := &retryPublisherMock{}
+				publisher.On(
+					"Park", d, mock.MatchedBy(
+						func(reason error) bool {
+							return domain.CrawlErrorKindOf(reason) == domain.CrawlErrorInvalidData &&
+								errors.Is(reason, decodeErr)
+						},
+					),
+				).Return(testCase.parkErr)
+
+				app := NewAmqpApplication(nil, "queue", nil, nil, publisher, nil, 3, 1, nil, 0)
+				err := app.parkInvalid(d, decodeErr)
+
+				require.NoError(t, err)
+				publisher.AssertExpectations(t)
+				acknowledger.AssertExpectations(t)
+			},
+		)
+	}
+}
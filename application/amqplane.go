@@ -0,0 +1,136 @@
+package application
+
+import (
+	"github.com/streadway/amqp"
+	"go-web-crawler-service/domain"
+	"reflect"
+)
+
+// lane is the queue of the crawl requests of one priority
+type lane struct {
+	priority domain.Priority
+	// weight is the share of the turns the lane gets, lane without turns is consumed only when the others are empty
+	weight     int
+	deliveries <-chan amqp.Delivery
+}
+
+// laneSelector picks the lane the worker takes the next message from. Lanes take turns by their weights, lane
+// without a waiting message gives its turn to the others by their priority, so the worker is not idle while
+// any lane has messages and the low priority lane is not starved while the high priority one is busy.
+type laneSelector struct {
+	// lanes are ordered from the highest priority, closed lanes have nil deliveries
+	lanes []lane
+	// turns are indexes of the lanes in the order of their turns
+	turns []int
+	next  int
+}
+
+func newLaneSelector(lanes []lane) *laneSelector {
+	selected := make([]lane, len(lanes))
+	copy(selected, lanes)
+
+	return &laneSelector{lanes: selected, turns: laneTurns(selected)}
+}
+
+// laneTurns spreads the turns of the lanes by the smooth weighted round-robin, e.g. weights 3 and 1
+// give turns 0, 0, 1, 0 instead of 0, 0, 0, 1
+func laneTurns(lanes []lane) []int {
+	total := 0
+	for _, l := range lanes {
+		total += l.weight
+	}
+
+	current := make([]int, len(lanes))
+	turns := make([]int, 0, total)
+	for len(turns) < total {
+		best := -1
+		for i, l := range lanes {
+			current[i] += l.weight
+			if l.weight > 0 && (best < 0 || current[i] > current[best]) {
+				best = i
+			}
+		}
+		current[best] -= total
+		turns = append(turns, best)
+	}
+
+	return turns
+}
+
+// receive returns the next message, false is returned once all the lanes are closed
+func (s *laneSelector) receive() (amqp.Delivery, bool) {
+	for s.hasOpenLanes() {
+		if len(s.turns) > 0 {
+			turn := s.turns[s.next]
+			s.next = (s.next + 1) % len(s.turns)
+
+			if d, ok := s.tryReceive(turn); ok {
+				return d, true
+			}
+		}
+
+		for i := range s.lanes {
+			if d, ok := s.tryReceive(i); ok {
+				return d, true
+			}
+		}
+
+		if d, ok := s.waitForAny(); ok {
+			return d, true
+		}
+	}
+
+	return amqp.Delivery{}, false
+}
+
+// tryReceive takes the message waiting in the lane without blocking
+func (s *laneSelector) tryReceive(i int) (amqp.Delivery, bool) {
+	if s.lanes[i].deliveries == nil {
+		return amqp.Delivery{}, false
+	}
+
+	select {
+	case d, open := <-s.lanes[i].deliveries:
+		if !open {
+			s.lanes[i].deliveries = nil
+			return amqp.Delivery{}, false
+		}
+		return d, true
+	default:
+		return amqp.Delivery{}, false
+	}
+}
+
+// waitForAny blocks until a message arrives to any open lane or one of the lanes is closed
+func (s *laneSelector) waitForAny() (amqp.Delivery, bool) {
+	cases := make([]reflect.SelectCase, 0, len(s.lanes))
+	indexes := make([]int, 0, len(s.lanes))
+	for i, l := range s.lanes {
+		if l.deliveries == nil {
+			continue
+		}
+		cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(l.deliveries)})
+		indexes = append(indexes, i)
+	}
+	if len(cases) == 0 {
+		return amqp.Delivery{}, false
+	}
+
+	chosen, value, open := reflect.Select(cases)
+	if !open {
+		s.lanes[indexes[chosen]].deliveries = nil
+		return amqp.Delivery{}, false
+	}
+
+	return value.Interface().(amqp.Delivery), true
+}
+
+func (s *laneSelector) hasOpenLanes() bool {
+	for _, l := range s.lanes {
+		if l.deliveries != nil {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,73 @@
+package application
+
+import (
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go-web-crawler-service/domain"
+	"testing"
+)
+
+func TestLaneTurns(t *testing.T) {
+	lanes := []lane{
+		{priority: domain.PriorityHigh, weight: 3},
+		{priority: domain.PriorityNormal, weight: 1},
+		{priority: domain.PriorityLow, weight: 0},
+	}
+
+	assert.Equal(t, []int{0, 0, 1, 0}, laneTurns(lanes))
+}
+
+func TestLaneSelector_Receive(t *testing.T) {
+	high := make(chan amqp.Delivery, 10)
+	low := make(chan amqp.Delivery, 10)
+	for i := 0; i < 5; i++ {
+		high <- amqp.Delivery{RoutingKey: "high"}
+		low <- amqp.Delivery{RoutingKey: "low"}
+	}
+	close(high)
+	close(low)
+
+	selector := newLaneSelector(
+		[]lane{
+			{priority: domain.PriorityHigh, weight: 2, deliveries: high},
+			{priority: domain.PriorityLow, weight: 1, deliveries: low},
+		},
+	)
+
+	var received []string
+	for {
+		d, open := selector.receive()
+		if !open {
+			break
+		}
+		received = append(received, d.RoutingKey)
+	}
+
+	// Lanes take turns by their weights until the high priority lane is empty, then the low one takes all the turns
+	assert.Equal(t, []string{"high", "low", "high", "high", "low", "high", "high", "low", "low", "low"}, received)
+}
+
+func TestLaneSelector_Receive_WaitsForAnyLane(t *testing.T) {
+	high := make(chan amqp.Delivery)
+	low := make(chan amqp.Delivery)
+	selector := newLaneSelector(
+		[]lane{
+			{priority: domain.PriorityHigh, weight: 1, deliveries: high},
+			{priority: domain.PriorityLow, weight: 0, deliveries: low},
+		},
+	)
+
+	go func() {
+		low <- amqp.Delivery{RoutingKey: "low"}
+		close(high)
+		close(low)
+	}()
+
+	d, open := selector.receive()
+	require.True(t, open)
+	assert.Equal(t, "low", d.RoutingKey)
+
+	_, open = selector.receive()
+	assert.False(t, open)
+}
@@ -2,46 +2,712 @@ package application
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"go-web-crawler-service/domain"
+	"go-web-crawler-service/metrics"
 	grpcwebcrawler "go-web-crawler-service/protobuf/webcrawler"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"io"
+	"log"
+	"time"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+
+	maxWatchedJobs   = 1000
+	jobWatchInterval = 500 * time.Millisecond
+
+	// Submitted urls are scheduled in batches of up to submitBatchSize urls, batch is scheduled once it's full
+	// or no url was received for submitBatchLinger
+	submitBatchSize   = 100
+	submitBatchLinger = 100 * time.Millisecond
 )
 
 type server struct {
 	grpcwebcrawler.UnimplementedWebCrawlerServiceServer
 	publisher domain.ChannelCrawlerScheduler
+	stores    domain.StoreResolver
+	channels  domain.ChannelQueryRepository
+	jobs      domain.JobRepository
+	dedup     domain.CrawlDeduplicator
 }
 
-func NewServer(publisher domain.ChannelCrawlerScheduler) *server {
-	return &server{publisher: publisher}
+// NewServer creates the API server, urls scheduled within the dedup window are skipped unless dedup is nil
+func NewServer(
+	publisher domain.ChannelCrawlerScheduler,
+	stores domain.StoreResolver,
+	channels domain.ChannelQueryRepository,
+	jobs domain.JobRepository,
+	dedup domain.CrawlDeduplicator,
+) *server {
+	return &server{publisher: publisher, stores: stores, channels: channels, jobs: jobs, dedup: dedup}
 }
 
-func (s *server) Crawl(ctx context.Context, request *grpcwebcrawler.CrawlerRequest) (*grpcwebcrawler.Empty, error) {
+func (s *server) Crawl(ctx context.Context, request *grpcwebcrawler.CrawlerRequest) (
+	*grpcwebcrawler.CrawlResult,
+	error,
+) {
 	url, err := domain.NewURL(request.Url)
 	if err != nil {
 		return nil, status.Error(codes.InvalidArgument, "request validation failed")
 	}
 
-	err = s.publisher.Schedule(ctx, *url)
+	locale, err := domain.NewLocale(request.Locale)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "request validation failed")
+	}
+
+	priority, err := newCrawlPriority(request.Priority)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "request validation failed")
+	}
+
+	_, err = s.stores.ResolveStore(*url)
 	if err != nil {
-		return nil, status.Error(codes.Internal, "failed to publish message")
+		return nil, status.Error(codes.InvalidArgument, "url does not belong to any supported store")
+	}
+
+	if jobID, found := s.findScheduled(ctx, *locale, priority, []domain.Url{*url})[*url]; found {
+		metrics.DuplicateRequests.Inc()
+		return &grpcwebcrawler.CrawlResult{
+			Url:    request.Url,
+			Status: grpcwebcrawler.CrawlStatus_CRAWL_STATUS_DUPLICATE,
+			JobId:  string(jobID),
+		}, nil
 	}
 
-	return &grpcwebcrawler.Empty{}, nil
+	jobID := domain.GenerateJobID()
+	crawlRequest := domain.NewCrawlRequest(jobID, *url, *locale, priority)
+	err = s.publisher.Schedule(ctx, *crawlRequest)
+	// Url could be scheduled by the concurrent request after it was looked up
+	if duplicateJobID, duplicate := domain.DuplicateJobIDOf(err); duplicate {
+		metrics.DuplicateRequests.Inc()
+		return &grpcwebcrawler.CrawlResult{
+			Url:    request.Url,
+			Status: grpcwebcrawler.CrawlStatus_CRAWL_STATUS_DUPLICATE,
+			JobId:  string(duplicateJobID),
+		}, nil
+	} else if err != nil {
+		return nil, crawlErrorStatus(err, "failed to publish message")
+	}
+	s.remember(ctx, []domain.CrawlRequest{*crawlRequest})
+
+	return &grpcwebcrawler.CrawlResult{
+		Url:    request.Url,
+		Status: grpcwebcrawler.CrawlStatus_CRAWL_STATUS_ACCEPTED,
+		JobId:  string(jobID),
+	}, nil
 }
 
+// CrawlBatch attempts to schedule every url in the batch and reports the result of each of them, urls repeated
+// in the batch in the same locale or scheduled within the dedup window are reported as duplicates
 func (s *server) CrawlBatch(ctx context.Context, request *grpcwebcrawler.BatchCrawlerRequest) (
-	*grpcwebcrawler.Empty,
+	*grpcwebcrawler.BatchCrawlerResponse,
+	error,
+) {
+	urls := make([]submittedUrl, 0, len(request.Urls))
+	for _, urlInBatch := range request.Urls {
+		urls = append(
+			urls,
+			submittedUrl{url: urlInBatch.Url, locale: urlInBatch.Locale, priority: urlInBatch.Priority},
+		)
+	}
+
+	return &grpcwebcrawler.BatchCrawlerResponse{Results: s.scheduleBatch(ctx, urls)}, nil
+}
+
+// SubmitUrls schedules the streamed urls in batches and acknowledges every url with its offset once it's handled,
+// acknowledgements are sent in the order the urls were received. Urls are not received while the batch is being
+// scheduled, so a slow broker slows the client down instead of piling the urls up in memory. Stream is closed
+// once the client closed its side and all the received urls are acknowledged.
+func (s *server) SubmitUrls(stream grpcwebcrawler.WebCrawlerService_SubmitUrlsServer) error {
+	ctx := stream.Context()
+	requests := make(chan *grpcwebcrawler.SubmitUrlsRequest, submitBatchSize)
+	recvErr := make(chan error, 1)
+
+	go func() {
+		defer close(requests)
+		for {
+			request, err := stream.Recv()
+			if err != nil {
+				if !errors.Is(err, io.EOF) {
+					recvErr <- err
+				}
+				return
+			}
+
+			select {
+			case requests <- request:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	for {
+		batch, open := receiveSubmitBatch(requests)
+		if len(batch) > 0 {
+			urls := make([]submittedUrl, 0, len(batch))
+			for _, request := range batch {
+				urls = append(
+					urls,
+					submittedUrl{url: request.Url, locale: request.Locale, priority: request.Priority},
+				)
+			}
+
+			for i, result := range s.scheduleBatch(ctx, urls) {
+				err := stream.Send(&grpcwebcrawler.SubmitUrlsResponse{Offset: batch[i].Offset, Result: result})
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		if !open {
+			select {
+			case err := <-recvErr:
+				return err
+			default:
+				return nil
+			}
+		}
+	}
+}
+
+// receiveSubmitBatch waits for the first url of the batch and collects the urls received until the batch is full
+// or the linger elapsed, false is returned once there are no more urls to receive
+func receiveSubmitBatch(
+	requests <-chan *grpcwebcrawler.SubmitUrlsRequest,
+) ([]*grpcwebcrawler.SubmitUrlsRequest, bool) {
+	request, open := <-requests
+	if !open {
+		return nil, false
+	}
+
+	batch := []*grpcwebcrawler.SubmitUrlsRequest{request}
+	linger := time.NewTimer(submitBatchLinger)
+	defer linger.Stop()
+
+	for len(batch) < submitBatchSize {
+		select {
+		case request, open = <-requests:
+			if !open {
+				return batch, false
+			}
+			batch = append(batch, request)
+		case <-linger.C:
+			return batch, true
+		}
+	}
+
+	return batch, true
+}
+
+// submittedUrl is the url scheduled by the client with the locale it's crawled in and its priority,
+// all of them are validated by scheduleBatch
+type submittedUrl struct {
+	url      string
+	locale   string
+	priority grpcwebcrawler.CrawlPriority
+}
+
+// scheduleBatch attempts to schedule every url and returns the results in the same order as the urls
+func (s *server) scheduleBatch(ctx context.Context, urls []submittedUrl) []*grpcwebcrawler.CrawlResult {
+	results := make([]*grpcwebcrawler.CrawlResult, len(urls))
+	requests := make([]domain.CrawlRequest, 0, len(urls))
+	requestResults := make([]*grpcwebcrawler.CrawlResult, 0, len(urls))
+	scheduledResults := make(map[domain.CrawlTarget]*grpcwebcrawler.CrawlResult, len(urls))
+	// Results of the urls repeated in the batch by the results of their first occurrence, which job could turn out
+	// to be the duplicate as well
+	repeatedResults := make(map[*grpcwebcrawler.CrawlResult]*grpcwebcrawler.CrawlResult)
+
+	for i, urlInBatch := range urls {
+		result := &grpcwebcrawler.CrawlResult{Url: urlInBatch.url}
+		results[i] = result
+
+		url, err := domain.NewURL(urlInBatch.url)
+		if err != nil {
+			result.Status = grpcwebcrawler.CrawlStatus_CRAWL_STATUS_INVALID
+			result.Error = err.Error()
+			continue
+		}
+
+		locale, err := domain.NewLocale(urlInBatch.locale)
+		if err != nil {
+			result.Status = grpcwebcrawler.CrawlStatus_CRAWL_STATUS_INVALID
+			result.Error = err.Error()
+			continue
+		}
+
+		priority, err := newCrawlPriority(urlInBatch.priority)
+		if err != nil {
+			result.Status = grpcwebcrawler.CrawlStatus_CRAWL_STATUS_INVALID
+			result.Error = err.Error()
+			continue
+		}
+
+		_, err = s.stores.ResolveStore(*url)
+		if err != nil {
+			result.Status = grpcwebcrawler.CrawlStatus_CRAWL_STATUS_INVALID
+			result.Error = err.Error()
+			continue
+		}
+
+		target := domain.CrawlTarget{Url: *url, Locale: *locale}
+		if scheduledResult, found := scheduledResults[target]; found {
+			result.Status = grpcwebcrawler.CrawlStatus_CRAWL_STATUS_DUPLICATE
+			repeatedResults[result] = scheduledResult
+			continue
+		}
+
+		jobID := domain.GenerateJobID()
+		scheduledResults[target] = result
+		result.JobId = string(jobID)
+
+		requests = append(requests, *domain.NewCrawlRequest(jobID, *url, *locale, priority))
+		requestResults = append(requestResults, result)
+	}
+
+	requests, requestResults = s.skipScheduled(ctx, requests, requestResults)
+
+	errs := s.publisher.ScheduleBatch(ctx, requests)
+	scheduled := make([]domain.CrawlRequest, 0, len(requests))
+	for i, err := range errs {
+		if duplicateJobID, duplicate := domain.DuplicateJobIDOf(err); duplicate {
+			metrics.DuplicateRequests.Inc()
+			requestResults[i].Status = grpcwebcrawler.CrawlStatus_CRAWL_STATUS_DUPLICATE
+			requestResults[i].JobId = string(duplicateJobID)
+			continue
+		}
+
+		if err != nil {
+			requestResults[i].Status = grpcwebcrawler.CrawlStatus_CRAWL_STATUS_PUBLISH_FAILED
+			requestResults[i].Error = "failed to publish message"
+			continue
+		}
+
+		requestResults[i].Status = grpcwebcrawler.CrawlStatus_CRAWL_STATUS_ACCEPTED
+		scheduled = append(scheduled, requests[i])
+	}
+	s.remember(ctx, scheduled)
+
+	for result, scheduledResult := range repeatedResults {
+		result.JobId = scheduledResult.JobId
+	}
+
+	return results
+}
+
+// skipScheduled reports the requests of the urls scheduled in the same locale within the dedup window
+// as duplicates and returns the remaining ones with their results. Urls are looked up by their locale
+// and priority, as the url queued with lower priority is scheduled again.
+func (s *server) skipScheduled(
+	ctx context.Context,
+	requests []domain.CrawlRequest,
+	results []*grpcwebcrawler.CrawlResult,
+) ([]domain.CrawlRequest, []*grpcwebcrawler.CrawlResult) {
+	type lookup struct {
+		locale   domain.Locale
+		priority domain.Priority
+	}
+
+	urlsByLookup := make(map[lookup][]domain.Url)
+	for _, request := range requests {
+		key := lookup{locale: request.Locale, priority: request.Priority}
+		urlsByLookup[key] = append(urlsByLookup[key], request.Url)
+	}
+
+	scheduledJobs := make(map[domain.CrawlTarget]domain.JobID)
+	for key, urls := range urlsByLookup {
+		for url, jobID := range s.findScheduled(ctx, key.locale, key.priority, urls) {
+			scheduledJobs[domain.CrawlTarget{Url: url, Locale: key.locale}] = jobID
+		}
+	}
+	if len(scheduledJobs) == 0 {
+		return requests, results
+	}
+
+	remainingRequests := make([]domain.CrawlRequest, 0, len(requests))
+	remainingResults := make([]*grpcwebcrawler.CrawlResult, 0, len(results))
+	for i, request := range requests {
+		if jobID, found := scheduledJobs[domain.CrawlTarget{Url: request.Url, Locale: request.Locale}]; found {
+			metrics.DuplicateRequests.Inc()
+			results[i].Status = grpcwebcrawler.CrawlStatus_CRAWL_STATUS_DUPLICATE
+			results[i].JobId = string(jobID)
+			continue
+		}
+
+		remainingRequests = append(remainingRequests, request)
+		remainingResults = append(remainingResults, results[i])
+	}
+
+	return remainingRequests, remainingResults
+}
+
+// findScheduled returns jobs of the urls scheduled in the locale within the dedup window which are not queued
+// with lower priority. Deduplication is best effort, the urls are scheduled again when the deduplicator fails.
+func (s *server) findScheduled(
+	ctx context.Context,
+	locale domain.Locale,
+	priority domain.Priority,
+	urls []domain.Url,
+) map[domain.Url]domain.JobID {
+	if s.dedup == nil || len(urls) == 0 {
+		return nil
+	}
+
+	scheduledJobs, err := s.dedup.FindScheduled(ctx, locale, priority, urls)
+	if err != nil {
+		log.Printf("Could not find recently scheduled urls, error: %v\n", err)
+		return nil
+	}
+
+	return scheduledJobs
+}
+
+func (s *server) remember(ctx context.Context, requests []domain.CrawlRequest) {
+	if s.dedup == nil || len(requests) == 0 {
+		return
+	}
+
+	err := s.dedup.Remember(ctx, requests)
+	if err != nil {
+		log.Printf("Could not remember %d scheduled urls, error: %v\n", len(requests), err)
+	}
+}
+
+func (s *server) GetChannel(ctx context.Context, request *grpcwebcrawler.GetChannelRequest) (
+	*grpcwebcrawler.Channel,
+	error,
+) {
+	var channel *domain.ChannelView
+	var err error
+
+	switch identifier := request.Identifier.(type) {
+	case *grpcwebcrawler.GetChannelRequest_Url:
+		url, urlErr := domain.NewURL(identifier.Url)
+		if urlErr != nil {
+			return nil, status.Error(codes.InvalidArgument, "request validation failed")
+		}
+		country, countryErr := domain.NewCountry(request.Country)
+		if countryErr != nil {
+			return nil, status.Error(codes.InvalidArgument, "request validation failed")
+		}
+		channel, err = s.channels.FindByUrl(ctx, *url, *country)
+	case *grpcwebcrawler.GetChannelRequest_ChannelId:
+		channel, err = s.channels.FindByID(ctx, identifier.ChannelId)
+	default:
+		return nil, status.Error(codes.InvalidArgument, "url or channel id is required")
+	}
+
+	if errors.Is(err, domain.ErrChannelNotFound) {
+		return nil, status.Error(codes.NotFound, "channel not found")
+	} else if err != nil {
+		return nil, crawlErrorStatus(err, "failed to get channel")
+	}
+
+	return newGRPCChannel(*channel), nil
+}
+
+func (s *server) ListChannels(ctx context.Context, request *grpcwebcrawler.ListChannelsRequest) (
+	*grpcwebcrawler.ListChannelsResponse,
 	error,
 ) {
-	for _, urlInBatch := range request.Urls { // TODO: Some more sophisticated solution?
-		_, err := s.Crawl(ctx, urlInBatch)
+	filter, err := newChannelFilter(request)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "request validation failed")
+	}
 
+	page, err := s.channels.List(ctx, *filter)
+	if errors.Is(err, domain.ErrInvalidCursor) {
+		return nil, status.Error(codes.InvalidArgument, "invalid cursor")
+	} else if err != nil {
+		return nil, crawlErrorStatus(err, "failed to list channels")
+	}
+
+	response := &grpcwebcrawler.ListChannelsResponse{
+		Channels:   make([]*grpcwebcrawler.Channel, 0, len(page.Channels)),
+		NextCursor: page.NextCursor,
+	}
+	for _, channel := range page.Channels {
+		response.Channels = append(response.Channels, newGRPCChannel(channel))
+	}
+
+	return response, nil
+}
+
+func (s *server) GetJob(ctx context.Context, request *grpcwebcrawler.GetJobRequest) (*grpcwebcrawler.Job, error) {
+	jobID, err := domain.NewJobID(request.JobId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "request validation failed")
+	}
+
+	job, err := s.jobs.FindByID(ctx, *jobID)
+	if errors.Is(err, domain.ErrJobNotFound) {
+		return nil, status.Error(codes.NotFound, "job not found")
+	} else if err != nil {
+		return nil, crawlErrorStatus(err, "failed to get job")
+	}
+
+	return newGRPCJob(*job), nil
+}
+
+// WatchJobs polls the watched jobs and sends every change of them until all the jobs are finished
+func (s *server) WatchJobs(
+	request *grpcwebcrawler.WatchJobsRequest,
+	stream grpcwebcrawler.WebCrawlerService_WatchJobsServer,
+) error {
+	if len(request.JobIds) == 0 || len(request.JobIds) > maxWatchedJobs {
+		return status.Errorf(codes.InvalidArgument, "between 1 and %d job ids are required", maxWatchedJobs)
+	}
+
+	jobIDs := make([]domain.JobID, 0, len(request.JobIds))
+	requestedJobs := make(map[domain.JobID]struct{}, len(request.JobIds))
+	for _, id := range request.JobIds {
+		jobID, err := domain.NewJobID(id)
 		if err != nil {
-			return nil, err // TODO: It should probably report which urls failed to publish
+			return status.Error(codes.InvalidArgument, "request validation failed")
+		}
+
+		if _, found := requestedJobs[*jobID]; !found {
+			requestedJobs[*jobID] = struct{}{}
+			jobIDs = append(jobIDs, *jobID)
 		}
 	}
 
-	return &grpcwebcrawler.Empty{}, nil
+	ctx := stream.Context()
+	ticker := time.NewTicker(jobWatchInterval)
+	defer ticker.Stop()
+
+	sent := make(map[domain.JobID]domain.Job, len(jobIDs))
+	for {
+		jobs, err := s.jobs.FindByIDs(ctx, jobIDs)
+		if err != nil {
+			return crawlErrorStatus(err, "failed to get jobs")
+		}
+
+		if len(sent) == 0 && len(jobs) != len(jobIDs) {
+			return status.Error(codes.NotFound, "job not found")
+		}
+
+		finished := 0
+		for _, job := range jobs {
+			if job.Status.IsFinal() {
+				finished++
+			}
+
+			previous, found := sent[job.ID]
+			if found && previous.Status == job.Status && previous.UpdatedAt.Equal(job.UpdatedAt) {
+				continue
+			}
+
+			err = stream.Send(newGRPCJob(job))
+			if err != nil {
+				return err
+			}
+			sent[job.ID] = job
+		}
+
+		if finished == len(jobIDs) {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func newChannelFilter(request *grpcwebcrawler.ListChannelsRequest) (*domain.ChannelFilter, error) {
+	filter := &domain.ChannelFilter{
+		NamePrefix: request.NamePrefix,
+		Cursor:     request.Cursor,
+		Limit:      defaultPageSize,
+	}
+
+	if request.MinRating != nil {
+		rating, err := domain.NewRating(request.GetMinRating())
+		if err != nil {
+			return nil, err
+		}
+		filter.MinRating = rating
+	}
+
+	if request.MinNumberOfRatings != nil {
+		ratingsAmount, err := domain.NewRatingsAmount(request.GetMinNumberOfRatings())
+		if err != nil {
+			return nil, err
+		}
+		filter.MinNumberOfRatings = ratingsAmount
+	}
+
+	if request.UpdatedSince != nil {
+		err := request.UpdatedSince.CheckValid()
+		if err != nil {
+			return nil, err
+		}
+		updatedSince := request.UpdatedSince.AsTime()
+		filter.UpdatedSince = &updatedSince
+	}
+
+	if request.Country != nil {
+		country, err := domain.NewCountry(request.GetCountry())
+		if err != nil {
+			return nil, err
+		}
+		filter.Country = country
+	}
+
+	switch request.Status {
+	case grpcwebcrawler.ChannelStatus_CHANNEL_STATUS_ACTIVE:
+		filter.Status = domain.ChannelStatusActive
+	case grpcwebcrawler.ChannelStatus_CHANNEL_STATUS_DELISTED:
+		filter.Status = domain.ChannelStatusDelisted
+	}
+
+	if request.PageSize > 0 {
+		filter.Limit = int(request.PageSize)
+	}
+	if filter.Limit > maxPageSize {
+		filter.Limit = maxPageSize
+	}
+
+	return filter, nil
+}
+
+// crawlErrorStatus maps kind of the failure to the status code, so the client can tell whether retrying makes sense
+func crawlErrorStatus(err error, message string) error {
+	var code codes.Code
+	switch domain.CrawlErrorKindOf(err) {
+	case domain.CrawlErrorTimeout:
+		code = codes.DeadlineExceeded
+	case domain.CrawlErrorNotFound:
+		code = codes.NotFound
+	case domain.CrawlErrorBlocked, domain.CrawlErrorUnavailable, domain.CrawlErrorStorage:
+		code = codes.Unavailable
+	case domain.CrawlErrorMarkupChanged:
+		code = codes.FailedPrecondition
+	case domain.CrawlErrorInvalidData:
+		code = codes.InvalidArgument
+	default:
+		code = codes.Internal
+	}
+
+	return status.Error(code, message)
+}
+
+func newGRPCChannel(view domain.ChannelView) *grpcwebcrawler.Channel {
+	channel := &grpcwebcrawler.Channel{
+		Id:              view.ID,
+		ApplicationName: string(view.Channel.ApplicationName),
+		Url:             string(view.Channel.Url),
+		Rating:          float32(view.Channel.Rating),
+		NumberOfRatings: uint32(view.Channel.NumberOfRatings),
+		UpdatedAt:       timestamppb.New(view.UpdatedAt),
+		Store:           string(view.Channel.Store),
+		ProfileVersion:  view.Channel.ProfileVersion,
+		Proxy:           view.Channel.Proxy,
+		Locale:          string(view.Channel.Locale),
+		Country:         string(view.Channel.Locale.Country()),
+		Status:          newGRPCChannelStatus(view.Status),
+		Developer:       string(view.Channel.Developer),
+		Category:        string(view.Channel.Category),
+		Price:           string(view.Channel.Price),
+		Description:     string(view.Channel.Description),
+		ContentRating:   string(view.Channel.ContentRating),
+		StoreChannelId:  string(view.Channel.StoreChannelID),
+	}
+	if view.DelistedAt != nil {
+		channel.DelistedAt = timestamppb.New(*view.DelistedAt)
+	}
+	if view.Channel.StarDistribution != nil {
+		for _, amount := range view.Channel.StarDistribution {
+			channel.StarDistribution = append(channel.StarDistribution, uint32(amount))
+		}
+	}
+	for _, nameChange := range view.NameChanges {
+		channel.NameChanges = append(
+			channel.NameChanges, &grpcwebcrawler.ChannelNameChange{
+				From:      string(nameChange.From),
+				To:        string(nameChange.To),
+				ChangedAt: timestamppb.New(nameChange.ChangedAt),
+			},
+		)
+	}
+
+	return channel
+}
+
+func newGRPCChannelStatus(channelStatus domain.ChannelStatus) grpcwebcrawler.ChannelStatus {
+	switch channelStatus {
+	case domain.ChannelStatusActive:
+		return grpcwebcrawler.ChannelStatus_CHANNEL_STATUS_ACTIVE
+	case domain.ChannelStatusDelisted:
+		return grpcwebcrawler.ChannelStatus_CHANNEL_STATUS_DELISTED
+	default:
+		return grpcwebcrawler.ChannelStatus_CHANNEL_STATUS_UNSPECIFIED
+	}
+}
+
+func newGRPCJob(job domain.Job) *grpcwebcrawler.Job {
+	return &grpcwebcrawler.Job{
+		Id:         string(job.ID),
+		Url:        string(job.Url),
+		Locale:     string(job.Locale),
+		Priority:   newGRPCCrawlPriority(job.Priority),
+		Status:     newGRPCJobStatus(job.Status),
+		Error:      job.Error,
+		ArtifactId: string(job.ArtifactID),
+		CreatedAt:  timestamppb.New(job.CreatedAt),
+		UpdatedAt:  timestamppb.New(job.UpdatedAt),
+	}
+}
+
+// newCrawlPriority maps priority of the request to the lane, unspecified priority is the normal one
+func newCrawlPriority(priority grpcwebcrawler.CrawlPriority) (domain.Priority, error) {
+	switch priority {
+	case grpcwebcrawler.CrawlPriority_CRAWL_PRIORITY_UNSPECIFIED, grpcwebcrawler.CrawlPriority_CRAWL_PRIORITY_NORMAL:
+		return domain.PriorityNormal, nil
+	case grpcwebcrawler.CrawlPriority_CRAWL_PRIORITY_HIGH:
+		return domain.PriorityHigh, nil
+	case grpcwebcrawler.CrawlPriority_CRAWL_PRIORITY_LOW:
+		return domain.PriorityLow, nil
+	default:
+		return "", fmt.Errorf("unknown priority %d", priority)
+	}
+}
+
+func newGRPCCrawlPriority(priority domain.Priority) grpcwebcrawler.CrawlPriority {
+	switch priority {
+	case domain.PriorityHigh:
+		return grpcwebcrawler.CrawlPriority_CRAWL_PRIORITY_HIGH
+	case domain.PriorityNormal:
+		return grpcwebcrawler.CrawlPriority_CRAWL_PRIORITY_NORMAL
+	case domain.PriorityLow:
+		return grpcwebcrawler.CrawlPriority_CRAWL_PRIORITY_LOW
+	default:
+		return grpcwebcrawler.CrawlPriority_CRAWL_PRIORITY_UNSPECIFIED
+	}
+}
+
+func newGRPCJobStatus(jobStatus domain.JobStatus) grpcwebcrawler.JobStatus {
+	switch jobStatus {
+	case domain.JobStatusQueued:
+		return grpcwebcrawler.JobStatus_JOB_STATUS_QUEUED
+	case domain.JobStatusRunning:
+		return grpcwebcrawler.JobStatus_JOB_STATUS_RUNNING
+	case domain.JobStatusFailed:
+		return grpcwebcrawler.JobStatus_JOB_STATUS_FAILED
+	case domain.JobStatusDone:
+		return grpcwebcrawler.JobStatus_JOB_STATUS_DONE
+	default:
+		return grpcwebcrawler.JobStatus_JOB_STATUS_UNSPECIFIED
+	}
 }
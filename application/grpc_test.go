@@ -0,0 +1,392 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go-web-crawler-service/domain"
+	grpcwebcrawler "go-web-crawler-service/protobuf/webcrawler"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"io"
+	"testing"
+	"time"
+)
+
+func newTestStoreResolver(t *testing.T) domain.StoreResolver {
+	route, err := domain.NewStoreRoute("google.com", "google")
+	require.NoError(t, err)
+
+	return domain.NewHostStoreResolver([]domain.StoreRoute{*route})
+}
+
+type crawlerSchedulerMock struct {
+	mock.Mock
+}
+
+func (m *crawlerSchedulerMock) Schedule(ctx context.Context, request domain.CrawlRequest) error {
+	return m.Called(ctx, request).Error(0)
+}
+
+func (m *crawlerSchedulerMock) ScheduleBatch(ctx context.Context, requests []domain.CrawlRequest) []error {
+	args := m.Called(ctx, requests)
+	if errsFunc, ok := args.Get(0).(func(context.Context, []domain.CrawlRequest) []error); ok {
+		return errsFunc(ctx, requests)
+	}
+
+	return args.Get(0).([]error)
+}
+
+func TestServer_CrawlBatch_ReportsResultOfEveryUrl(t *testing.T) {
+	ctx := context.Background()
+
+	schedulerMock := &crawlerSchedulerMock{}
+	schedulerMock.On(
+		"ScheduleBatch", ctx, mock.MatchedBy(
+			func(requests []domain.CrawlRequest) bool {
+				return len(requests) == 2 &&
+					requests[0].Url == "https://google.com/first" &&
+					requests[0].Priority == domain.PriorityNormal &&
+					requests[1].Url == "https://google.com/second" &&
+					requests[1].Priority == domain.PriorityHigh
+			},
+		),
+	).Return([]error{nil, errors.New("publish error")})
+
+	response, err := NewServer(schedulerMock, newTestStoreResolver(t), nil, nil, nil).CrawlBatch(
+		ctx, &grpcwebcrawler.BatchCrawlerRequest{
+			Urls: []*grpcwebcrawler.CrawlerRequest{
+				{Url: "https://google.com/first"},
+				{Url: "not-a-url"},
+				{Url: "https://google.com/second", Priority: grpcwebcrawler.CrawlPriority_CRAWL_PRIORITY_HIGH},
+				{Url: "https://google.com/first"},
+				{Url: "https://example.com/unsupported"},
+				{Url: "https://google.com/first", Locale: "english"},
+				{Url: "https://google.com/first", Priority: grpcwebcrawler.CrawlPriority(10)},
+			},
+		},
+	)
+
+	require.NoError(t, err)
+	require.Len(t, response.Results, 7)
+
+	assert.Equal(t, grpcwebcrawler.CrawlStatus_CRAWL_STATUS_ACCEPTED, response.Results[0].Status)
+	assert.NotEmpty(t, response.Results[0].JobId)
+	assert.Equal(t, grpcwebcrawler.CrawlStatus_CRAWL_STATUS_INVALID, response.Results[1].Status)
+	assert.Empty(t, response.Results[1].JobId)
+	assert.Equal(t, grpcwebcrawler.CrawlStatus_CRAWL_STATUS_PUBLISH_FAILED, response.Results[2].Status)
+	assert.Equal(t, grpcwebcrawler.CrawlStatus_CRAWL_STATUS_DUPLICATE, response.Results[3].Status)
+	assert.Equal(t, response.Results[0].JobId, response.Results[3].JobId)
+	assert.Equal(t, grpcwebcrawler.CrawlStatus_CRAWL_STATUS_INVALID, response.Results[4].Status)
+	assert.Equal(t, grpcwebcrawler.CrawlStatus_CRAWL_STATUS_INVALID, response.Results[5].Status)
+	assert.Equal(t, grpcwebcrawler.CrawlStatus_CRAWL_STATUS_INVALID, response.Results[6].Status)
+	schedulerMock.AssertExpectations(t)
+}
+
+type crawlDeduplicatorMock struct {
+	mock.Mock
+}
+
+func (m *crawlDeduplicatorMock) FindScheduled(
+	ctx context.Context,
+	locale domain.Locale,
+	priority domain.Priority,
+	urls []domain.Url,
+) (map[domain.Url]domain.JobID, error) {
+	args := m.Called(ctx, locale, priority, urls)
+	scheduled, _ := args.Get(0).(map[domain.Url]domain.JobID)
+
+	return scheduled, args.Error(1)
+}
+
+func (m *crawlDeduplicatorMock) Remember(ctx context.Context, requests []domain.CrawlRequest) error {
+	return m.Called(ctx, requests).Error(0)
+}
+
+func TestServer_CrawlBatch_SkipsRecentlyScheduledUrls(t *testing.T) {
+	ctx := context.Background()
+
+	dedupMock := &crawlDeduplicatorMock{}
+	dedupMock.On(
+		"FindScheduled",
+		ctx,
+		domain.Locale(""),
+		domain.PriorityNormal,
+		[]domain.Url{"https://google.com/first", "https://google.com/second"},
+	).Return(map[domain.Url]domain.JobID{"https://google.com/first": "scheduled-job"}, nil)
+	dedupMock.On(
+		"FindScheduled", ctx, domain.Locale("en-GB"), domain.PriorityNormal, []domain.Url{"https://google.com/first"},
+	).Return(map[domain.Url]domain.JobID{}, nil)
+	dedupMock.On(
+		"Remember", ctx, mock.MatchedBy(
+			func(requests []domain.CrawlRequest) bool {
+				return len(requests) == 2 && requests[0].Url == "https://google.com/second"
+			},
+		),
+	).Return(nil)
+
+	schedulerMock := &crawlerSchedulerMock{}
+	schedulerMock.On(
+		"ScheduleBatch", ctx, mock.MatchedBy(
+			func(requests []domain.CrawlRequest) bool {
+				return len(requests) == 2 && requests[0].Url == "https://google.com/second" &&
+					requests[1].Url == "https://google.com/first" && requests[1].Locale == "en-GB"
+			},
+		),
+	).Return([]error{nil, nil})
+
+	response, err := NewServer(schedulerMock, newTestStoreResolver(t), nil, nil, dedupMock).CrawlBatch(
+		ctx, &grpcwebcrawler.BatchCrawlerRequest{
+			Urls: []*grpcwebcrawler.CrawlerRequest{
+				{Url: "http://google.com/first/?utm_source=newsletter"},
+				{Url: "https://google.com/second"},
+				{Url: "https://google.com/first", Locale: "en_gb"},
+			},
+		},
+	)
+
+	require.NoError(t, err)
+	require.Len(t, response.Results, 3)
+	assert.Equal(t, grpcwebcrawler.CrawlStatus_CRAWL_STATUS_DUPLICATE, response.Results[0].Status)
+	assert.Equal(t, "scheduled-job", response.Results[0].JobId)
+	assert.Equal(t, grpcwebcrawler.CrawlStatus_CRAWL_STATUS_ACCEPTED, response.Results[1].Status)
+	assert.Equal(t, grpcwebcrawler.CrawlStatus_CRAWL_STATUS_ACCEPTED, response.Results[2].Status)
+	schedulerMock.AssertExpectations(t)
+	dedupMock.AssertExpectations(t)
+}
+
+func TestServer_Crawl_DeduplicatorFailure_SchedulesUrl(t *testing.T) {
+	ctx := context.Background()
+
+	dedupMock := &crawlDeduplicatorMock{}
+	dedupMock.On(
+		"FindScheduled", ctx, domain.Locale(""), domain.PriorityNormal, []domain.Url{"https://google.com/first"},
+	).Return(nil, errors.New("storage error"))
+	dedupMock.On("Remember", ctx, mock.Anything).Return(nil)
+
+	schedulerMock := &crawlerSchedulerMock{}
+	schedulerMock.On("Schedule", ctx, mock.Anything).Return(nil)
+
+	result, err := NewServer(schedulerMock, newTestStoreResolver(t), nil, nil, dedupMock).Crawl(
+		ctx,
+		&grpcwebcrawler.CrawlerRequest{Url: "https://google.com/first"},
+	)
+
+	require.NoError(t, err)
+	assert.Equal(t, grpcwebcrawler.CrawlStatus_CRAWL_STATUS_ACCEPTED, result.Status)
+	schedulerMock.AssertExpectations(t)
+}
+
+func TestServer_Crawl_HigherPriorityThanQueuedJob_SchedulesUrl(t *testing.T) {
+	ctx := context.Background()
+	url := domain.Url("https://google.com/first")
+
+	// Job of the url is queued with the normal priority, it's the duplicate of the normal and low requests only
+	dedupMock := &crawlDeduplicatorMock{}
+	dedupMock.On("FindScheduled", ctx, domain.Locale(""), domain.PriorityLow, []domain.Url{url}).
+		Return(map[domain.Url]domain.JobID{url: "queued-job"}, nil)
+	dedupMock.On("FindScheduled", ctx, domain.Locale(""), domain.PriorityHigh, []domain.Url{url}).
+		Return(map[domain.Url]domain.JobID{}, nil)
+	dedupMock.On("Remember", ctx, mock.Anything).Return(nil)
+
+	schedulerMock := &crawlerSchedulerMock{}
+	schedulerMock.On(
+		"Schedule", ctx, mock.MatchedBy(
+			func(request domain.CrawlRequest) bool {
+				return request.Url == url && request.Priority == domain.PriorityHigh
+			},
+		),
+	).Return(nil).Once()
+
+	server := NewServer(schedulerMock, newTestStoreResolver(t), nil, nil, dedupMock)
+
+	result, err := server.Crawl(
+		ctx,
+		&grpcwebcrawler.CrawlerRequest{Url: string(url), Priority: grpcwebcrawler.CrawlPriority_CRAWL_PRIORITY_LOW},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, grpcwebcrawler.CrawlStatus_CRAWL_STATUS_DUPLICATE, result.Status)
+	assert.Equal(t, "queued-job", result.JobId)
+
+	result, err = server.Crawl(
+		ctx,
+		&grpcwebcrawler.CrawlerRequest{Url: string(url), Priority: grpcwebcrawler.CrawlPriority_CRAWL_PRIORITY_HIGH},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, grpcwebcrawler.CrawlStatus_CRAWL_STATUS_ACCEPTED, result.Status)
+	assert.NotEqual(t, "queued-job", result.JobId)
+	schedulerMock.AssertExpectations(t)
+	dedupMock.AssertExpectations(t)
+}
+
+type submitUrlsStreamFake struct {
+	grpc.ServerStream
+	ctx       context.Context
+	requests  chan *grpcwebcrawler.SubmitUrlsRequest
+	responses []*grpcwebcrawler.SubmitUrlsResponse
+}
+
+func (f *submitUrlsStreamFake) Context() context.Context {
+	return f.ctx
+}
+
+func (f *submitUrlsStreamFake) Recv() (*grpcwebcrawler.SubmitUrlsRequest, error) {
+	request, open := <-f.requests
+	if !open {
+		return nil, io.EOF
+	}
+
+	return request, nil
+}
+
+func (f *submitUrlsStreamFake) Send(response *grpcwebcrawler.SubmitUrlsResponse) error {
+	f.responses = append(f.responses, response)
+	return nil
+}
+
+func TestServer_SubmitUrls_AcknowledgesEveryUrlInOrder(t *testing.T) {
+	ctx := context.Background()
+
+	schedulerMock := &crawlerSchedulerMock{}
+	schedulerMock.On("ScheduleBatch", ctx, mock.Anything).Return(
+		func(_ context.Context, requests []domain.CrawlRequest) []error {
+			return make([]error, len(requests))
+		},
+	)
+
+	urlsAmount := submitBatchSize + 10
+	stream := &submitUrlsStreamFake{ctx: ctx, requests: make(chan *grpcwebcrawler.SubmitUrlsRequest, urlsAmount)}
+	for i := 0; i < urlsAmount; i++ {
+		url := fmt.Sprintf("https://google.com/%d", i)
+		if i == 1 {
+			url = "not-a-url"
+		}
+		stream.requests <- &grpcwebcrawler.SubmitUrlsRequest{Offset: uint64(1000 + i), Url: url}
+	}
+	close(stream.requests)
+
+	err := NewServer(schedulerMock, newTestStoreResolver(t), nil, nil, nil).SubmitUrls(stream)
+
+	require.NoError(t, err)
+	require.Len(t, stream.responses, urlsAmount)
+	for i, response := range stream.responses {
+		assert.EqualValues(t, 1000+i, response.Offset)
+	}
+	assert.Equal(t, grpcwebcrawler.CrawlStatus_CRAWL_STATUS_ACCEPTED, stream.responses[0].Result.Status)
+	assert.Equal(t, grpcwebcrawler.CrawlStatus_CRAWL_STATUS_INVALID, stream.responses[1].Result.Status)
+	schedulerMock.AssertNumberOfCalls(t, "ScheduleBatch", 2)
+}
+
+func TestServer_Crawl_ScheduledConcurrently_ReportsDuplicate(t *testing.T) {
+	ctx := context.Background()
+
+	// Job of the url was created by the concurrent request after the url was looked up
+	schedulerMock := &crawlerSchedulerMock{}
+	schedulerMock.On("Schedule", ctx, mock.Anything).Return(domain.NewDuplicateJobError("concurrent-job"))
+
+	result, err := NewServer(schedulerMock, newTestStoreResolver(t), nil, nil, nil).Crawl(
+		ctx,
+		&grpcwebcrawler.CrawlerRequest{Url: "https://google.com/first"},
+	)
+
+	require.NoError(t, err)
+	assert.Equal(t, grpcwebcrawler.CrawlStatus_CRAWL_STATUS_DUPLICATE, result.Status)
+	assert.Equal(t, "concurrent-job", result.JobId)
+}
+
+func TestServer_CrawlBatch_ScheduledConcurrently_ReportsDuplicate(t *testing.T) {
+	ctx := context.Background()
+
+	schedulerMock := &crawlerSchedulerMock{}
+	schedulerMock.On("ScheduleBatch", ctx, mock.Anything).
+		Return([]error{domain.NewDuplicateJobError("concurrent-job"), nil})
+
+	response, err := NewServer(schedulerMock, newTestStoreResolver(t), nil, nil, nil).CrawlBatch(
+		ctx, &grpcwebcrawler.BatchCrawlerRequest{
+			Urls: []*grpcwebcrawler.CrawlerRequest{
+				{Url: "https://google.com/first"},
+				{Url: "https://google.com/second"},
+				{Url: "https://google.com/first"},
+			},
+		},
+	)
+
+	require.NoError(t, err)
+	require.Len(t, response.Results, 3)
+	assert.Equal(t, grpcwebcrawler.CrawlStatus_CRAWL_STATUS_DUPLICATE, response.Results[0].Status)
+	assert.Equal(t, "concurrent-job", response.Results[0].JobId)
+	assert.Equal(t, grpcwebcrawler.CrawlStatus_CRAWL_STATUS_ACCEPTED, response.Results[1].Status)
+	assert.Equal(t, grpcwebcrawler.CrawlStatus_CRAWL_STATUS_DUPLICATE, response.Results[2].Status)
+	assert.Equal(t, "concurrent-job", response.Results[2].JobId, "repeated url refers to the job it duplicates")
+}
+
+func TestServer_Crawl_UnsupportedStore_ReturnsInvalidArgument(t *testing.T) {
+	schedulerMock := &crawlerSchedulerMock{}
+
+	_, err := NewServer(schedulerMock, newTestStoreResolver(t), nil, nil, nil).Crawl(
+		context.Background(),
+		&grpcwebcrawler.CrawlerRequest{Url: "https://example.com/unsupported"},
+	)
+
+	require.Error(t, err)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	schedulerMock.AssertNotCalled(t, "Schedule", mock.Anything, mock.Anything)
+}
+
+func TestCrawlErrorStatus(t *testing.T) {
+	testCases := []struct {
+		err          error
+		expectedCode codes.Code
+	}{
+		{err: errors.New("failure"), expectedCode: codes.Internal},
+		{err: context.DeadlineExceeded, expectedCode: codes.DeadlineExceeded},
+		{err: domain.NewCrawlError(domain.CrawlErrorStorage, errors.New("failure")), expectedCode: codes.Unavailable},
+		{err: domain.NewCrawlError(domain.CrawlErrorNotFound, errors.New("failure")), expectedCode: codes.NotFound},
+		{
+			err:          domain.NewCrawlError(domain.CrawlErrorInvalidData, errors.New("failure")),
+			expectedCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, testCase := range testCases {
+		err := crawlErrorStatus(testCase.err, "failed")
+
+		assert.Equal(t, testCase.expectedCode, status.Code(err), testCase.err.Error())
+	}
+}
+
+func TestNewGRPCChannel_Delisted(t *testing.T) {
+	delistedAt := time.Date(2022, 4, 1, 12, 0, 0, 0, time.UTC)
+
+	channel := newGRPCChannel(
+		domain.ChannelView{ID: "id", Status: domain.ChannelStatusDelisted, DelistedAt: &delistedAt},
+	)
+
+	assert.Equal(t, grpcwebcrawler.ChannelStatus_CHANNEL_STATUS_DELISTED, channel.Status)
+	assert.Equal(t, delistedAt, channel.DelistedAt.AsTime())
+	assert.Equal(
+		t,
+		grpcwebcrawler.ChannelStatus_CHANNEL_STATUS_ACTIVE,
+		newGRPCChannel(domain.ChannelView{Status: domain.ChannelStatusActive}).Status,
+	)
+	assert.Nil(t, newGRPCChannel(domain.ChannelView{Status: domain.ChannelStatusActive}).DelistedAt)
+}
+
+func TestNewGRPCChannel_Details(t *testing.T) {
+	channel := domain.NewChannel("Netflix", "https://channelstore.roku.com/details/12", 3.8, 10)
+	channel.Developer = "Netflix, Inc."
+	channel.StarDistribution = &domain.StarDistribution{1, 2, 3, 0, 4}
+	channel.Locale = "en-GB"
+
+	grpcChannel := newGRPCChannel(domain.ChannelView{Channel: *channel})
+
+	assert.Equal(t, "Netflix, Inc.", grpcChannel.Developer)
+	assert.Equal(t, "en-GB", grpcChannel.Locale)
+	assert.Equal(t, "GB", grpcChannel.Country)
+	assert.Equal(t, []uint32{1, 2, 3, 0, 4}, grpcChannel.StarDistribution)
+	assert.Empty(t, newGRPCChannel(domain.ChannelView{}).StarDistribution)
+}
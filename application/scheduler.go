@@ -0,0 +1,87 @@
+package application
+
+import (
+	"context"
+	"go-web-crawler-service/domain"
+	"log"
+	"time"
+)
+
+const (
+	recrawlLeaseName = "recrawl-scheduler"
+)
+
+type channelRecrawler interface {
+	Recrawl(ctx context.Context, now time.Time) (int, error)
+}
+
+type schedulerApp struct {
+	recrawler       channelRecrawler
+	leaseRepository domain.LeaseRepository
+	holder          string
+	interval        time.Duration
+	leaseTTL        time.Duration
+}
+
+// NewSchedulerApplication creates app that periodically recrawls stale channels. Many replicas could run at once,
+// only the one holding the lease schedules the crawls.
+func NewSchedulerApplication(
+	recrawler channelRecrawler,
+	leaseRepository domain.LeaseRepository,
+	holder string,
+	interval time.Duration,
+	leaseTTL time.Duration,
+) *schedulerApp {
+	return &schedulerApp{
+		recrawler:       recrawler,
+		leaseRepository: leaseRepository,
+		holder:          holder,
+		interval:        interval,
+		leaseTTL:        leaseTTL,
+	}
+}
+
+func (a *schedulerApp) Run(ctx context.Context, notifyStart func(), notifyEnd func()) {
+	log.Printf("Starting recrawl scheduler %s, interval: %s\n", a.holder, a.interval)
+
+	notifyStart()
+	go func() {
+		defer notifyEnd()
+
+		ticker := time.NewTicker(a.interval)
+		defer ticker.Stop()
+
+		for {
+			a.tick(ctx)
+
+			select {
+			case <-ctx.Done():
+				log.Println("Recrawl scheduler stopped")
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (a *schedulerApp) tick(ctx context.Context) {
+	acquired, err := a.leaseRepository.Acquire(ctx, recrawlLeaseName, a.holder, a.leaseTTL)
+	if err != nil {
+		log.Printf("Could not acquire recrawl lease, %v\n", err)
+		return
+	}
+
+	if !acquired {
+		return
+	}
+
+	scheduled, err := a.recrawler.Recrawl(ctx, time.Now())
+	if err != nil {
+		log.Printf("Recrawl failed after scheduling %d channels, %v\n", scheduled, err)
+		return
+	}
+
+	if scheduled > 0 {
+		log.Printf("Scheduled recrawl of %d stale channels\n", scheduled)
+	}
+}
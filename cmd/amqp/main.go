@@ -2,21 +2,35 @@ package main
 
 import (
 	"context"
-	"github.com/go-rod/rod"
-	"github.com/go-rod/rod/lib/launcher"
+	"errors"
+	"fmt"
 	"go-web-crawler-service/application"
 	"go-web-crawler-service/cmd"
 	"go-web-crawler-service/config"
 	"go-web-crawler-service/domain"
 	"go-web-crawler-service/infrastructure"
 	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
 	"sync"
 )
 
+const (
+	artifactStoreNone = "none"
+	artifactStoreFile = "file"
+	artifactStoreS3   = "s3"
+
+	crawlerEngineRod      = "rod"
+	crawlerEngineHttp     = "http"
+	crawlerEngineFallback = "fallback"
+
+	rateLimitStoreNone   = "none"
+	rateLimitStoreMemory = "memory"
+	rateLimitStoreMongo  = "mongo"
+)
+
 func main() {
 	cfg, err := config.ParseConfig()
 	if err != nil {
@@ -35,6 +49,11 @@ func main() {
 		wg.Done()
 	}
 
+	err = cmd.InitTracing(ctx, cfg.Tracing, "web-crawler-worker", notifyStart, notifyDone)
+	if err != nil {
+		log.Fatalf("failed to initialize tracing: %v", err)
+	}
+
 	conn, err := cmd.GetAMQPConn(ctx, cfg.AMQP.URL, notifyStart, notifyDone)
 	if err != nil {
 		log.Fatalf("failed to open RabbitMQ connection: %v", err)
@@ -45,22 +64,107 @@ func main() {
 		log.Fatalf("failed to open RabbitMQ channel: %v", err)
 	}
 
-	err = cmd.InitializeAMQPExchange(ch, cfg.AMQP.ExchangeName, cfg.AMQP.QueueName, cfg.AMQP.RoutingKey)
+	err = cmd.InitializeAMQPExchange(
+		ch,
+		cfg.AMQP.ExchangeName,
+		cfg.AMQP.QueueName,
+		cfg.AMQP.RoutingKey,
+		cfg.AMQP.RetryDelays,
+	)
 	if err != nil {
 		log.Fatalf("failed to initialize queues and exchanges: %v", err)
 	}
 
-	db, err := getMongoDB(ctx, cfg.Database.DSN, cfg.Database.DatabaseName, notifyStart, notifyDone)
+	db, err := cmd.GetMongoDB(ctx, cfg.Database.DSN, cfg.Database.DatabaseName, notifyStart, notifyDone)
 	if err != nil {
 		log.Fatalf("failed to create mongo connection: %v", err)
 	}
 
-	browser := getHeadlessBrowser(ctx)
-	webCrawler := infrastructure.NewRodRokuWebCrawler(browser)
+	profile, err := getExtractionProfile(cfg.Crawler.ProfilePath)
+	if err != nil {
+		log.Fatalf("failed to load extraction profile: %v", err)
+	}
+	log.Printf("Using extraction profile version: %s\n", profile.Version)
+
+	// Browsers are launched on the first use, so only when the engine or its fallback renders the page
+	browserPool := infrastructure.NewBrowserPool(
+		cfg.Browser.Bin,
+		cfg.Browser.PoolSize,
+		cfg.Browser.TabsPerBrowser,
+		cfg.Browser.MaxPagesPerBrowser,
+	)
+	notifyStart()
+	go func() {
+		defer notifyDone()
+		browserPool.RunHealthChecks(ctx, cfg.Browser.HealthCheckInterval)
+		browserPool.Close()
+		log.Printf("Browser pool closed, stats: %+v\n", browserPool.Stats())
+	}()
+
+	artifacts, err := getArtifactStore(cfg.Artifacts)
+	if err != nil {
+		log.Fatalf("failed to create artifact store: %v", err)
+	}
+
+	identities, err := getIdentityProvider(ctx, cfg.Browser)
+	if err != nil {
+		log.Fatalf("failed to configure crawl identities: %v", err)
+	}
 
+	rodWebCrawler := infrastructure.NewRodRokuWebCrawler(browserPool, profile, identities, artifacts)
+	rokuWebCrawler, err := getWebCrawler(cfg.Crawler.Engine, profile, rodWebCrawler)
+	if err != nil {
+		log.Fatalf("failed to create web crawler: %v", err)
+	}
+
+	storeRoutes, err := cmd.GetStoreRoutes(cfg.Stores)
+	if err != nil {
+		log.Fatalf("failed to configure stores: %v", err)
+	}
+
+	storeWebCrawlers := map[domain.Store]domain.ChannelWebCrawler{cmd.RokuStore: rokuWebCrawler}
+	jsonLDWebCrawler := infrastructure.NewJSONLDWebCrawler(&http.Client{})
+	for _, store := range cfg.Stores.JSONLDHosts {
+		storeWebCrawlers[domain.Store(store)] = jsonLDWebCrawler
+	}
+	storeResolver := domain.NewHostStoreResolver(storeRoutes)
+	webCrawler := domain.NewStoreWebCrawlerRegistry(storeResolver, storeWebCrawlers)
+
+	// Channels stored by the previous versions are migrated by the migrate command before the workers are started
 	repo := infrastructure.NewMongoChannelRepository(db)
-	processor := domain.NewChannelCrawlerProcessor(webCrawler, repo)
-	app := application.NewAmqpApplication(ch, cfg.AMQP.QueueName, processor, cfg.Crawler.WorkersAmount)
+	err = repo.EnsureIndexes(ctx)
+	if err != nil {
+		log.Fatalf("failed to create database indexes: %v", err)
+	}
+
+	jobs := infrastructure.NewMongoJobRepository(db, cfg.Dedup.Window)
+	processor := domain.NewChannelCrawlerProcessor(webCrawler, storeResolver, repo, jobs)
+	retryPublisher := infrastructure.NewAmqpRetryPublisher(ch, cfg.AMQP.ExchangeName, cfg.AMQP.RetryDelays)
+
+	rateLimiter, err := getHostRateLimiter(ctx, cfg.Crawler, db)
+	if err != nil {
+		log.Fatalf("failed to create rate limiter: %v", err)
+	}
+
+	laneWeights, err := getLaneWeights(cfg.AMQP.LaneWeights)
+	if err != nil {
+		log.Fatalf("failed to configure priority lanes: %v", err)
+	}
+
+	app := application.NewAmqpApplication(
+		ch,
+		cfg.AMQP.QueueName,
+		processor,
+		jobs,
+		retryPublisher,
+		rateLimiter,
+		cfg.AMQP.MaxAttempts,
+		cfg.Crawler.WorkersAmount,
+		laneWeights,
+		cfg.AMQP.QueueDepthInterval,
+	)
+
+	cmd.ServeMetrics(ctx, cfg.Metrics.Port, notifyStart, notifyDone)
 
 	err = app.Run(ctx, notifyStart, notifyDone)
 	if err != nil {
@@ -70,42 +174,146 @@ func main() {
 	wg.Wait()
 }
 
-func getMongoDB(
-	ctx context.Context,
-	dsn string,
-	databaseName string,
-	notifyStart func(),
-	notifyDone func(),
-) (*mongo.Database, error) {
-	client, err := mongo.Connect(ctx, options.Client().ApplyURI(dsn))
+func getExtractionProfile(path string) (*infrastructure.ExtractionProfile, error) {
+	if path == "" {
+		return infrastructure.DefaultExtractionProfile()
+	}
+
+	return infrastructure.LoadExtractionProfile(path)
+}
+
+// getLaneWeights validates the weights of the priority lanes, at least one lane has to get turns
+func getLaneWeights(weights map[string]int) (map[domain.Priority]int, error) {
+	laneWeights := make(map[domain.Priority]int, len(weights))
+	total := 0
+	for value, weight := range weights {
+		priority, err := domain.NewPriority(value)
+		if err != nil {
+			return nil, err
+		}
+		if weight < 0 {
+			return nil, fmt.Errorf("weight of %s lane could not be negative", *priority)
+		}
+
+		laneWeights[*priority] = weight
+		total += weight
+	}
+
+	if total == 0 {
+		return nil, errors.New("at least one lane has to have positive weight")
+	}
+
+	return laneWeights, nil
+}
+
+// getIdentityProvider creates provider of the identities the browser crawls the pages with, SOCKS5 proxies
+// with credentials are forwarded until the context is done
+func getIdentityProvider(ctx context.Context, cfg config.Browser) (infrastructure.IdentityProvider, error) {
+	var pool *infrastructure.IdentityPool
+	var err error
+	if cfg.IdentityPath == "" {
+		pool, err = infrastructure.DefaultIdentityPool()
+	} else {
+		pool, err = infrastructure.LoadIdentityPool(cfg.IdentityPath)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	notifyStart()
-	go func() {
-		defer notifyDone()
-		<-ctx.Done()
-		err := client.Disconnect(ctx)
+	proxies := make([]*infrastructure.Proxy, 0, len(cfg.Proxies))
+	for _, rawUrl := range cfg.Proxies {
+		proxy, err := infrastructure.NewProxy(rawUrl)
 		if err != nil {
-			log.Printf("failed to disconnect from db: %v\n", err)
-		} else {
-			log.Println("MongoDB connection closed")
+			return nil, err
 		}
-	}()
 
-	return client.Database(databaseName), nil
+		err = proxy.Forward(ctx)
+		if err != nil {
+			return nil, err
+		}
+		proxies = append(proxies, proxy)
+	}
+	log.Printf("Crawling pages through %d proxies\n", len(proxies))
+
+	return infrastructure.NewRotatingIdentityProvider(pool, proxies, cfg.ProxyMaxFailures, cfg.ProxyCooldown), nil
 }
 
-func getHeadlessBrowser(ctx context.Context) *rod.Browser {
-	u := launcher.New().Bin("/usr/bin/chromium-browser").MustLaunch()
+// getArtifactStore creates store of the failure artifacts, nil store disables capturing them
+func getArtifactStore(cfg config.Artifacts) (domain.ArtifactStore, error) {
+	switch cfg.Store {
+	case artifactStoreNone:
+		return nil, nil
+	case artifactStoreFile:
+		return infrastructure.NewFileArtifactStore(cfg.Dir), nil
+	case artifactStoreS3:
+		return infrastructure.NewS3ArtifactStore(
+			cfg.S3Endpoint,
+			cfg.S3Region,
+			cfg.S3Bucket,
+			cfg.S3AccessKey,
+			cfg.S3SecretKey,
+			cfg.S3UseSSL,
+		)
+	default:
+		return nil, fmt.Errorf("unknown artifact store: %s", cfg.Store)
+	}
+}
 
-	browser := rod.New().ControlURL(u).MustConnect()
+// getHostRateLimiter creates limiter spacing the crawls of the same host, nil limiter disables it
+func getHostRateLimiter(
+	ctx context.Context,
+	cfg config.Crawler,
+	db *mongo.Database,
+) (domain.HostRateLimiter, error) {
+	if cfg.RateLimitStore == rateLimitStoreNone {
+		return nil, nil
+	}
 
-	go func() {
-		<-ctx.Done()
-		browser.MustClose()
-	}()
+	policy := infrastructure.RateLimitPolicy{
+		Interval:    cfg.RateLimitInterval,
+		MaxInterval: cfg.RateLimitMaxInterval,
+		Jitter:      cfg.RateLimitJitter,
+	}
+	if policy.Interval <= 0 || policy.MaxInterval < policy.Interval {
+		return nil, fmt.Errorf(
+			"rate limit interval has to be positive and not above the max interval %s, got %s",
+			policy.MaxInterval,
+			policy.Interval,
+		)
+	}
 
-	return browser
+	switch cfg.RateLimitStore {
+	case rateLimitStoreMemory:
+		return infrastructure.NewHostRateLimiter(infrastructure.NewMemoryHostRateStore(policy), policy), nil
+	case rateLimitStoreMongo:
+		store := infrastructure.NewMongoHostRateStore(db, policy)
+		err := store.EnsureIndexes(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return infrastructure.NewHostRateLimiter(store, policy), nil
+	default:
+		return nil, fmt.Errorf("unknown rate limit store: %s", cfg.RateLimitStore)
+	}
+}
+
+// getWebCrawler creates crawler of given engine
+func getWebCrawler(
+	engine string,
+	profile *infrastructure.ExtractionProfile,
+	rodWebCrawler domain.ChannelWebCrawler,
+) (domain.ChannelWebCrawler, error) {
+	switch engine {
+	case crawlerEngineRod:
+		return rodWebCrawler, nil
+	case crawlerEngineHttp:
+		return infrastructure.NewHttpRokuWebCrawler(&http.Client{}, profile), nil
+	case crawlerEngineFallback:
+		return infrastructure.NewFallbackWebCrawler(
+			infrastructure.NewHttpRokuWebCrawler(&http.Client{}, profile),
+			rodWebCrawler,
+		), nil
+	default:
+		return nil, fmt.Errorf("unknown crawler engine: %s", engine)
+	}
 }
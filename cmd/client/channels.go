@@ -0,0 +1,415 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	grpcwebcrawler "go-web-crawler-service/protobuf/webcrawler"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Output formats of the query commands
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputCSV   = "csv"
+)
+
+const exportPageSize = 500
+
+var channelCSVHeader = []string{
+	"id",
+	"store",
+	"store_channel_id",
+	"application_name",
+	"url",
+	"locale",
+	"country",
+	"rating",
+	"number_of_ratings",
+	"status",
+	"developer",
+	"category",
+	"price",
+	"content_rating",
+	"profile_version",
+	"proxy",
+	"updated_at",
+	"delisted_at",
+}
+
+func runGet(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) error {
+	flags := newFlagSet("get", "[flags] <channel url>", stderr)
+	connection := addConnectionFlags(flags)
+	id := flags.String("id", "", "Id of the channel, the channel is looked up by the url when empty")
+	country := flags.String("country", "", "Country the channel was crawled in, e.g. GB, the store default when empty")
+	output := flags.String("output", outputTable, "Output format: table or json")
+	err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+
+	request := &grpcwebcrawler.GetChannelRequest{}
+	switch {
+	case *id != "" && flags.NArg() == 0:
+		request.Identifier = &grpcwebcrawler.GetChannelRequest_ChannelId{ChannelId: *id}
+	case *id == "" && flags.NArg() == 1:
+		request.Identifier = &grpcwebcrawler.GetChannelRequest_Url{Url: flags.Arg(0)}
+		request.Country = *country
+	default:
+		flags.Usage()
+		return withExitCode(exitUsage, errors.New("either channel url or -id is required"))
+	}
+
+	if *output != outputTable && *output != outputJSON {
+		return withExitCode(exitUsage, fmt.Errorf("unknown output format %s", *output))
+	}
+
+	client, closeConn, err := connect(ctx, connection)
+	if err != nil {
+		return err
+	}
+	defer closeConn()
+
+	callCtx, cancel := context.WithTimeout(ctx, connection.timeout)
+	defer cancel()
+
+	channel, err := client.GetChannel(callCtx, request)
+	if err != nil {
+		return fmt.Errorf("failed to get channel, error: %w", err)
+	}
+
+	if *output == outputJSON {
+		return writeJSONLine(stdout, channel)
+	}
+
+	table := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	record := channelRecord(channel)
+	for i, field := range channelCSVHeader {
+		fmt.Fprintf(table, "%s:\t%s\n", field, record[i])
+	}
+	fmt.Fprintf(table, "star_distribution:\t%v\n", channel.StarDistribution)
+	for _, nameChange := range channel.NameChanges {
+		fmt.Fprintf(
+			table,
+			"renamed:\t%s -> %s at %s\n",
+			nameChange.From,
+			nameChange.To,
+			formatTimestamp(nameChange.ChangedAt),
+		)
+	}
+
+	return table.Flush()
+}
+
+func runList(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) error {
+	flags := newFlagSet("list", "[flags]", stderr)
+	connection := addConnectionFlags(flags)
+	filter := addChannelFilterFlags(flags)
+	limit := flags.Int("limit", 50, "Max amount of listed channels, 0 lists all of them")
+	output := flags.String("output", outputTable, "Output format: table or json (JSON Lines)")
+	err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+
+	if *output != outputTable && *output != outputJSON {
+		return withExitCode(exitUsage, fmt.Errorf("unknown output format %s", *output))
+	}
+
+	request, err := filter.request(flags)
+	if err != nil {
+		return withExitCode(exitUsage, err)
+	}
+
+	client, closeConn, err := connect(ctx, connection)
+	if err != nil {
+		return err
+	}
+	defer closeConn()
+
+	table := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	if *output == outputTable {
+		fmt.Fprintln(table, "ID\tSTORE\tNAME\tRATING\tRATINGS\tSTATUS\tUPDATED")
+	}
+
+	listed := 0
+	err = listChannels(
+		ctx, client, request, connection.timeout, *limit, func(channel *grpcwebcrawler.Channel) error {
+			listed++
+			if *output == outputJSON {
+				return writeJSONLine(stdout, channel)
+			}
+
+			_, err := fmt.Fprintf(
+				table,
+				"%s\t%s\t%s\t%.2f\t%d\t%s\t%s\n",
+				channel.Id,
+				channel.Store,
+				channel.ApplicationName,
+				channel.Rating,
+				channel.NumberOfRatings,
+				channelStatusLabel(channel.Status),
+				formatTimestamp(channel.UpdatedAt),
+			)
+			return err
+		},
+	)
+	if flushErr := table.Flush(); err == nil {
+		err = flushErr
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stderr, "%d channels listed\n", listed)
+	return nil
+}
+
+func runExport(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) error {
+	flags := newFlagSet("export", "[flags]", stderr)
+	connection := addConnectionFlags(flags)
+	filter := addChannelFilterFlags(flags)
+	format := flags.String("format", outputCSV, "Export format: csv or json (JSON Lines)")
+	out := flags.String("out", "", "File the channels are exported to, stdout when empty")
+	err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+
+	if *format != outputCSV && *format != outputJSON {
+		return withExitCode(exitUsage, fmt.Errorf("unknown export format %s", *format))
+	}
+
+	request, err := filter.request(flags)
+	if err != nil {
+		return withExitCode(exitUsage, err)
+	}
+	request.PageSize = exportPageSize
+
+	client, closeConn, err := connect(ctx, connection)
+	if err != nil {
+		return err
+	}
+	defer closeConn()
+
+	w := stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	csvWriter := csv.NewWriter(w)
+	if *format == outputCSV {
+		err = csvWriter.Write(channelCSVHeader)
+		if err != nil {
+			return err
+		}
+	}
+
+	exported := 0
+	err = listChannels(
+		ctx, client, request, connection.timeout, 0, func(channel *grpcwebcrawler.Channel) error {
+			exported++
+			if exported%exportPageSize == 0 {
+				fmt.Fprintf(stderr, "%d channels exported\n", exported)
+			}
+
+			if *format == outputJSON {
+				return writeJSONLine(w, channel)
+			}
+			return csvWriter.Write(channelRecord(channel))
+		},
+	)
+	csvWriter.Flush()
+	if err == nil {
+		err = csvWriter.Error()
+	}
+	if err != nil {
+		return fmt.Errorf("export interrupted after %d channels, error: %w", exported, err)
+	}
+
+	fmt.Fprintf(stderr, "%d channels exported\n", exported)
+	return nil
+}
+
+// listChannels follows the cursor until there are no more channels or limit of them was listed, 0 limit lists
+// all of them
+func listChannels(
+	ctx context.Context,
+	client grpcwebcrawler.WebCrawlerServiceClient,
+	request *grpcwebcrawler.ListChannelsRequest,
+	timeout time.Duration,
+	limit int,
+	handle func(channel *grpcwebcrawler.Channel) error,
+) error {
+	listed := 0
+	for {
+		if limit > 0 && (request.PageSize == 0 || int(request.PageSize) > limit-listed) {
+			request.PageSize = uint32(limit - listed)
+		}
+
+		callCtx, cancel := context.WithTimeout(ctx, timeout)
+		response, err := client.ListChannels(callCtx, request)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("failed to list channels, error: %w", err)
+		}
+
+		for _, channel := range response.Channels {
+			err = handle(channel)
+			if err != nil {
+				return err
+			}
+			listed++
+		}
+
+		if response.NextCursor == "" || (limit > 0 && listed >= limit) {
+			return nil
+		}
+		request.Cursor = response.NextCursor
+	}
+}
+
+type channelFilterOptions struct {
+	minRating          float64
+	minNumberOfRatings uint64
+	namePrefix         string
+	updatedSince       string
+	status             string
+	country            string
+}
+
+// addChannelFilterFlags registers flags of the channel filter shared by list and export commands
+func addChannelFilterFlags(flags *flag.FlagSet) *channelFilterOptions {
+	options := &channelFilterOptions{}
+	flags.Float64Var(&options.minRating, "min-rating", 0, "Minimal rating of the channel")
+	flags.Uint64Var(&options.minNumberOfRatings, "min-ratings", 0, "Minimal amount of ratings of the channel")
+	flags.StringVar(&options.namePrefix, "name-prefix", "", "Prefix of the application name")
+	flags.StringVar(
+		&options.updatedSince,
+		"updated-since",
+		"",
+		"Channels updated since the time (RFC 3339) or within the duration (e.g. 24h)",
+	)
+	flags.StringVar(&options.status, "status", "", "Status of the channel: active or delisted, any when empty")
+	flags.StringVar(
+		&options.country,
+		"country",
+		"",
+		"Country the channels were crawled in, e.g. GB, any when unset, the store default when set empty",
+	)
+
+	return options
+}
+
+// request creates the list request from the filter flags, only the flags that were set filter the channels
+func (o *channelFilterOptions) request(flags *flag.FlagSet) (*grpcwebcrawler.ListChannelsRequest, error) {
+	request := &grpcwebcrawler.ListChannelsRequest{NamePrefix: o.namePrefix}
+
+	var err error
+	flags.Visit(
+		func(f *flag.Flag) {
+			switch f.Name {
+			case "min-rating":
+				request.MinRating = proto.Float32(float32(o.minRating))
+			case "min-ratings":
+				request.MinNumberOfRatings = proto.Uint32(uint32(o.minNumberOfRatings))
+			case "country":
+				request.Country = proto.String(o.country)
+			}
+		},
+	)
+
+	if o.updatedSince != "" {
+		request.UpdatedSince, err = parseUpdatedSince(o.updatedSince)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	switch o.status {
+	case "":
+	case "active":
+		request.Status = grpcwebcrawler.ChannelStatus_CHANNEL_STATUS_ACTIVE
+	case "delisted":
+		request.Status = grpcwebcrawler.ChannelStatus_CHANNEL_STATUS_DELISTED
+	default:
+		return nil, fmt.Errorf("unknown channel status %s", o.status)
+	}
+
+	return request, nil
+}
+
+func parseUpdatedSince(value string) (*timestamppb.Timestamp, error) {
+	if duration, err := time.ParseDuration(value); err == nil {
+		return timestamppb.New(time.Now().Add(-duration)), nil
+	}
+
+	updatedSince, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("updated since has to be RFC 3339 time or duration, got %s", value)
+	}
+
+	return timestamppb.New(updatedSince), nil
+}
+
+// channelRecord returns the channel fields in the order of channelCSVHeader
+func channelRecord(channel *grpcwebcrawler.Channel) []string {
+	return []string{
+		channel.Id,
+		channel.Store,
+		channel.StoreChannelId,
+		channel.ApplicationName,
+		channel.Url,
+		channel.Locale,
+		channel.Country,
+		strconv.FormatFloat(float64(channel.Rating), 'f', -1, 32),
+		strconv.FormatUint(uint64(channel.NumberOfRatings), 10),
+		channelStatusLabel(channel.Status),
+		channel.Developer,
+		channel.Category,
+		channel.Price,
+		channel.ContentRating,
+		channel.ProfileVersion,
+		channel.Proxy,
+		formatTimestamp(channel.UpdatedAt),
+		formatTimestamp(channel.DelistedAt),
+	}
+}
+
+func channelStatusLabel(channelStatus grpcwebcrawler.ChannelStatus) string {
+	return strings.ToLower(strings.TrimPrefix(channelStatus.String(), "CHANNEL_STATUS_"))
+}
+
+func formatTimestamp(timestamp *timestamppb.Timestamp) string {
+	if timestamp == nil {
+		return ""
+	}
+
+	return timestamp.AsTime().Format(time.RFC3339)
+}
+
+func writeJSONLine(w io.Writer, message proto.Message) error {
+	line, err := protojson.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "%s\n", line)
+	return err
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Formats of the submitted urls
+const (
+	formatAuto  = "auto"
+	formatCSV   = "csv"
+	formatTSV   = "tsv"
+	formatLines = "lines"
+	formatJSONL = "jsonl"
+)
+
+// Names of the url column recognized in the header when no column is selected
+var defaultUrlColumns = []string{"page_url", "url"}
+
+// urlReader reads the urls one by one, io.EOF is returned once there are no more urls
+type urlReader interface {
+	Read() (string, error)
+}
+
+// detectFormat resolves the auto format by the file extension, the input without known extension is read as lines
+func detectFormat(format string, path string) (string, error) {
+	if format != formatAuto {
+		switch format {
+		case formatCSV, formatTSV, formatLines, formatJSONL:
+			return format, nil
+		default:
+			return "", fmt.Errorf("unknown input format %s", format)
+		}
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return formatCSV, nil
+	case ".tsv":
+		return formatTSV, nil
+	case ".jsonl", ".ndjson":
+		return formatJSONL, nil
+	default:
+		return formatLines, nil
+	}
+}
+
+// newUrlReader creates reader of the input in the format. Column selects the url column of the CSV and TSV input
+// by its header name or 1-based index and the url field of the JSON Lines input.
+func newUrlReader(input io.Reader, format string, column string) (urlReader, error) {
+	switch format {
+	case formatCSV:
+		return newTableUrlReader(input, ',', column), nil
+	case formatTSV:
+		return newTableUrlReader(input, '\t', column), nil
+	case formatLines:
+		return &linesUrlReader{scanner: bufio.NewScanner(input)}, nil
+	case formatJSONL:
+		if column == "" {
+			column = "url"
+		}
+		return &jsonLinesUrlReader{scanner: bufio.NewScanner(input), field: column}, nil
+	default:
+		return nil, fmt.Errorf("unknown input format %s", format)
+	}
+}
+
+// tableUrlReader reads the url column of CSV or TSV input. The first row is the header when it contains
+// the selected column name (page_url or url by default) or when the selected cell of it is not an url.
+type tableUrlReader struct {
+	reader *csv.Reader
+	column string
+	index  int
+	// row is a number of the last read row used in the errors
+	row int
+}
+
+func newTableUrlReader(input io.Reader, separator rune, column string) *tableUrlReader {
+	reader := csv.NewReader(input)
+	reader.Comma = separator
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	return &tableUrlReader{reader: reader, column: column, index: -1}
+}
+
+func (r *tableUrlReader) Read() (string, error) {
+	for {
+		record, err := r.reader.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return "", io.EOF
+			}
+			return "", fmt.Errorf("failed to read row %d, error: %w", r.row+1, err)
+		}
+		r.row++
+
+		if r.index < 0 {
+			isHeader, err := r.resolveColumn(record)
+			if err != nil {
+				return "", err
+			}
+			if isHeader {
+				continue
+			}
+		}
+
+		if r.index >= len(record) {
+			return "", fmt.Errorf("row %d has no column %d", r.row, r.index+1)
+		}
+
+		value := strings.TrimSpace(record[r.index])
+		if value == "" {
+			continue
+		}
+
+		return value, nil
+	}
+}
+
+// resolveColumn finds index of the url column in the first row and tells whether the row is the header
+func (r *tableUrlReader) resolveColumn(record []string) (bool, error) {
+	if index, err := strconv.Atoi(r.column); err == nil {
+		if index < 1 {
+			return false, fmt.Errorf("column index has to start at 1, got %d", index)
+		}
+		r.index = index - 1
+
+		return r.index < len(record) && !looksLikeUrl(record[r.index]), nil
+	}
+
+	names := defaultUrlColumns
+	if r.column != "" {
+		names = []string{r.column}
+	}
+
+	for i, cell := range record {
+		for _, name := range names {
+			if strings.EqualFold(strings.TrimSpace(cell), name) {
+				r.index = i
+				return true, nil
+			}
+		}
+	}
+
+	if r.column != "" {
+		return false, fmt.Errorf("column %s not found in the header", r.column)
+	}
+
+	// Input without header holds the urls in the first column
+	r.index = 0
+	return !looksLikeUrl(record[0]), nil
+}
+
+// linesUrlReader reads an url per line, empty lines and lines starting with # are skipped
+type linesUrlReader struct {
+	scanner *bufio.Scanner
+}
+
+func (r *linesUrlReader) Read() (string, error) {
+	for r.scanner.Scan() {
+		line := strings.TrimSpace(r.scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		return line, nil
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read input, error: %w", err)
+	}
+
+	return "", io.EOF
+}
+
+// jsonLinesUrlReader reads the url field of JSON object per line, line could also be a plain JSON string
+type jsonLinesUrlReader struct {
+	scanner *bufio.Scanner
+	field   string
+	line    int
+}
+
+func (r *jsonLinesUrlReader) Read() (string, error) {
+	for r.scanner.Scan() {
+		r.line++
+		line := strings.TrimSpace(r.scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var value interface{}
+		err := json.Unmarshal([]byte(line), &value)
+		if err != nil {
+			return "", fmt.Errorf("line %d is not valid JSON, error: %w", r.line, err)
+		}
+
+		switch typedValue := value.(type) {
+		case string:
+			return typedValue, nil
+		case map[string]interface{}:
+			url, ok := typedValue[r.field].(string)
+			if !ok {
+				return "", fmt.Errorf("line %d has no %s string field", r.line, r.field)
+			}
+			return url, nil
+		default:
+			return "", fmt.Errorf("line %d is neither JSON object nor string", r.line)
+		}
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read input, error: %w", err)
+	}
+
+	return "", io.EOF
+}
+
+func looksLikeUrl(value string) bool {
+	value = strings.ToLower(strings.TrimSpace(value))
+	return strings.HasPrefix(value, "http://") || strings.HasPrefix(value, "https://")
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"strings"
+	"testing"
+)
+
+func readAllUrls(t *testing.T, input string, format string, column string) []string {
+	reader, err := newUrlReader(strings.NewReader(input), format, column)
+	require.NoError(t, err)
+
+	var urls []string
+	for {
+		url, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return urls
+		}
+		require.NoError(t, err)
+		urls = append(urls, url)
+	}
+}
+
+func TestUrlReader(t *testing.T) {
+	expected := []string{"https://google.com/first", "https://google.com/second"}
+
+	testCases := map[string]struct {
+		input  string
+		format string
+		column string
+	}{
+		"csv with page_url header": {
+			input:  "page_url\nhttps://google.com/first\n\nhttps://google.com/second\n",
+			format: formatCSV,
+		},
+		"csv without header": {
+			input:  "https://google.com/first,a\nhttps://google.com/second,b\n",
+			format: formatCSV,
+		},
+		"csv column by name": {
+			input:  "name,link\nfirst,https://google.com/first\nsecond,https://google.com/second\n",
+			format: formatCSV,
+			column: "link",
+		},
+		"tsv column by index": {
+			input:  "first\thttps://google.com/first\nsecond\thttps://google.com/second\n",
+			format: formatTSV,
+			column: "2",
+		},
+		"lines with comments": {
+			input:  "# channels\nhttps://google.com/first\n\n  https://google.com/second  \n",
+			format: formatLines,
+		},
+		"json lines": {
+			input:  "{\"url\": \"https://google.com/first\"}\n\"https://google.com/second\"\n",
+			format: formatJSONL,
+		},
+		"json lines custom field": {
+			input:  "{\"link\": \"https://google.com/first\"}\n{\"link\": \"https://google.com/second\"}\n",
+			format: formatJSONL,
+			column: "link",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(
+			name, func(t *testing.T) {
+				assert.Equal(t, expected, readAllUrls(t, testCase.input, testCase.format, testCase.column))
+			},
+		)
+	}
+}
+
+func TestUrlReader_Errors(t *testing.T) {
+	reader, err := newUrlReader(strings.NewReader("name,link\nfirst,https://google.com\n"), formatCSV, "url")
+	require.NoError(t, err)
+	_, err = reader.Read()
+	assert.EqualError(t, err, "column url not found in the header")
+
+	reader, err = newUrlReader(strings.NewReader("{\"link\": \"https://google.com\"}\n"), formatJSONL, "")
+	require.NoError(t, err)
+	_, err = reader.Read()
+	assert.EqualError(t, err, "line 1 has no url string field")
+}
+
+func TestDetectFormat(t *testing.T) {
+	testCases := map[string]string{
+		"list.csv":    formatCSV,
+		"list.TSV":    formatTSV,
+		"list.jsonl":  formatJSONL,
+		"list.ndjson": formatJSONL,
+		"list.txt":    formatLines,
+		"":            formatLines,
+	}
+
+	for path, expected := range testCases {
+		format, err := detectFormat(formatAuto, path)
+		require.NoError(t, err)
+		assert.Equal(t, expected, format, path)
+	}
+
+	_, err := detectFormat("xml", "list.xml")
+	require.Error(t, err)
+}
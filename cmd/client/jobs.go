@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	grpcwebcrawler "go-web-crawler-service/protobuf/webcrawler"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"io"
+	"strings"
+)
+
+func runJobs(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) error {
+	flags := newFlagSet("jobs", "[flags] <job id>...", stderr)
+	connection := addConnectionFlags(flags)
+	wait := flags.Bool("wait", false, "Print every change of the jobs until all of them are finished")
+	output := flags.String("output", outputTable, "Output format: table or json (JSON Lines)")
+	err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return withExitCode(exitUsage, errors.New("at least one job id is required"))
+	}
+	if *output != outputTable && *output != outputJSON {
+		return withExitCode(exitUsage, fmt.Errorf("unknown output format %s", *output))
+	}
+
+	client, closeConn, err := connect(ctx, connection)
+	if err != nil {
+		return err
+	}
+	defer closeConn()
+
+	printer := newJobPrinter(stdout, *output)
+	if *wait {
+		err = watchJobs(ctx, client, flags.Args(), printer)
+	} else {
+		err = getJobs(ctx, client, flags.Args(), connection, printer, stderr)
+	}
+	summaryErr := printer.summary(stderr)
+	if err != nil {
+		return err
+	}
+
+	return summaryErr
+}
+
+// getJobs prints the current state of the jobs, jobs that do not exist are reported and skipped
+func getJobs(
+	ctx context.Context,
+	client grpcwebcrawler.WebCrawlerServiceClient,
+	jobIDs []string,
+	connection *connectionOptions,
+	printer *jobPrinter,
+	stderr io.Writer,
+) error {
+	notFound := 0
+	for _, jobID := range jobIDs {
+		callCtx, cancel := context.WithTimeout(ctx, connection.timeout)
+		job, err := client.GetJob(callCtx, &grpcwebcrawler.GetJobRequest{JobId: jobID})
+		cancel()
+		if status.Code(err) == codes.NotFound {
+			notFound++
+			fmt.Fprintf(stderr, "job %s not found\n", jobID)
+			continue
+		} else if err != nil {
+			return fmt.Errorf("failed to get job %s, error: %w", jobID, err)
+		}
+
+		err = printer.print(job)
+		if err != nil {
+			return err
+		}
+	}
+
+	if notFound > 0 {
+		return withExitCode(exitNotFound, fmt.Errorf("%d jobs not found", notFound))
+	}
+
+	return nil
+}
+
+// watchJobs prints every change of the jobs until all of them are finished
+func watchJobs(
+	ctx context.Context,
+	client grpcwebcrawler.WebCrawlerServiceClient,
+	jobIDs []string,
+	printer *jobPrinter,
+) error {
+	stream, err := client.WatchJobs(ctx, &grpcwebcrawler.WatchJobsRequest{JobIds: jobIDs})
+	if err != nil {
+		return fmt.Errorf("failed to watch jobs, error: %w", err)
+	}
+
+	for {
+		job, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("failed to watch jobs, error: %w", err)
+		}
+
+		err = printer.print(job)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// jobPrinter prints the jobs and keeps their latest state for the summary
+type jobPrinter struct {
+	w      io.Writer
+	output string
+	latest map[string]grpcwebcrawler.JobStatus
+}
+
+func newJobPrinter(w io.Writer, output string) *jobPrinter {
+	return &jobPrinter{w: w, output: output, latest: map[string]grpcwebcrawler.JobStatus{}}
+}
+
+func (p *jobPrinter) print(job *grpcwebcrawler.Job) error {
+	p.latest[job.Id] = job.Status
+	if p.output == outputJSON {
+		return writeJSONLine(p.w, job)
+	}
+
+	// Jobs are printed as they change, so the columns are padded instead of aligned by tabwriter
+	_, err := fmt.Fprintf(
+		p.w,
+		"%-32s  %-7s  %-20s  %s  %s\n",
+		job.Id,
+		jobStatusLabel(job.Status),
+		formatTimestamp(job.UpdatedAt),
+		job.Url,
+		job.Error,
+	)
+
+	return err
+}
+
+// summary prints the amount of jobs by their latest status, failed jobs end the client with the partial exit code
+func (p *jobPrinter) summary(w io.Writer) error {
+	amounts := map[grpcwebcrawler.JobStatus]int{}
+	for _, jobStatus := range p.latest {
+		amounts[jobStatus]++
+	}
+
+	fmt.Fprintf(
+		w,
+		"%d jobs: %d queued, %d running, %d done, %d failed\n",
+		len(p.latest),
+		amounts[grpcwebcrawler.JobStatus_JOB_STATUS_QUEUED],
+		amounts[grpcwebcrawler.JobStatus_JOB_STATUS_RUNNING],
+		amounts[grpcwebcrawler.JobStatus_JOB_STATUS_DONE],
+		amounts[grpcwebcrawler.JobStatus_JOB_STATUS_FAILED],
+	)
+
+	if failed := amounts[grpcwebcrawler.JobStatus_JOB_STATUS_FAILED]; failed > 0 {
+		return withExitCode(exitPartial, fmt.Errorf("%d jobs failed", failed))
+	}
+
+	return nil
+}
+
+func jobStatusLabel(jobStatus grpcwebcrawler.JobStatus) string {
+	return strings.ToLower(strings.TrimPrefix(jobStatus.String(), "JOB_STATUS_"))
+}
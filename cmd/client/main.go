@@ -2,124 +2,199 @@ package main
 
 import (
 	"context"
-	"encoding/csv"
+	"errors"
 	"flag"
 	"fmt"
-	"go-web-crawler-service/config"
 	grpcwebcrawler "go-web-crawler-service/protobuf/webcrawler"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
 	"io"
-	"log"
 	"os"
-	"sync"
+	"os/signal"
 	"time"
 )
 
+// Exit codes of the client
 const (
-	grpcHostEnv = "GRPC_HOST"
+	exitOK = 0
+	// exitFailure means that the command failed, e.g. the API is not reachable
+	exitFailure = 1
+	// exitUsage means that the command or its flags are not valid
+	exitUsage = 2
+	// exitPartial means that the command finished but some of the urls were not accepted or some of the jobs failed
+	exitPartial = 3
+	// exitNotFound means that the requested channel or job does not exist
+	exitNotFound = 4
 )
 
-var (
-	csvFile = flag.String("csv", "", "Path to CSV file contains all the urls to index")
+const (
+	grpcHostEnv = "GRPC_HOST"
+	grpcPortEnv = "GRPC_SERVER_PORT"
+
+	defaultGRPCHost = "localhost"
+	defaultGRPCPort = "8454"
 )
 
-// This one is ugly, I didn't focus on it :)
+type command struct {
+	name        string
+	description string
+	run         func(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) error
+}
+
+var commands = []command{
+	{name: "submit", description: "Submit urls to crawl from CSV, TSV, text or JSON Lines input", run: runSubmit},
+	{name: "get", description: "Show crawled channel by its url or id", run: runGet},
+	{name: "list", description: "List crawled channels matching the filters", run: runList},
+	{name: "export", description: "Export all crawled channels matching the filters as CSV or JSON Lines", run: runExport},
+	{name: "jobs", description: "Show status of the crawl jobs, optionally wait until they are finished", run: runJobs},
+}
+
 func main() {
-	flag.Parse()
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	err := run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+	cancel()
 
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	code := exitCodeOf(err)
+	if err != nil && code != exitOK {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+	}
+	os.Exit(code)
+}
 
-	cfg, err := config.ParseConfig()
-	if err != nil {
-		log.Fatalf("got error when parsing config %v", err)
+func run(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) error {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		printUsage(stderr)
+		if len(args) == 0 {
+			return withExitCode(exitUsage, errors.New("command is required"))
+		}
+		return nil
 	}
 
-	grpcConnString, err := resolveGRPCHost(cfg)
-	if err != nil {
-		log.Fatalf("could not resolve grpc host %v", err)
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			return cmd.run(ctx, args[1:], stdout, stderr)
+		}
 	}
 
-	conn, err := grpc.DialContext(ctx, grpcConnString, grpc.WithTransportCredentials(insecure.NewCredentials()))
-	if err != nil {
-		log.Fatalf("failed to connect with GRPC server")
+	printUsage(stderr)
+	return withExitCode(exitUsage, fmt.Errorf("unknown command %s", args[0]))
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: web-crawler-client <command> [flags]\n\nCommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-8s %s\n", cmd.name, cmd.description)
 	}
-	defer conn.Close()
+	fmt.Fprintf(w, "\nRun web-crawler-client <command> -h to see flags of the command.\n")
+}
 
-	client := grpcwebcrawler.NewWebCrawlerServiceClient(conn)
+// exitError carries the exit code the client ends with
+type exitError struct {
+	code int
+	err  error
+}
 
-	f, err := os.Open(*csvFile)
-	if err != nil {
-		log.Fatal(err)
+func withExitCode(code int, err error) error {
+	return &exitError{code: code, err: err}
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+func exitCodeOf(err error) int {
+	if err == nil || errors.Is(err, flag.ErrHelp) {
+		return exitOK
 	}
 
-	defer f.Close()
-
-	urlsToSend := make(chan string)
-	wg := &sync.WaitGroup{}
-
-	go func() {
-		data := make([]*grpcwebcrawler.CrawlerRequest, 0)
-		ticker := time.NewTicker(200 * time.Millisecond)
-		defer ticker.Stop()
-
-		for {
-			select {
-			case <-ticker.C:
-				if len(data) > 0 {
-					log.Printf("try to send batch to server %v", data)
-					_, err := client.CrawlBatch(ctx, &grpcwebcrawler.BatchCrawlerRequest{Urls: data})
-
-					if err != nil {
-						panic(err)
-					}
-					log.Printf("sent batch to server")
-					wg.Add(-len(data))
-					data = make([]*grpcwebcrawler.CrawlerRequest, 0)
-				}
-			case d := <-urlsToSend:
-				log.Printf("adding new url %s", d)
-				data = append(data, &grpcwebcrawler.CrawlerRequest{Url: d})
-			}
-		}
-	}()
+	var exitErr *exitError
+	if errors.As(err, &exitErr) {
+		return exitErr.code
+	}
 
-	csvReader := csv.NewReader(f)
+	if grpcCode(err) == codes.NotFound {
+		return exitNotFound
+	}
 
-	for {
-		rec, err := csvReader.Read()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			log.Fatal(err)
-		}
+	return exitFailure
+}
 
-		if len(rec) != 1 {
-			log.Fatal("unsupported CSV format")
-		}
+// grpcCode returns status code of the GRPC error wrapped in err
+func grpcCode(err error) codes.Code {
+	var statusErr interface {
+		GRPCStatus() *status.Status
+	}
+	if errors.As(err, &statusErr) {
+		return statusErr.GRPCStatus().Code()
+	}
 
-		if rec[0] == "page_url" {
-			continue
-		}
+	return status.Code(err)
+}
 
-		wg.Add(1)
-		urlsToSend <- rec[0]
+// newFlagSet creates flags of the command, errors are returned instead of exiting, so the exit code is set by main
+func newFlagSet(name string, usage string, stderr io.Writer) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: web-crawler-client %s %s\n\nFlags:\n", name, usage)
+		flags.PrintDefaults()
 	}
 
-	if err != nil {
-		log.Fatalf("unable to send channels for indexing, %v", err)
+	return flags
+}
+
+// parseFlags parses flags of the command, invalid flags end the client with the usage exit code
+func parseFlags(flags *flag.FlagSet, args []string) error {
+	err := flags.Parse(args)
+	if err != nil && !errors.Is(err, flag.ErrHelp) {
+		return withExitCode(exitUsage, err)
 	}
 
-	wg.Wait()
+	return err
 }
 
-func resolveGRPCHost(cfg *config.Config) (string, error) {
+type connectionOptions struct {
+	addr    string
+	timeout time.Duration
+}
+
+// addConnectionFlags registers flags of the API connection shared by all the commands
+func addConnectionFlags(flags *flag.FlagSet) *connectionOptions {
+	options := &connectionOptions{}
+	flags.StringVar(&options.addr, "addr", defaultGRPCAddr(), "Address of the GRPC API")
+	flags.DurationVar(&options.timeout, "timeout", 30*time.Second, "Timeout of a single API call")
+
+	return options
+}
+
+// defaultGRPCAddr builds the API address from GRPC_HOST and GRPC_SERVER_PORT env vars shared with the API
+func defaultGRPCAddr() string {
 	host, ok := os.LookupEnv(grpcHostEnv)
 	if !ok {
-		return "", fmt.Errorf("%s env is required", grpcHostEnv)
+		host = defaultGRPCHost
+	}
+
+	port, ok := os.LookupEnv(grpcPortEnv)
+	if !ok {
+		port = defaultGRPCPort
+	}
+
+	return fmt.Sprintf("%s:%s", host, port)
+}
+
+func connect(ctx context.Context, options *connectionOptions) (grpcwebcrawler.WebCrawlerServiceClient, func(), error) {
+	conn, err := grpc.DialContext(ctx, options.addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect with GRPC server %s, error: %w", options.addr, err)
 	}
 
-	return fmt.Sprintf("%s:%d", host, cfg.GRPC.ServerPort), nil
+	return grpcwebcrawler.NewWebCrawlerServiceClient(conn), func() {
+		_ = conn.Close()
+	}, nil
 }
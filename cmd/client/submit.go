@@ -0,0 +1,433 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	grpcwebcrawler "go-web-crawler-service/protobuf/webcrawler"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+const reconnectDelay = 2 * time.Second
+
+// Statuses of the urls reported in the summary, in the order they are printed
+var submitStatuses = []grpcwebcrawler.CrawlStatus{
+	grpcwebcrawler.CrawlStatus_CRAWL_STATUS_ACCEPTED,
+	grpcwebcrawler.CrawlStatus_CRAWL_STATUS_DUPLICATE,
+	grpcwebcrawler.CrawlStatus_CRAWL_STATUS_INVALID,
+	grpcwebcrawler.CrawlStatus_CRAWL_STATUS_PUBLISH_FAILED,
+}
+
+func runSubmit(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) error {
+	flags := newFlagSet("submit", "[flags] [file, stdin when omitted or -]", stderr)
+	connection := addConnectionFlags(flags)
+	format := flags.String("format", formatAuto, "Input format: auto (by file extension), csv, tsv, lines or jsonl")
+	column := flags.String(
+		"column",
+		"",
+		"Url column of CSV/TSV input (header name or 1-based index, page_url or url by default) "+
+			"or url field of JSON Lines input (url by default)",
+	)
+	offset := flags.Uint64("offset", 0, "Offset of the first url to submit, resumes interrupted submission")
+	window := flags.Int("window", 1000, "Max amount of submitted urls waiting for acknowledgement")
+	maxReconnects := flags.Int("max-reconnects", 10, "Max amount of reconnects in a row without any acknowledged url")
+	progressInterval := flags.Duration("progress", 2*time.Second, "How often the progress is printed, 0 disables it")
+	locale := flags.String("locale", "", "Locale the channels are crawled in, e.g. en-GB, the store default when empty")
+	priorityName := flags.String("priority", "normal", "Priority of the crawls: high, normal or low (bulk imports)")
+	err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+
+	if flags.NArg() > 1 {
+		flags.Usage()
+		return withExitCode(exitUsage, errors.New("at most one input file is accepted"))
+	}
+	if *window < 1 {
+		return withExitCode(exitUsage, errors.New("window has to be at least 1"))
+	}
+
+	priority, err := parseCrawlPriority(*priorityName)
+	if err != nil {
+		return withExitCode(exitUsage, err)
+	}
+
+	path := flags.Arg(0)
+	inputFormat, err := detectFormat(*format, path)
+	if err != nil {
+		return withExitCode(exitUsage, err)
+	}
+
+	input := io.Reader(os.Stdin)
+	if path != "" && path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		input = f
+	}
+
+	reader, err := newUrlReader(input, inputFormat, *column)
+	if err != nil {
+		return withExitCode(exitUsage, err)
+	}
+
+	client, closeConn, err := connect(ctx, connection)
+	if err != nil {
+		return err
+	}
+	defer closeConn()
+
+	submitter := newUrlSubmitter(client, reader, *locale, priority, *window, stderr)
+	err = submitter.skip(*offset)
+	if err != nil {
+		return err
+	}
+
+	stopProgress := submitter.printProgress(*progressInterval)
+	err = submitter.run(ctx, *maxReconnects)
+	stopProgress()
+
+	submitter.printSummary(stdout)
+
+	if err != nil {
+		return fmt.Errorf(
+			"submission interrupted, resume it with -offset %d, error: %w",
+			submitter.resumeOffset(),
+			err,
+		)
+	}
+
+	if rejected := submitter.rejected(); rejected > 0 {
+		return withExitCode(exitPartial, fmt.Errorf("%d urls were not accepted", rejected))
+	}
+
+	return nil
+}
+
+// parseCrawlPriority maps the name of the priority to the priority of the request
+func parseCrawlPriority(name string) (grpcwebcrawler.CrawlPriority, error) {
+	value, found := grpcwebcrawler.CrawlPriority_value["CRAWL_PRIORITY_"+strings.ToUpper(name)]
+	if !found || value == int32(grpcwebcrawler.CrawlPriority_CRAWL_PRIORITY_UNSPECIFIED) {
+		return 0, fmt.Errorf("unknown priority %s, expected high, normal or low", name)
+	}
+
+	return grpcwebcrawler.CrawlPriority(value), nil
+}
+
+type submittedUrl struct {
+	offset uint64
+	url    string
+}
+
+// urlSubmitter streams the urls to SubmitUrls call. Urls sent but not acknowledged yet are kept, so they are sent
+// again on the new stream when the previous one was disconnected.
+type urlSubmitter struct {
+	client   grpcwebcrawler.WebCrawlerServiceClient
+	reader   urlReader
+	locale   string
+	priority grpcwebcrawler.CrawlPriority
+	// window is an amount of urls sent ahead of the acknowledged ones
+	window int
+	stderr io.Writer
+	// nextOffset is an offset of the next url read from the input, it's used only by the sending goroutine
+	nextOffset uint64
+	startedAt  time.Time
+
+	mu           sync.Mutex
+	pending      []submittedUrl
+	sent         int
+	acknowledged int
+	statuses     map[grpcwebcrawler.CrawlStatus]int
+}
+
+func newUrlSubmitter(
+	client grpcwebcrawler.WebCrawlerServiceClient,
+	reader urlReader,
+	locale string,
+	priority grpcwebcrawler.CrawlPriority,
+	window int,
+	stderr io.Writer,
+) *urlSubmitter {
+	return &urlSubmitter{
+		client:    client,
+		reader:    reader,
+		locale:    locale,
+		priority:  priority,
+		window:    window,
+		stderr:    stderr,
+		startedAt: time.Now(),
+		statuses:  map[grpcwebcrawler.CrawlStatus]int{},
+	}
+}
+
+// skip reads the urls before the offset, they were submitted before the submission was interrupted
+func (s *urlSubmitter) skip(offset uint64) error {
+	for s.nextOffset < offset {
+		_, err := s.reader.Read()
+		if errors.Is(err, io.EOF) {
+			return withExitCode(
+				exitUsage,
+				fmt.Errorf("input has only %d urls, offset %d is out of it", s.nextOffset, offset),
+			)
+		} else if err != nil {
+			return err
+		}
+		s.nextOffset++
+	}
+
+	return nil
+}
+
+// run submits all the urls, the stream is reopened when it's disconnected until maxReconnects reconnects in a row
+// did not get any url acknowledged
+func (s *urlSubmitter) run(ctx context.Context, maxReconnects int) error {
+	reconnects := 0
+	for {
+		acknowledged := s.acknowledgedAmount()
+		err := s.submit(ctx)
+		if err == nil {
+			return nil
+		}
+
+		if s.acknowledgedAmount() > acknowledged {
+			reconnects = 0
+		}
+		if !isRetryable(err) || reconnects >= maxReconnects {
+			return err
+		}
+
+		reconnects++
+		fmt.Fprintf(s.stderr, "stream disconnected, resuming from offset %d, error: %v\n", s.resumeOffset(), err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(reconnectDelay):
+		}
+	}
+}
+
+// submit streams the pending urls and then the rest of the input until the stream is finished or disconnected
+func (s *urlSubmitter) submit(ctx context.Context) error {
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := s.client.SubmitUrls(streamCtx, grpc.WaitForReady(true))
+	if err != nil {
+		return err
+	}
+
+	inFlight := make(chan struct{}, s.window)
+	sendErr := make(chan error, 1)
+	go func() {
+		sendErr <- s.send(streamCtx, stream, inFlight)
+	}()
+
+	for {
+		response, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			// Server closes the stream once all the sent urls are acknowledged
+			return <-sendErr
+		}
+		if err != nil {
+			// Sender has to stop before the input is read by the next stream
+			cancel()
+			<-sendErr
+			return err
+		}
+
+		s.acknowledge(response)
+		<-inFlight
+	}
+}
+
+// send streams the pending urls first and then the urls read from the input, it waits for the acknowledgements
+// once there is a window of urls in flight
+func (s *urlSubmitter) send(
+	ctx context.Context,
+	stream grpcwebcrawler.WebCrawlerService_SubmitUrlsClient,
+	inFlight chan<- struct{},
+) error {
+	s.mu.Lock()
+	pending := append([]submittedUrl(nil), s.pending...)
+	s.mu.Unlock()
+
+	for {
+		var submitted submittedUrl
+		if len(pending) > 0 {
+			submitted, pending = pending[0], pending[1:]
+		} else {
+			url, err := s.reader.Read()
+			if errors.Is(err, io.EOF) {
+				return stream.CloseSend()
+			} else if err != nil {
+				_ = stream.CloseSend()
+				return err
+			}
+
+			// Url is pending before it's sent, so it's not lost when the stream is disconnected meanwhile
+			submitted = submittedUrl{offset: s.nextOffset, url: url}
+			s.nextOffset++
+			s.mu.Lock()
+			s.pending = append(s.pending, submitted)
+			s.sent++
+			s.mu.Unlock()
+		}
+
+		select {
+		case inFlight <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		// Failure of the stream is returned by Recv
+		err := stream.Send(
+			&grpcwebcrawler.SubmitUrlsRequest{
+				Offset:   submitted.offset,
+				Url:      submitted.url,
+				Locale:   s.locale,
+				Priority: s.priority,
+			},
+		)
+		if err != nil {
+			return nil
+		}
+	}
+}
+
+func (s *urlSubmitter) acknowledge(response *grpcwebcrawler.SubmitUrlsResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.pending) > 0 && s.pending[0].offset == response.Offset {
+		s.pending = s.pending[1:]
+	}
+	s.acknowledged++
+
+	result := response.Result
+	s.statuses[result.Status]++
+	if isRejected(result.Status) {
+		fmt.Fprintf(
+			s.stderr,
+			"url %s (offset %d) was not accepted, status: %s %s\n",
+			result.Url,
+			response.Offset,
+			statusLabel(result.Status),
+			result.Error,
+		)
+	}
+}
+
+// resumeOffset is an offset of the first url that was not acknowledged
+func (s *urlSubmitter) resumeOffset() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.pending) > 0 {
+		return s.pending[0].offset
+	}
+
+	return s.nextOffset
+}
+
+func (s *urlSubmitter) acknowledgedAmount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.acknowledged
+}
+
+// rejected is an amount of urls that were not scheduled, duplicates are not counted as they are scheduled already
+func (s *urlSubmitter) rejected() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rejected := 0
+	for crawlStatus, amount := range s.statuses {
+		if isRejected(crawlStatus) {
+			rejected += amount
+		}
+	}
+
+	return rejected
+}
+
+// printProgress prints the progress in the interval until the returned function is called
+func (s *urlSubmitter) printProgress(interval time.Duration) func() {
+	if interval <= 0 {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				s.mu.Lock()
+				fmt.Fprintf(
+					s.stderr,
+					"submitted %d urls, acknowledged %d (%.0f urls/s)\n",
+					s.sent,
+					s.acknowledged,
+					float64(s.acknowledged)/time.Since(s.startedAt).Seconds(),
+				)
+				s.mu.Unlock()
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+func (s *urlSubmitter) printSummary(w io.Writer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(table, "Submitted urls:\t%d\n", s.sent)
+	fmt.Fprintf(table, "Acknowledged urls:\t%d\n", s.acknowledged)
+	for _, crawlStatus := range submitStatuses {
+		fmt.Fprintf(table, "  %s:\t%d\n", statusLabel(crawlStatus), s.statuses[crawlStatus])
+	}
+	fmt.Fprintf(table, "Took:\t%s\n", time.Since(s.startedAt).Round(time.Millisecond))
+	_ = table.Flush()
+}
+
+func isRejected(crawlStatus grpcwebcrawler.CrawlStatus) bool {
+	return crawlStatus != grpcwebcrawler.CrawlStatus_CRAWL_STATUS_ACCEPTED &&
+		crawlStatus != grpcwebcrawler.CrawlStatus_CRAWL_STATUS_DUPLICATE
+}
+
+// statusLabel turns CRAWL_STATUS_PUBLISH_FAILED to "publish failed"
+func statusLabel(crawlStatus grpcwebcrawler.CrawlStatus) string {
+	label := strings.TrimPrefix(crawlStatus.String(), "CRAWL_STATUS_")
+	return strings.ReplaceAll(strings.ToLower(label), "_", " ")
+}
+
+// isRetryable tells whether the submission could continue on a new stream
+func isRetryable(err error) bool {
+	switch grpcCode(err) {
+	case codes.Unavailable, codes.Aborted, codes.ResourceExhausted:
+		return true
+	default:
+		return false
+	}
+}
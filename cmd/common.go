@@ -2,24 +2,149 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/streadway/amqp"
+	"go-web-crawler-service/config"
+	"go-web-crawler-service/domain"
+	"go-web-crawler-service/infrastructure"
+	"go-web-crawler-service/metrics"
+	"go-web-crawler-service/tracing"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 	"log"
+	"net/http"
+	"os"
+	"sort"
+	"time"
 )
 
-func InitializeAMQPExchange(ch *amqp.Channel, exchangeName string, queueName string, routingKey string) error {
+// RokuStore is the store crawled with the extraction profile, the rest of the stores are crawled from JSON-LD
+const RokuStore domain.Store = "roku"
+
+// GetStoreRoutes creates routes of the configured stores, Roku routes go first
+func GetStoreRoutes(cfg config.Stores) ([]domain.StoreRoute, error) {
+	routes := make([]domain.StoreRoute, 0, len(cfg.RokuHosts)+len(cfg.JSONLDHosts))
+	for _, hostPattern := range cfg.RokuHosts {
+		route, err := domain.NewStoreRoute(hostPattern, string(RokuStore))
+		if err != nil {
+			return nil, err
+		}
+		routes = append(routes, *route)
+	}
+
+	hostPatterns := make([]string, 0, len(cfg.JSONLDHosts))
+	for hostPattern := range cfg.JSONLDHosts {
+		hostPatterns = append(hostPatterns, hostPattern)
+	}
+	sort.Strings(hostPatterns)
+
+	for _, hostPattern := range hostPatterns {
+		route, err := domain.NewStoreRoute(hostPattern, cfg.JSONLDHosts[hostPattern])
+		if err != nil {
+			return nil, err
+		}
+		routes = append(routes, *route)
+	}
+
+	return routes, nil
+}
+
+func InitializeAMQPExchange(
+	ch *amqp.Channel,
+	exchangeName string,
+	queueName string,
+	routingKey string,
+	retryDelays []time.Duration,
+) error {
 	err := ch.ExchangeDeclare(exchangeName, "direct", true, false, false, false, nil)
 	if err != nil {
 		return fmt.Errorf("could not declare AMQP exchange, %w", err)
 	}
-	_, err = ch.QueueDeclare(queueName, true, false, false, false, nil)
+
+	err = initializeAMQPDeadLetterExchange(ch, exchangeName, queueName)
 	if err != nil {
-		return fmt.Errorf("could not declare AMQP queue, %w", err)
+		return err
+	}
+
+	// Every priority has its own queue, retried messages keep their routing key, so they come back to their lane.
+	// Lanes are declared without arguments, as the normal lane is the queue of the previous versions and
+	// redeclaring it with different arguments fails, the worker parks the messages itself.
+	for _, priority := range domain.Priorities {
+		laneQueue := infrastructure.LaneQueueName(queueName, priority)
+		_, err = ch.QueueDeclare(laneQueue, true, false, false, false, nil)
+		if err != nil {
+			return fmt.Errorf("could not declare AMQP queue %s, %w", laneQueue, err)
+		}
+
+		err = ch.QueueBind(laneQueue, infrastructure.LaneRoutingKey(routingKey, priority), exchangeName, true, nil)
+		if err != nil {
+			return fmt.Errorf("could not bind AMQP queue %s with the exchange, %w", laneQueue, err)
+		}
+	}
+
+	for tier, delay := range retryDelays {
+		err = initializeAMQPRetryTier(ch, exchangeName, queueName, tier, delay)
+		if err != nil {
+			return err
+		}
 	}
 
-	err = ch.QueueBind(queueName, routingKey, exchangeName, true, nil)
+	return nil
+}
+
+// initializeAMQPDeadLetterExchange declares the exchange with the queue where messages that
+// will not be retried anymore are parked
+func initializeAMQPDeadLetterExchange(ch *amqp.Channel, exchangeName string, queueName string) error {
+	deadLetterExchange := infrastructure.DeadLetterExchangeName(exchangeName)
+	err := ch.ExchangeDeclare(deadLetterExchange, "fanout", true, false, false, false, nil)
+	if err != nil {
+		return fmt.Errorf("could not declare AMQP dead letter exchange, %w", err)
+	}
+
+	parkingQueue := infrastructure.ParkingQueueName(queueName)
+	_, err = ch.QueueDeclare(parkingQueue, true, false, false, false, nil)
+	if err != nil {
+		return fmt.Errorf("could not declare AMQP parking queue, %w", err)
+	}
+
+	err = ch.QueueBind(parkingQueue, "", deadLetterExchange, false, nil)
+	if err != nil {
+		return fmt.Errorf("could not bind AMQP parking queue with the dead letter exchange, %w", err)
+	}
+
+	return nil
+}
+
+// initializeAMQPRetryTier declares the queue which holds messages for given delay and then dead letters them
+// back to the main exchange with their original routing key
+func initializeAMQPRetryTier(
+	ch *amqp.Channel,
+	exchangeName string,
+	queueName string,
+	tier int,
+	delay time.Duration,
+) error {
+	retryExchange := infrastructure.RetryExchangeName(exchangeName, tier)
+	err := ch.ExchangeDeclare(retryExchange, "fanout", true, false, false, false, nil)
+	if err != nil {
+		return fmt.Errorf("could not declare AMQP retry exchange %s, %w", retryExchange, err)
+	}
+
+	retryQueue := infrastructure.RetryQueueName(queueName, tier)
+	_, err = ch.QueueDeclare(
+		retryQueue, true, false, false, false, amqp.Table{
+			"x-message-ttl":          delay.Milliseconds(),
+			"x-dead-letter-exchange": exchangeName,
+		},
+	)
 	if err != nil {
-		return fmt.Errorf("could not bind AMQP queue with the exchange")
+		return fmt.Errorf("could not declare AMQP retry queue %s, %w", retryQueue, err)
+	}
+
+	err = ch.QueueBind(retryQueue, "", retryExchange, false, nil)
+	if err != nil {
+		return fmt.Errorf("could not bind AMQP retry queue %s with the exchange, %w", retryQueue, err)
 	}
 
 	return nil
@@ -79,3 +204,95 @@ func GetAMQPConn(ctx context.Context, url string, notifyStart func(), notifyDone
 
 	return conn, nil
 }
+
+func GetMongoDB(
+	ctx context.Context,
+	dsn string,
+	databaseName string,
+	notifyStart func(),
+	notifyDone func(),
+) (*mongo.Database, error) {
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(dsn))
+	if err != nil {
+		return nil, err
+	}
+
+	notifyStart()
+	go func() {
+		defer notifyDone()
+		<-ctx.Done()
+		err := client.Disconnect(ctx)
+		if err != nil {
+			log.Printf("failed to disconnect from db: %v\n", err)
+		} else {
+			log.Println("MongoDB connection closed")
+		}
+	}()
+
+	return client.Database(databaseName), nil
+}
+
+// InitTracing installs the tracer provider of the service, remaining spans are flushed when the context is done
+func InitTracing(
+	ctx context.Context,
+	cfg config.Tracing,
+	serviceName string,
+	notifyStart func(),
+	notifyDone func(),
+) error {
+	shutdown, err := tracing.Init(ctx, serviceName, cfg.Exporter, cfg.OTLPEndpoint, cfg.SampleRatio)
+	if err != nil {
+		return err
+	}
+
+	notifyStart()
+	go func() {
+		defer notifyDone()
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		err := shutdown(shutdownCtx)
+		if err != nil {
+			log.Printf("failed to flush spans: %v\n", err)
+		}
+	}()
+
+	return nil
+}
+
+// ServeMetrics exposes metrics on /metrics endpoint until the context is done
+func ServeMetrics(ctx context.Context, port int, notifyStart func(), notifyDone func()) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	server := &http.Server{Addr: fmt.Sprintf(":%d", port), Handler: mux}
+
+	notifyStart()
+	go func() {
+		defer notifyDone()
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+		log.Println("Metrics server stopped")
+	}()
+
+	go func() {
+		log.Printf("starting metrics server on port %d\n", port)
+		err := server.ListenAndServe()
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("metrics server failed: %v\n", err)
+		}
+	}()
+}
+
+// GetLeaseHolder returns the name the process holds the leases under, unique for every replica
+func GetLeaseHolder() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
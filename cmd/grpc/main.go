@@ -3,11 +3,15 @@ package main
 import (
 	"context"
 	"fmt"
+	grpcprometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
 	"go-web-crawler-service/application"
 	"go-web-crawler-service/cmd"
 	"go-web-crawler-service/config"
+	"go-web-crawler-service/domain"
 	"go-web-crawler-service/infrastructure"
 	grpcwebcrawler "go-web-crawler-service/protobuf/webcrawler"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
 	"log"
 	"net"
@@ -16,6 +20,12 @@ import (
 	"sync"
 )
 
+const (
+	dedupStoreNone   = "none"
+	dedupStoreMemory = "memory"
+	dedupStoreMongo  = "mongo"
+)
+
 func main() {
 	cfg, err := config.ParseConfig()
 	if err != nil {
@@ -34,6 +44,11 @@ func main() {
 		wg.Done()
 	}
 
+	err = cmd.InitTracing(ctx, cfg.Tracing, "web-crawler-api", notifyStart, notifyDone)
+	if err != nil {
+		log.Fatalf("failed to initialize tracing: %v", err)
+	}
+
 	conn, err := cmd.GetAMQPConn(ctx, cfg.AMQP.URL, notifyStart, notifyDone)
 	if err != nil {
 		log.Fatalf("failed to open RabbitMQ connection: %v", err)
@@ -44,7 +59,13 @@ func main() {
 		log.Fatalf("failed to open RabbitMQ channel: %v", err)
 	}
 
-	err = cmd.InitializeAMQPExchange(ch, cfg.AMQP.ExchangeName, cfg.AMQP.QueueName, cfg.AMQP.RoutingKey)
+	err = cmd.InitializeAMQPExchange(
+		ch,
+		cfg.AMQP.ExchangeName,
+		cfg.AMQP.QueueName,
+		cfg.AMQP.RoutingKey,
+		cfg.AMQP.RetryDelays,
+	)
 	if err != nil {
 		log.Fatalf("failed to initialize queues and exchanges: %v", err)
 	}
@@ -54,14 +75,63 @@ func main() {
 		log.Fatalf("failed to listen on %d", cfg.GRPC.ServerPort)
 	}
 
-	publisher := infrastructure.NewAmqpPublisher(ch, cfg.AMQP.ExchangeName, cfg.AMQP.RoutingKey)
+	db, err := cmd.GetMongoDB(ctx, cfg.Database.DSN, cfg.Database.DatabaseName, notifyStart, notifyDone)
+	if err != nil {
+		log.Fatalf("failed to create mongo connection: %v", err)
+	}
+
+	messageFormat, err := infrastructure.NewMessageFormat(cfg.AMQP.MessageFormat)
+	if err != nil {
+		log.Fatalf("invalid AMQP message format: %v", err)
+	}
+
+	publisher, err := infrastructure.NewAmqpPublisher(
+		ch,
+		cfg.AMQP.ExchangeName,
+		cfg.AMQP.RoutingKey,
+		messageFormat,
+		"api",
+	)
+	if err != nil {
+		log.Fatalf("failed to create AMQP publisher: %v", err)
+	}
 
-	grpcServer := grpc.NewServer()
+	channels := infrastructure.NewMongoChannelRepository(db)
+	jobs := infrastructure.NewMongoJobRepository(db, cfg.Dedup.Window)
+	err = jobs.EnsureIndexes(ctx)
+	if err != nil {
+		log.Fatalf("failed to create database indexes: %v", err)
+	}
+
+	storeRoutes, err := cmd.GetStoreRoutes(cfg.Stores)
+	if err != nil {
+		log.Fatalf("failed to configure stores: %v", err)
+	}
+
+	dedup, err := getCrawlDeduplicator(cfg.Dedup, db)
+	if err != nil {
+		log.Fatalf("failed to configure deduplication: %v", err)
+	}
+
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(otelgrpc.UnaryServerInterceptor(), grpcprometheus.UnaryServerInterceptor),
+		grpc.ChainStreamInterceptor(otelgrpc.StreamServerInterceptor(), grpcprometheus.StreamServerInterceptor),
+	)
 	grpcwebcrawler.RegisterWebCrawlerServiceServer(
 		grpcServer,
-		application.NewServer(publisher),
+		application.NewServer(
+			domain.NewTrackingCrawlerScheduler(publisher, jobs),
+			domain.NewHostStoreResolver(storeRoutes),
+			channels,
+			jobs,
+			dedup,
+		),
 	)
 
+	grpcprometheus.EnableHandlingTimeHistogram()
+	grpcprometheus.Register(grpcServer)
+	cmd.ServeMetrics(ctx, cfg.Metrics.Port, notifyStart, notifyDone)
+
 	notifyStart()
 	go func() {
 		defer notifyDone()
@@ -79,3 +149,21 @@ func main() {
 
 	wg.Wait()
 }
+
+// getCrawlDeduplicator creates deduplicator of the submitted urls, nil deduplicator disables it
+func getCrawlDeduplicator(cfg config.Dedup, db *mongo.Database) (domain.CrawlDeduplicator, error) {
+	if cfg.Window <= 0 {
+		return nil, nil
+	}
+
+	switch cfg.Store {
+	case dedupStoreNone:
+		return nil, nil
+	case dedupStoreMemory:
+		return infrastructure.NewMemoryCrawlDeduplicator(cfg.Window), nil
+	case dedupStoreMongo:
+		return infrastructure.NewMongoCrawlDeduplicator(db, cfg.Window), nil
+	default:
+		return nil, fmt.Errorf("unknown dedup store: %s", cfg.Store)
+	}
+}
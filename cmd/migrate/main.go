@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"go-web-crawler-service/cmd"
+	"go-web-crawler-service/config"
+	"go-web-crawler-service/infrastructure"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+)
+
+const (
+	migrationLease = "migration"
+	// migrationLeaseTTL is the time the other instances are kept from migrating, it has to cover the whole migration
+	migrationLeaseTTL = 30 * time.Minute
+)
+
+// Migrates the channels stored by the previous versions and creates the indexes. It's run once before the workers
+// of the new version are started, every step skips the data that is already migrated, so it could be run again.
+func main() {
+	cfg, err := config.ParseConfig()
+	if err != nil {
+		log.Fatalf("got error when parsing config %v", err)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
+	defer cancel()
+
+	wg := &sync.WaitGroup{}
+	notifyStart := func() {
+		wg.Add(1)
+	}
+
+	notifyDone := func() {
+		wg.Done()
+	}
+
+	db, err := cmd.GetMongoDB(ctx, cfg.Database.DSN, cfg.Database.DatabaseName, notifyStart, notifyDone)
+	if err != nil {
+		log.Fatalf("failed to create mongo connection: %v", err)
+	}
+
+	acquired, err := infrastructure.NewMongoLeaseRepository(db).
+		Acquire(ctx, migrationLease, cmd.GetLeaseHolder(), migrationLeaseTTL)
+	if err != nil {
+		log.Fatalf("failed to acquire migration lease: %v", err)
+	}
+	if !acquired {
+		log.Fatal("migration is already run by another instance")
+	}
+
+	repo := infrastructure.NewMongoChannelRepository(db)
+	err = repo.AssignStore(ctx, cmd.RokuStore)
+	if err != nil {
+		log.Fatalf("failed to assign store to channels: %v", err)
+	}
+
+	// Ids are assigned before the unique index is created, as the channels saved by their name could be duplicated
+	err = repo.AssignStoreChannelIDs(ctx)
+	if err != nil {
+		log.Fatalf("failed to assign store channel ids to channels: %v", err)
+	}
+
+	// Countries are assigned before the unique index per country replaces the index per store channel id
+	err = repo.AssignCountry(ctx)
+	if err != nil {
+		log.Fatalf("failed to assign country to channels: %v", err)
+	}
+
+	err = repo.EnsureIndexes(ctx)
+	if err != nil {
+		log.Fatalf("failed to create database indexes: %v", err)
+	}
+
+	log.Println("Migration finished")
+
+	cancel()
+	wg.Wait()
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"go-web-crawler-service/application"
+	"go-web-crawler-service/cmd"
+	"go-web-crawler-service/config"
+	"go-web-crawler-service/domain"
+	"go-web-crawler-service/infrastructure"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+)
+
+func main() {
+	cfg, err := config.ParseConfig()
+	if err != nil {
+		log.Fatalf("got error when parsing config %v", err)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
+	defer cancel()
+
+	wg := &sync.WaitGroup{}
+	notifyStart := func() {
+		wg.Add(1)
+	}
+
+	notifyDone := func() {
+		wg.Done()
+	}
+
+	tiers, err := getFreshnessTiers(cfg.Scheduler)
+	if err != nil {
+		log.Fatalf("invalid freshness tiers: %v", err)
+	}
+
+	err = cmd.InitTracing(ctx, cfg.Tracing, "web-crawler-scheduler", notifyStart, notifyDone)
+	if err != nil {
+		log.Fatalf("failed to initialize tracing: %v", err)
+	}
+
+	conn, err := cmd.GetAMQPConn(ctx, cfg.AMQP.URL, notifyStart, notifyDone)
+	if err != nil {
+		log.Fatalf("failed to open RabbitMQ connection: %v", err)
+	}
+
+	ch, err := cmd.GetAMQPChannel(ctx, conn, cancel, notifyStart, notifyDone)
+	if err != nil {
+		log.Fatalf("failed to open RabbitMQ channel: %v", err)
+	}
+
+	err = cmd.InitializeAMQPExchange(
+		ch,
+		cfg.AMQP.ExchangeName,
+		cfg.AMQP.QueueName,
+		cfg.AMQP.RoutingKey,
+		cfg.AMQP.RetryDelays,
+	)
+	if err != nil {
+		log.Fatalf("failed to initialize queues and exchanges: %v", err)
+	}
+
+	db, err := cmd.GetMongoDB(ctx, cfg.Database.DSN, cfg.Database.DatabaseName, notifyStart, notifyDone)
+	if err != nil {
+		log.Fatalf("failed to create mongo connection: %v", err)
+	}
+
+	messageFormat, err := infrastructure.NewMessageFormat(cfg.AMQP.MessageFormat)
+	if err != nil {
+		log.Fatalf("invalid AMQP message format: %v", err)
+	}
+
+	publisher, err := infrastructure.NewAmqpPublisher(
+		ch,
+		cfg.AMQP.ExchangeName,
+		cfg.AMQP.RoutingKey,
+		messageFormat,
+		"scheduler",
+	)
+	if err != nil {
+		log.Fatalf("failed to create AMQP publisher: %v", err)
+	}
+
+	channels := infrastructure.NewMongoChannelRepository(db)
+	jobs := infrastructure.NewMongoJobRepository(db, cfg.Dedup.Window)
+	leases := infrastructure.NewMongoLeaseRepository(db)
+
+	recrawler := domain.NewChannelRecrawler(
+		channels,
+		domain.NewTrackingCrawlerScheduler(publisher, jobs),
+		tiers,
+		cfg.Scheduler.BatchSize,
+	)
+
+	app := application.NewSchedulerApplication(
+		recrawler,
+		leases,
+		cmd.GetLeaseHolder(),
+		cfg.Scheduler.Interval,
+		cfg.Scheduler.LeaseTTL,
+	)
+	cmd.ServeMetrics(ctx, cfg.Metrics.Port, notifyStart, notifyDone)
+
+	app.Run(ctx, notifyStart, notifyDone)
+
+	wg.Wait()
+}
+
+func getFreshnessTiers(cfg config.Scheduler) ([]domain.FreshnessTier, error) {
+	tiers := make([]domain.FreshnessTier, 0, len(cfg.FreshnessTiers))
+	for minNumberOfRatings, maxAge := range cfg.FreshnessTiers {
+		tier, err := domain.NewFreshnessTier(minNumberOfRatings, maxAge)
+		if err != nil {
+			return nil, err
+		}
+
+		tiers = append(tiers, *tier)
+	}
+
+	return tiers, nil
+}
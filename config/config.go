@@ -2,13 +2,21 @@ package config
 
 import (
 	"github.com/kelseyhightower/envconfig"
+	"time"
 )
 
 type Config struct {
-	AMQP     AMQP     `required:"true"`
-	Database Database `required:"true"`
-	GRPC     GRPC     `required:"true"`
-	Crawler  Crawler  `required:"true"`
+	AMQP      AMQP      `required:"true"`
+	Database  Database  `required:"true"`
+	GRPC      GRPC      `required:"true"`
+	Crawler   Crawler   `required:"true"`
+	Browser   Browser   `required:"true"`
+	Artifacts Artifacts `required:"true"`
+	Scheduler Scheduler `required:"true"`
+	Stores    Stores    `required:"true"`
+	Metrics   Metrics   `required:"true"`
+	Tracing   Tracing   `required:"true"`
+	Dedup     Dedup     `required:"true"`
 }
 
 type AMQP struct {
@@ -16,6 +24,16 @@ type AMQP struct {
 	QueueName    string `required:"true" envconfig:"AMQP_QUEUE_NAME" default:"channel_crawler"`
 	ExchangeName string `required:"true" envconfig:"AMQP_EXCHANGE_NAME" default:"urls"`
 	RoutingKey   string `required:"true" envconfig:"AMQP_ROUTING_KEY" default:"channel_url"`
+	// RetryDelays are delays of the following retry tiers, the last one is used for all the remaining attempts
+	RetryDelays []time.Duration `required:"true" envconfig:"AMQP_RETRY_DELAYS" default:"10s,1m,5m"`
+	MaxAttempts int             `required:"true" envconfig:"AMQP_MAX_ATTEMPTS" default:"4"`
+	// MessageFormat is one of: json (versioned envelope), text (plain url read by the workers before the envelope)
+	MessageFormat string `required:"true" envconfig:"AMQP_MESSAGE_FORMAT" default:"json"`
+	// LaneWeights are shares of the turns of the priority lanes, lane without weight is consumed only when
+	// the other lanes are empty
+	LaneWeights map[string]int `required:"true" envconfig:"AMQP_LANE_WEIGHTS" default:"high:6,normal:3,low:1"`
+	// QueueDepthInterval is how often the depth of the lanes is exported, 0 disables it
+	QueueDepthInterval time.Duration `envconfig:"AMQP_QUEUE_DEPTH_INTERVAL" default:"15s"`
 }
 
 type Database struct {
@@ -29,6 +47,82 @@ type GRPC struct {
 
 type Crawler struct {
 	WorkersAmount int `required:"true" envconfig:"CRAWLER_WORKERS_AMOUNT" default:"5"`
+	// Engine is one of: rod, http, fallback (http first, rod when http failed)
+	Engine string `required:"true" envconfig:"CRAWLER_ENGINE" default:"rod"`
+	// ProfilePath points to the extraction profile file, the built-in Roku profile is used when empty
+	ProfilePath string `envconfig:"CRAWLER_PROFILE_PATH"`
+	// RateLimitStore is one of: none, memory (per worker), mongo (shared by all the workers)
+	RateLimitStore string `required:"true" envconfig:"CRAWLER_RATE_LIMIT_STORE" default:"mongo"`
+	// RateLimitInterval is the time between crawls of the same host while the host is not overloaded
+	RateLimitInterval time.Duration `required:"true" envconfig:"CRAWLER_RATE_LIMIT_INTERVAL" default:"200ms"`
+	// RateLimitMaxInterval is the time between crawls of the same host the interval backs off up to
+	RateLimitMaxInterval time.Duration `required:"true" envconfig:"CRAWLER_RATE_LIMIT_MAX_INTERVAL" default:"30s"`
+	// RateLimitJitter is the max random delay added to every crawl
+	RateLimitJitter time.Duration `envconfig:"CRAWLER_RATE_LIMIT_JITTER" default:"100ms"`
+}
+
+type Browser struct {
+	Bin            string `required:"true" envconfig:"BROWSER_BIN" default:"/usr/bin/chromium-browser"`
+	PoolSize       int    `required:"true" envconfig:"BROWSER_POOL_SIZE" default:"1"`
+	TabsPerBrowser int    `required:"true" envconfig:"BROWSER_TABS" default:"5"`
+	// MaxPagesPerBrowser is an amount of pages after which the browser is relaunched, 0 disables recycling
+	MaxPagesPerBrowser  int           `required:"true" envconfig:"BROWSER_MAX_PAGES" default:"200"`
+	HealthCheckInterval time.Duration `required:"true" envconfig:"BROWSER_HEALTH_CHECK_INTERVAL" default:"30s"`
+	// IdentityPath points to the file of user agents, languages and viewports, the built-in ones are used when empty
+	IdentityPath string `envconfig:"BROWSER_IDENTITY_PATH"`
+	// Proxies are urls of HTTP or SOCKS5 proxies rotated between the pages, pages are crawled directly when empty
+	Proxies []string `envconfig:"BROWSER_PROXIES"`
+	// ProxyMaxFailures is an amount of failed crawls in a row after which the proxy is taken out of the rotation
+	ProxyMaxFailures int `required:"true" envconfig:"BROWSER_PROXY_MAX_FAILURES" default:"3"`
+	// ProxyCooldown is the time the unhealthy proxy is out of the rotation for
+	ProxyCooldown time.Duration `required:"true" envconfig:"BROWSER_PROXY_COOLDOWN" default:"5m"`
+}
+
+type Artifacts struct {
+	// Store is one of: none, file, s3
+	Store string `required:"true" envconfig:"ARTIFACT_STORE" default:"none"`
+	// Dir is the directory of the file store
+	Dir         string `envconfig:"ARTIFACT_DIR" default:"/tmp/crawler-artifacts"`
+	S3Endpoint  string `envconfig:"ARTIFACT_S3_ENDPOINT"`
+	S3Region    string `envconfig:"ARTIFACT_S3_REGION" default:"us-east-1"`
+	S3Bucket    string `envconfig:"ARTIFACT_S3_BUCKET"`
+	S3AccessKey string `envconfig:"ARTIFACT_S3_ACCESS_KEY"`
+	S3SecretKey string `envconfig:"ARTIFACT_S3_SECRET_KEY"`
+	S3UseSSL    bool   `envconfig:"ARTIFACT_S3_USE_SSL" default:"true"`
+}
+
+type Scheduler struct {
+	Interval time.Duration `required:"true" envconfig:"SCHEDULER_INTERVAL" default:"1m"`
+	// FreshnessTiers maps minimal amount of ratings to the age after which channel data are recrawled
+	FreshnessTiers map[uint32]time.Duration `required:"true" envconfig:"SCHEDULER_FRESHNESS_TIERS" default:"0:24h,1000:6h,100000:1h"`
+	BatchSize      int                      `required:"true" envconfig:"SCHEDULER_BATCH_SIZE" default:"500"`
+	LeaseTTL       time.Duration            `required:"true" envconfig:"SCHEDULER_LEASE_TTL" default:"5m"`
+}
+
+type Metrics struct {
+	Port int `required:"true" envconfig:"METRICS_PORT" default:"9100"`
+}
+
+type Tracing struct {
+	// Exporter is one of: otlp, noop (spans are dropped)
+	Exporter     string `required:"true" envconfig:"TRACING_EXPORTER" default:"noop"`
+	OTLPEndpoint string `envconfig:"TRACING_OTLP_ENDPOINT" default:"localhost:4317"`
+	// SampleRatio is a share of the traces started by the service that are sampled, 0-1
+	SampleRatio float64 `required:"true" envconfig:"TRACING_SAMPLE_RATIO" default:"1"`
+}
+
+type Stores struct {
+	// RokuHosts are host patterns of the Roku channel store, crawled with the extraction profile
+	RokuHosts []string `required:"true" envconfig:"STORE_ROKU_HOSTS" default:"roku.com,*.roku.com"`
+	// JSONLDHosts maps host patterns to the stores crawled with the generic JSON-LD crawler
+	JSONLDHosts map[string]string `envconfig:"STORE_JSONLD_HOSTS" default:"apps.apple.com:apple"`
+}
+
+type Dedup struct {
+	// Store is one of: none, memory (per API instance), mongo (reads the jobs)
+	Store string `required:"true" envconfig:"DEDUP_STORE" default:"mongo"`
+	// Window is the time the scheduled url is not scheduled again for
+	Window time.Duration `required:"true" envconfig:"DEDUP_WINDOW" default:"1h"`
 }
 
 func ParseConfig() (*Config, error) {
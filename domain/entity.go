@@ -1,12 +1,167 @@
 package domain
 
+import "time"
+
 type Channel struct {
 	ApplicationName ApplicationName
 	Url             Url
 	Rating          Rating
 	NumberOfRatings RatingsAmount
+	// Store is the app store the channel was crawled from
+	Store Store
+	// Locale is the locale the page was crawled in, the channel is stored for every country separately
+	Locale Locale
+	// StoreChannelID identifies the channel in the store, channels are told apart by it instead of the name
+	StoreChannelID StoreChannelID
+	// ProfileVersion is a version of the extraction profile the channel data were crawled with
+	ProfileVersion string
+	// Proxy is the proxy the page was crawled through without its credentials, it's empty for direct crawls
+	Proxy string
+	// Details below are optional, they are empty when the store page does not show them
+	Developer     Developer
+	Category      Category
+	Price         Price
+	Description   Description
+	ContentRating ContentRating
+	// StarDistribution is nil when the store page does not show it
+	StarDistribution *StarDistribution
 }
 
 func NewChannel(name ApplicationName, url Url, rating Rating, numberOfRating RatingsAmount) *Channel {
 	return &Channel{ApplicationName: name, Url: url, Rating: rating, NumberOfRatings: numberOfRating}
 }
+
+// ChannelNameChange records rename of the channel found by the crawl
+type ChannelNameChange struct {
+	From      ApplicationName
+	To        ApplicationName
+	ChangedAt time.Time
+}
+
+// CrawlRequest is a single url scheduled to be crawled in the locale
+type CrawlRequest struct {
+	JobID    JobID
+	Url      Url
+	Locale   Locale
+	Priority Priority
+}
+
+func NewCrawlRequest(jobID JobID, url Url, locale Locale, priority Priority) *CrawlRequest {
+	return &CrawlRequest{JobID: jobID, Url: url, Locale: locale, Priority: priority}
+}
+
+// CrawlTarget is the channel page in the locale it's crawled in
+type CrawlTarget struct {
+	Url    Url
+	Locale Locale
+}
+
+// Job tracks the state of a single crawl request
+type Job struct {
+	ID       JobID
+	Url      Url
+	Locale   Locale
+	Priority Priority
+	Status   JobStatus
+	Error    string
+	// ArtifactID identifies the page captured when the last crawl attempt failed
+	ArtifactID ArtifactID
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+func NewJob(request CrawlRequest, createdAt time.Time) *Job {
+	return &Job{
+		ID:        request.JobID,
+		Url:       request.Url,
+		Locale:    request.Locale,
+		Priority:  request.Priority,
+		Status:    JobStatusQueued,
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt,
+	}
+}
+
+// FailureArtifact is the state of the page captured when the channel could not be extracted from it
+type FailureArtifact struct {
+	ID         ArtifactID
+	Url        Url
+	Reason     string
+	Screenshot []byte
+	DOM        string
+	ConsoleLog []string
+	// Proxy is the proxy the page was crawled through, it's empty for direct crawls
+	Proxy      string
+	CapturedAt time.Time
+}
+
+// ChannelSnapshot is an immutable record of the channel rating at the moment it was crawled
+type ChannelSnapshot struct {
+	ApplicationName ApplicationName
+	Url             Url
+	Rating          Rating
+	NumberOfRatings RatingsAmount
+	Store           Store
+	StoreChannelID  StoreChannelID
+	Locale          Locale
+	ProfileVersion  string
+	Proxy           string
+	// StarDistribution is nil when the store page does not show it
+	StarDistribution *StarDistribution
+	CrawledAt        time.Time
+}
+
+func NewChannelSnapshot(channel Channel, crawledAt time.Time) *ChannelSnapshot {
+	return &ChannelSnapshot{
+		ApplicationName:  channel.ApplicationName,
+		Url:              channel.Url,
+		Rating:           channel.Rating,
+		NumberOfRatings:  channel.NumberOfRatings,
+		Store:            channel.Store,
+		StoreChannelID:   channel.StoreChannelID,
+		Locale:           channel.Locale,
+		ProfileVersion:   channel.ProfileVersion,
+		Proxy:            channel.Proxy,
+		StarDistribution: channel.StarDistribution,
+		CrawledAt:        crawledAt,
+	}
+}
+
+// ChannelView is a read model of the channel stored after the crawl
+type ChannelView struct {
+	ID        string
+	Channel   Channel
+	Status    ChannelStatus
+	UpdatedAt time.Time
+	// DelistedAt is the time the channel was found delisted, it's nil for active channels
+	DelistedAt *time.Time
+	// NameChanges are renames of the channel from the oldest one
+	NameChanges []ChannelNameChange
+}
+
+type ChannelFilter struct {
+	MinRating          *Rating
+	MinNumberOfRatings *RatingsAmount
+	NamePrefix         string
+	UpdatedSince       *time.Time
+	// Status selects channels of the status, channels of any status are listed when it's empty
+	Status ChannelStatus
+	// Country selects channels crawled in the country, channels of all the countries are listed when it's nil
+	Country *Country
+	Cursor  string
+	Limit   int
+}
+
+// StaleChannelCriteria selects active channels with ratings amount in [MinNumberOfRatings, MaxNumberOfRatings) range
+// which were neither updated nor scheduled since StaleBefore, nil MaxNumberOfRatings means no upper bound
+type StaleChannelCriteria struct {
+	MinNumberOfRatings RatingsAmount
+	MaxNumberOfRatings *RatingsAmount
+	StaleBefore        time.Time
+	Limit              int
+}
+
+type ChannelPage struct {
+	Channels   []ChannelView
+	NextCursor string
+}
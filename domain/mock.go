@@ -6,6 +6,8 @@ import (
 	context "context"
 
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // channelRepositoryMock is an autogenerated mock type for the ChannelRepository type
@@ -27,19 +29,32 @@ func (_m *channelRepositoryMock) Save(ctx context.Context, channel Channel) erro
 	return r0
 }
 
+// MarkDelisted provides a mock function with given fields: ctx, store, url, locale, delistedAt
+func (_m *channelRepositoryMock) MarkDelisted(ctx context.Context, store Store, url Url, locale Locale, delistedAt time.Time) error {
+	ret := _m.Called(ctx, store, url, locale, delistedAt)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, Store, Url, Locale, time.Time) error); ok {
+		r0 = rf(ctx, store, url, locale, delistedAt)
+	} else {
+		r0 = ret.Error(0)
+	}
 
-// rokuWebCrawlerMock is an autogenerated mock type for the RokuWebCrawler type
-type rokuWebCrawlerMock struct {
+	return r0
+}
+
+// channelWebCrawlerMock is an autogenerated mock type for the ChannelWebCrawler type
+type channelWebCrawlerMock struct {
 	mock.Mock
 }
 
-// CrawlChannel provides a mock function with given fields: ctx, url
-func (_m *rokuWebCrawlerMock) CrawlChannel(ctx context.Context, url Url) (*Channel, error) {
-	ret := _m.Called(ctx, url)
+// CrawlChannel provides a mock function with given fields: ctx, url, locale
+func (_m *channelWebCrawlerMock) CrawlChannel(ctx context.Context, url Url, locale Locale) (*Channel, error) {
+	ret := _m.Called(ctx, url, locale)
 
 	var r0 *Channel
-	if rf, ok := ret.Get(0).(func(context.Context, Url) *Channel); ok {
-		r0 = rf(ctx, url)
+	if rf, ok := ret.Get(0).(func(context.Context, Url, Locale) *Channel); ok {
+		r0 = rf(ctx, url, locale)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*Channel)
@@ -47,11 +62,183 @@ func (_m *rokuWebCrawlerMock) CrawlChannel(ctx context.Context, url Url) (*Chann
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, Url) error); ok {
-		r1 = rf(ctx, url)
+	if rf, ok := ret.Get(1).(func(context.Context, Url, Locale) error); ok {
+		r1 = rf(ctx, url, locale)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// jobRepositoryMock is an autogenerated mock type for the JobRepository type
+type jobRepositoryMock struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: ctx, jobs
+func (_m *jobRepositoryMock) Create(ctx context.Context, jobs []Job) []error {
+	ret := _m.Called(ctx, jobs)
+
+	var r0 []error
+	if rf, ok := ret.Get(0).(func(context.Context, []Job) []error); ok {
+		r0 = rf(ctx, jobs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]error)
+		}
+	}
+
+	return r0
+}
+
+// FindByID provides a mock function with given fields: ctx, id
+func (_m *jobRepositoryMock) FindByID(ctx context.Context, id JobID) (*Job, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *Job
+	if rf, ok := ret.Get(0).(func(context.Context, JobID) *Job); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*Job)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, JobID) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindByIDs provides a mock function with given fields: ctx, ids
+func (_m *jobRepositoryMock) FindByIDs(ctx context.Context, ids []JobID) ([]Job, error) {
+	ret := _m.Called(ctx, ids)
+
+	var r0 []Job
+	if rf, ok := ret.Get(0).(func(context.Context, []JobID) []Job); ok {
+		r0 = rf(ctx, ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]Job)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, []JobID) error); ok {
+		r1 = rf(ctx, ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AttachArtifact provides a mock function with given fields: ctx, id, artifactID
+func (_m *jobRepositoryMock) AttachArtifact(ctx context.Context, id JobID, artifactID ArtifactID) error {
+	ret := _m.Called(ctx, id, artifactID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, JobID, ArtifactID) error); ok {
+		r0 = rf(ctx, id, artifactID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateStatus provides a mock function with given fields: ctx, id, status, reason
+func (_m *jobRepositoryMock) UpdateStatus(ctx context.Context, id JobID, status JobStatus, reason string) error {
+	ret := _m.Called(ctx, id, status, reason)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, JobID, JobStatus, string) error); ok {
+		r0 = rf(ctx, id, status, reason)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// channelCrawlerSchedulerMock is an autogenerated mock type for the ChannelCrawlerScheduler type
+type channelCrawlerSchedulerMock struct {
+	mock.Mock
+}
+
+// Schedule provides a mock function with given fields: ctx, request
+func (_m *channelCrawlerSchedulerMock) Schedule(ctx context.Context, request CrawlRequest) error {
+	ret := _m.Called(ctx, request)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, CrawlRequest) error); ok {
+		r0 = rf(ctx, request)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ScheduleBatch provides a mock function with given fields: ctx, requests
+func (_m *channelCrawlerSchedulerMock) ScheduleBatch(ctx context.Context, requests []CrawlRequest) []error {
+	ret := _m.Called(ctx, requests)
+
+	var r0 []error
+	if rf, ok := ret.Get(0).(func(context.Context, []CrawlRequest) []error); ok {
+		r0 = rf(ctx, requests)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]error)
+		}
+	}
+
+	return r0
+}
+
+// staleChannelRepositoryMock is an autogenerated mock type for the StaleChannelRepository type
+type staleChannelRepositoryMock struct {
+	mock.Mock
+}
+
+// FindStale provides a mock function with given fields: ctx, criteria
+func (_m *staleChannelRepositoryMock) FindStale(ctx context.Context, criteria StaleChannelCriteria) ([]CrawlTarget, error) {
+	ret := _m.Called(ctx, criteria)
+
+	var r0 []CrawlTarget
+	if rf, ok := ret.Get(0).(func(context.Context, StaleChannelCriteria) []CrawlTarget); ok {
+		r0 = rf(ctx, criteria)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]CrawlTarget)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, StaleChannelCriteria) error); ok {
+		r1 = rf(ctx, criteria)
 	} else {
 		r1 = ret.Error(1)
 	}
 
 	return r0, r1
 }
+
+// MarkScheduled provides a mock function with given fields: ctx, targets, scheduledAt
+func (_m *staleChannelRepositoryMock) MarkScheduled(ctx context.Context, targets []CrawlTarget, scheduledAt time.Time) error {
+	ret := _m.Called(ctx, targets, scheduledAt)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []CrawlTarget, time.Time) error); ok {
+		r0 = rf(ctx, targets, scheduledAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
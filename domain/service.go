@@ -2,52 +2,555 @@ package domain
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"net/url"
+	"sort"
+	"time"
 )
 
+var (
+	ErrChannelNotFound = errors.New("channel not found")
+	ErrInvalidCursor   = errors.New("invalid cursor")
+	ErrJobNotFound     = errors.New("job not found")
+	// ErrElementNotFound means that the page was loaded but its markup does not contain the crawled data,
+	// crawling it again will not help
+	ErrElementNotFound = errors.New("element not found")
+	// ErrUnsupportedStore means that the url does not belong to any of the supported app stores
+	ErrUnsupportedStore = errors.New("unsupported store")
+)
+
+// CrawlErrorKind classifies crawl failures, so all the layers handle the same failure the same way
+type CrawlErrorKind string
+
+const (
+	// CrawlErrorUnknown is a failure that could not be classified, e.g. network error
+	CrawlErrorUnknown CrawlErrorKind = "unknown"
+	// CrawlErrorTimeout means that the page was not loaded or rendered in time
+	CrawlErrorTimeout CrawlErrorKind = "timeout"
+	// CrawlErrorNotFound means that the page does not exist, e.g. the channel was delisted
+	CrawlErrorNotFound CrawlErrorKind = "not_found"
+	// CrawlErrorBlocked means that the store refused to serve the page, e.g. it asked for captcha
+	CrawlErrorBlocked CrawlErrorKind = "blocked"
+	// CrawlErrorUnavailable means that the store failed to serve the page, e.g. it responded with 5xx status
+	CrawlErrorUnavailable CrawlErrorKind = "unavailable"
+	// CrawlErrorMarkupChanged means that the page was loaded but it does not contain the crawled data
+	CrawlErrorMarkupChanged CrawlErrorKind = "markup_changed"
+	// CrawlErrorInvalidData means that the crawled data or the crawl request itself are not valid
+	CrawlErrorInvalidData CrawlErrorKind = "invalid_data"
+	// CrawlErrorStorage means that the data could not be read from or written to the storage
+	CrawlErrorStorage CrawlErrorKind = "storage"
+)
+
+// IsThrottling tells whether the failure is a sign of the store being overloaded by the crawls,
+// so the store should be crawled slower
+func (k CrawlErrorKind) IsThrottling() bool {
+	switch k {
+	case CrawlErrorTimeout, CrawlErrorBlocked, CrawlErrorUnavailable:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsRetryable tells whether the failure could go away when the crawl is repeated later
+func (k CrawlErrorKind) IsRetryable() bool {
+	switch k {
+	case CrawlErrorNotFound, CrawlErrorMarkupChanged, CrawlErrorInvalidData:
+		return false
+	default:
+		return true
+	}
+}
+
+// CrawlError is a failure of the given kind
+type CrawlError struct {
+	Kind CrawlErrorKind
+	Err  error
+}
+
+func NewCrawlError(kind CrawlErrorKind, err error) *CrawlError {
+	return &CrawlError{Kind: kind, Err: err}
+}
+
+func (e *CrawlError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Kind, e.Err)
+}
+
+func (e *CrawlError) Unwrap() error {
+	return e.Err
+}
+
+// CrawlErrorKindOf returns kind of the first CrawlError in the chain, errors that are not wrapped
+// in CrawlError are classified by the sentinel errors they wrap
+func CrawlErrorKindOf(err error) CrawlErrorKind {
+	var crawlErr *CrawlError
+	switch {
+	case errors.As(err, &crawlErr):
+		return crawlErr.Kind
+	case errors.Is(err, context.DeadlineExceeded):
+		return CrawlErrorTimeout
+	case errors.Is(err, ErrElementNotFound):
+		return CrawlErrorMarkupChanged
+	case errors.Is(err, ErrUnsupportedStore):
+		return CrawlErrorInvalidData
+	default:
+		return CrawlErrorUnknown
+	}
+}
+
+// ArtifactError is a crawl failure which page was captured as the artifact
+type ArtifactError struct {
+	ArtifactID ArtifactID
+	Err        error
+}
+
+func NewArtifactError(artifactID ArtifactID, err error) *ArtifactError {
+	return &ArtifactError{ArtifactID: artifactID, Err: err}
+}
+
+func (e *ArtifactError) Error() string {
+	return fmt.Sprintf("%v (artifact: %s)", e.Err, e.ArtifactID)
+}
+
+func (e *ArtifactError) Unwrap() error {
+	return e.Err
+}
+
+// ArtifactIDOf returns id of the artifact captured for the failure, empty id when none was captured
+func ArtifactIDOf(err error) ArtifactID {
+	var artifactErr *ArtifactError
+	if errors.As(err, &artifactErr) {
+		return artifactErr.ArtifactID
+	}
+
+	return ""
+}
+
+// DuplicateJobError means that the url is already queued or crawled in the same locale with the same priority,
+// so the request is not scheduled again
+type DuplicateJobError struct {
+	JobID JobID
+}
+
+func NewDuplicateJobError(jobID JobID) *DuplicateJobError {
+	return &DuplicateJobError{JobID: jobID}
+}
+
+func (e *DuplicateJobError) Error() string {
+	return fmt.Sprintf("url is already scheduled with job %s", e.JobID)
+}
+
+// DuplicateJobIDOf returns id of the job the request duplicates, false when the error is not DuplicateJobError
+func DuplicateJobIDOf(err error) (JobID, bool) {
+	var duplicateErr *DuplicateJobError
+	if errors.As(err, &duplicateErr) {
+		return duplicateErr.JobID, true
+	}
+
+	return "", false
+}
+
 type ChannelCrawlerScheduler interface {
-	Schedule(ctx context.Context, url Url) error
+	Schedule(ctx context.Context, request CrawlRequest) error
+	// ScheduleBatch schedules all the requests at once, returned errors are in the same order as the requests,
+	// nil error means that the request was scheduled
+	ScheduleBatch(ctx context.Context, requests []CrawlRequest) []error
 }
 
 type ChannelCrawlerProcessor interface {
-	Crawl(ctx context.Context, url Url) error
+	Crawl(ctx context.Context, request CrawlRequest) error
 }
 
 type ChannelRepository interface {
+	// Save stores the crawled channel, delisted channel found again becomes active
 	Save(ctx context.Context, channel Channel) error
+	// MarkDelisted marks the channel of the store with the url as delisted in the country of the locale, channel
+	// never crawled in the country is stored as delisted too, so it's told apart from the unknown one
+	MarkDelisted(ctx context.Context, store Store, url Url, locale Locale, delistedAt time.Time) error
+}
+
+type ChannelHistoryRepository interface {
+	// FindSnapshots returns snapshots of the channel identified like by ChannelRepository.Save
+	FindSnapshots(
+		ctx context.Context,
+		store Store,
+		storeChannelID StoreChannelID,
+		country Country,
+		from time.Time,
+		to time.Time,
+	) ([]ChannelSnapshot, error)
+}
+
+type ChannelQueryRepository interface {
+	FindByID(ctx context.Context, id string) (*ChannelView, error)
+	// FindByUrl returns the channel with the url crawled in the country
+	FindByUrl(ctx context.Context, url Url, country Country) (*ChannelView, error)
+	List(ctx context.Context, filter ChannelFilter) (*ChannelPage, error)
+}
+
+type StaleChannelRepository interface {
+	// FindStale returns the pages of the stale channels in the locales they were crawled in
+	FindStale(ctx context.Context, criteria StaleChannelCriteria) ([]CrawlTarget, error)
+	MarkScheduled(ctx context.Context, targets []CrawlTarget, scheduledAt time.Time) error
+}
+
+// LeaseRepository grants the named lease to a single holder at a time, the lease expires after ttl unless
+// the holder acquires it again
+type LeaseRepository interface {
+	Acquire(ctx context.Context, name string, holder string, ttl time.Duration) (bool, error)
+}
+
+type JobRepository interface {
+	// Create stores the jobs and returns error of every job in the same order as the jobs, job of the url which
+	// is already queued or running in the same locale with the same priority is not stored, DuplicateJobError
+	// with the job it duplicates is returned for it
+	Create(ctx context.Context, jobs []Job) []error
+	UpdateStatus(ctx context.Context, id JobID, status JobStatus, reason string) error
+	AttachArtifact(ctx context.Context, id JobID, artifactID ArtifactID) error
+	FindByID(ctx context.Context, id JobID) (*Job, error)
+	FindByIDs(ctx context.Context, ids []JobID) ([]Job, error)
+}
+
+// CrawlDeduplicator finds urls scheduled within the dedup window, so the channel queued or crawled recently
+// is not crawled again. The same url scheduled in different locales is not a duplicate, neither is the url
+// requested with higher priority than the one it waits in the queue with, so it's scheduled again in the higher lane.
+type CrawlDeduplicator interface {
+	// FindScheduled returns jobs of the urls scheduled in the locale within the window which did not fail, jobs
+	// waiting in the queue with lower priority than the priority are left out
+	FindScheduled(ctx context.Context, locale Locale, priority Priority, urls []Url) (map[Url]JobID, error)
+	// Remember records the scheduled requests
+	Remember(ctx context.Context, requests []CrawlRequest) error
+}
+
+// HostRateLimiter spaces the crawls of the same host, so the store is not overloaded
+type HostRateLimiter interface {
+	// Wait blocks until the host could be crawled
+	Wait(ctx context.Context, host string) error
+	// Observe reports the outcome of the host crawl, so the limiter slows the crawls down when the host is
+	// overloaded and speeds them up once it recovers
+	Observe(ctx context.Context, host string, latency time.Duration, crawlErr error)
+}
+
+type ChannelWebCrawler interface {
+	// CrawlChannel crawls the channel page in the locale, empty locale crawls it in the store default
+	CrawlChannel(ctx context.Context, url Url, locale Locale) (*Channel, error)
+}
+
+// ArtifactStore keeps the artifacts of the failed crawls for later inspection
+type ArtifactStore interface {
+	Save(ctx context.Context, artifact FailureArtifact) error
 }
 
-type RokuWebCrawler interface {
-	CrawlChannel(ctx context.Context, url Url) (*Channel, error)
+// StoreResolver tells which app store the url belongs to, ErrUnsupportedStore is returned for unknown stores
+type StoreResolver interface {
+	ResolveStore(url Url) (Store, error)
 }
 
 type channelCrawlerProcessor struct {
-	webCrawler        RokuWebCrawler
+	webCrawler        ChannelWebCrawler
+	storeResolver     StoreResolver
 	channelRepository ChannelRepository
+	jobRepository     JobRepository
 }
 
-func NewChannelCrawlerProcessor(webCrawler RokuWebCrawler, repository ChannelRepository) *channelCrawlerProcessor {
+func NewChannelCrawlerProcessor(
+	webCrawler ChannelWebCrawler,
+	storeResolver StoreResolver,
+	repository ChannelRepository,
+	jobRepository JobRepository,
+) *channelCrawlerProcessor {
 	return &channelCrawlerProcessor{
 		webCrawler:        webCrawler,
+		storeResolver:     storeResolver,
 		channelRepository: repository,
+		jobRepository:     jobRepository,
 	}
 }
 
-func (p *channelCrawlerProcessor) Crawl(ctx context.Context, url Url) error {
-	log.Printf("Starting crawling url: %s\n", url)
-	channel, err := p.webCrawler.CrawlChannel(ctx, url)
+// Crawl marks the job as running and as done once the channel is saved, failures are left to the caller
+// as it decides whether the request is going to be retried
+func (p *channelCrawlerProcessor) Crawl(ctx context.Context, request CrawlRequest) error {
+	url := request.Url
+	log.Printf("Starting crawling url: %s, locale: %s\n", url, request.Locale)
+	UpdateJobStatus(ctx, p.jobRepository, request.JobID, JobStatusRunning, "")
+
+	channel, err := p.webCrawler.CrawlChannel(ctx, url, request.Locale)
 	if err != nil {
 		log.Printf("could not crawl channel %s, error: %v\n", url, err)
+		if artifactID := ArtifactIDOf(err); artifactID != "" {
+			attachJobArtifact(ctx, p.jobRepository, request.JobID, artifactID)
+		}
+		if CrawlErrorKindOf(err) == CrawlErrorNotFound {
+			return p.delist(ctx, request, err)
+		}
 		return fmt.Errorf("could not crawl channel %s, error: %w", url, err)
 	}
 
+	channel.Locale = request.Locale
+
 	err = p.channelRepository.Save(ctx, *channel)
 	if err != nil {
 		log.Printf("Could not save crawled channel data, url: %s, error: %v\n", url, err)
 		return fmt.Errorf("could not save crawled channel data, url: %s, error: %w", url, err)
 	}
 
+	UpdateJobStatus(ctx, p.jobRepository, request.JobID, JobStatusDone, "")
+
 	log.Printf("Crawled url: %s, received channel data: %+v\n", url, *channel)
 	return nil
 }
+
+// delist marks the channel which page is not found anymore as delisted, so the crawl is finished instead of failing
+// over and over
+func (p *channelCrawlerProcessor) delist(ctx context.Context, request CrawlRequest, crawlErr error) error {
+	store, err := p.storeResolver.ResolveStore(request.Url)
+	if err != nil {
+		return fmt.Errorf("could not crawl channel %s, error: %w", request.Url, crawlErr)
+	}
+
+	err = p.channelRepository.MarkDelisted(ctx, store, request.Url, request.Locale, time.Now())
+	if err != nil {
+		log.Printf("Could not mark channel as delisted, url: %s, error: %v\n", request.Url, err)
+		return fmt.Errorf("could not mark channel as delisted, url: %s, error: %w", request.Url, err)
+	}
+
+	UpdateJobStatus(ctx, p.jobRepository, request.JobID, JobStatusDone, "")
+
+	log.Printf("Channel with url: %s is delisted\n", request.Url)
+	return nil
+}
+
+type hostStoreResolver struct {
+	routes []StoreRoute
+}
+
+// NewHostStoreResolver creates resolver routing urls by their host, the first matching route wins
+func NewHostStoreResolver(routes []StoreRoute) *hostStoreResolver {
+	return &hostStoreResolver{
+		routes: routes,
+	}
+}
+
+func (r *hostStoreResolver) ResolveStore(crawlUrl Url) (Store, error) {
+	parsedUrl, err := url.Parse(string(crawlUrl))
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid url %s", ErrUnsupportedStore, crawlUrl)
+	}
+
+	for _, route := range r.routes {
+		if route.Matches(parsedUrl.Hostname()) {
+			return route.Store, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrUnsupportedStore, parsedUrl.Hostname())
+}
+
+type storeWebCrawlerRegistry struct {
+	resolver StoreResolver
+	crawlers map[Store]ChannelWebCrawler
+}
+
+// NewStoreWebCrawlerRegistry creates crawler that routes every url to the crawler of the store it belongs to
+func NewStoreWebCrawlerRegistry(
+	resolver StoreResolver,
+	crawlers map[Store]ChannelWebCrawler,
+) *storeWebCrawlerRegistry {
+	return &storeWebCrawlerRegistry{
+		resolver: resolver,
+		crawlers: crawlers,
+	}
+}
+
+func (r *storeWebCrawlerRegistry) CrawlChannel(ctx context.Context, url Url, locale Locale) (*Channel, error) {
+	store, err := r.resolver.ResolveStore(url)
+	if err != nil {
+		return nil, err
+	}
+
+	crawler, found := r.crawlers[store]
+	if !found {
+		return nil, fmt.Errorf("%w: no crawler registered for store %s", ErrUnsupportedStore, store)
+	}
+
+	channel, err := crawler.CrawlChannel(ctx, url, locale)
+	if err != nil {
+		return nil, err
+	}
+	channel.Store = store
+
+	return channel, nil
+}
+
+type trackingCrawlerScheduler struct {
+	scheduler     ChannelCrawlerScheduler
+	jobRepository JobRepository
+}
+
+// NewTrackingCrawlerScheduler creates scheduler that registers a queued job for every request before scheduling it,
+// requests which jobs were not registered, e.g. as they duplicate the jobs being processed, are not scheduled
+func NewTrackingCrawlerScheduler(scheduler ChannelCrawlerScheduler, jobRepository JobRepository) *trackingCrawlerScheduler {
+	return &trackingCrawlerScheduler{
+		scheduler:     scheduler,
+		jobRepository: jobRepository,
+	}
+}
+
+func (s *trackingCrawlerScheduler) Schedule(ctx context.Context, request CrawlRequest) error {
+	return s.ScheduleBatch(ctx, []CrawlRequest{request})[0]
+}
+
+func (s *trackingCrawlerScheduler) ScheduleBatch(ctx context.Context, requests []CrawlRequest) []error {
+	if len(requests) == 0 {
+		return []error{}
+	}
+
+	now := time.Now()
+	jobs := make([]Job, 0, len(requests))
+	for _, request := range requests {
+		jobs = append(jobs, *NewJob(request, now))
+	}
+
+	errs := make([]error, len(requests))
+	created := make([]CrawlRequest, 0, len(requests))
+	createdIndexes := make([]int, 0, len(requests))
+	for i, err := range s.jobRepository.Create(ctx, jobs) {
+		_, duplicate := DuplicateJobIDOf(err)
+		switch {
+		case duplicate:
+			errs[i] = err
+		case err != nil:
+			log.Printf("Could not create job %s, error: %v\n", requests[i].JobID, err)
+			errs[i] = fmt.Errorf("could not create job, error: %w", err)
+		default:
+			created = append(created, requests[i])
+			createdIndexes = append(createdIndexes, i)
+		}
+	}
+
+	if len(created) == 0 {
+		return errs
+	}
+
+	for i, err := range s.scheduler.ScheduleBatch(ctx, created) {
+		if err != nil {
+			UpdateJobStatus(ctx, s.jobRepository, created[i].JobID, JobStatusFailed, err.Error())
+		}
+		errs[createdIndexes[i]] = err
+	}
+
+	return errs
+}
+
+type channelRecrawler struct {
+	channelRepository StaleChannelRepository
+	scheduler         ChannelCrawlerScheduler
+	tiers             []FreshnessTier
+	batchSize         int
+}
+
+// NewChannelRecrawler creates service scheduling crawls of channels which data are older than their freshness tier
+func NewChannelRecrawler(
+	channelRepository StaleChannelRepository,
+	scheduler ChannelCrawlerScheduler,
+	tiers []FreshnessTier,
+	batchSize int,
+) *channelRecrawler {
+	sortedTiers := make([]FreshnessTier, len(tiers))
+	copy(sortedTiers, tiers)
+	sort.Slice(
+		sortedTiers, func(i, j int) bool {
+			return sortedTiers[i].MinNumberOfRatings < sortedTiers[j].MinNumberOfRatings
+		},
+	)
+
+	return &channelRecrawler{
+		channelRepository: channelRepository,
+		scheduler:         scheduler,
+		tiers:             sortedTiers,
+		batchSize:         batchSize,
+	}
+}
+
+// Recrawl schedules up to batch size stale channels of every tier and returns the amount of scheduled channels
+func (r *channelRecrawler) Recrawl(ctx context.Context, now time.Time) (int, error) {
+	scheduled := 0
+
+	for i, tier := range r.tiers {
+		criteria := StaleChannelCriteria{
+			MinNumberOfRatings: tier.MinNumberOfRatings,
+			StaleBefore:        now.Add(-tier.MaxAge),
+			Limit:              r.batchSize,
+		}
+		if i+1 < len(r.tiers) {
+			criteria.MaxNumberOfRatings = &r.tiers[i+1].MinNumberOfRatings
+		}
+
+		targets, err := r.channelRepository.FindStale(ctx, criteria)
+		if err != nil {
+			return scheduled, fmt.Errorf("could not find stale channels, error: %w", err)
+		}
+
+		if len(targets) == 0 {
+			continue
+		}
+
+		// Recrawls wait in the low priority lane, so they do not delay the urls submitted by the users
+		requests := make([]CrawlRequest, 0, len(targets))
+		for _, target := range targets {
+			requests = append(requests, *NewCrawlRequest(GenerateJobID(), target.Url, target.Locale, PriorityLow))
+		}
+
+		scheduledTargets := make([]CrawlTarget, 0, len(targets))
+		for j, err := range r.scheduler.ScheduleBatch(ctx, requests) {
+			// Channel which crawl is already queued is scheduled as well
+			_, duplicate := DuplicateJobIDOf(err)
+			if err != nil && !duplicate {
+				log.Printf("Could not schedule recrawl of url: %s, error: %v\n", requests[j].Url, err)
+				continue
+			}
+
+			scheduledTargets = append(scheduledTargets, targets[j])
+		}
+
+		if len(scheduledTargets) == 0 {
+			continue
+		}
+
+		err = r.channelRepository.MarkScheduled(ctx, scheduledTargets, now)
+		if err != nil {
+			return scheduled, fmt.Errorf("could not mark channels as scheduled, error: %w", err)
+		}
+
+		scheduled += len(scheduledTargets)
+	}
+
+	return scheduled, nil
+}
+
+// UpdateJobStatus records job state transition, requests without job are not tracked. Tracking is best effort,
+// so failure is only logged and never interrupts the crawl itself.
+func UpdateJobStatus(ctx context.Context, repository JobRepository, id JobID, status JobStatus, reason string) {
+	if id == "" {
+		return
+	}
+
+	err := repository.UpdateStatus(ctx, id, status, reason)
+	if err != nil {
+		log.Printf("Could not update job %s status to %s, error: %v\n", id, status, err)
+	}
+}
+
+// attachJobArtifact links the artifact of the failed crawl to the job, it's best effort as UpdateJobStatus
+func attachJobArtifact(ctx context.Context, repository JobRepository, id JobID, artifactID ArtifactID) {
+	if id == "" {
+		return
+	}
+
+	err := repository.AttachArtifact(ctx, id, artifactID)
+	if err != nil {
+		log.Printf("Could not attach artifact %s to job %s, error: %v\n", artifactID, id, err)
+	}
+}
@@ -3,8 +3,11 @@ package domain
 import (
 	"context"
 	"errors"
+	"fmt"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"testing"
+	"time"
 )
 
 const (
@@ -12,13 +15,19 @@ const (
 	testServiceApplicationName ApplicationName = "Google"
 	testServiceRating          Rating          = 3.8
 	testServiceRatingsAmount   RatingsAmount   = 999
+	testServiceJobID           JobID           = "job-id"
+	testServiceLocale          Locale          = "en-GB"
+	testServiceStore           Store           = "google"
 )
 
+var testServiceStoreResolver = NewHostStoreResolver([]StoreRoute{{HostPattern: "google.com", Store: testServiceStore}})
+
 func TestChannelCrawlerProcessor_Crawl_Success(t *testing.T) {
 	ctx := context.Background()
 
 	repositoryMock := &channelRepositoryMock{}
-	webCrawlerMock := &rokuWebCrawlerMock{}
+	webCrawlerMock := &channelWebCrawlerMock{}
+	jobRepositoryMock := &jobRepositoryMock{}
 
 	channel := NewChannel(
 		testServiceApplicationName,
@@ -26,40 +35,141 @@ func TestChannelCrawlerProcessor_Crawl_Success(t *testing.T) {
 		testServiceRating,
 		testServiceRatingsAmount,
 	)
-	webCrawlerMock.On("CrawlChannel", ctx, testServiceChannelURL).Return(channel, nil)
-	repositoryMock.On("Save", ctx, *channel).Return(nil)
+	webCrawlerMock.On("CrawlChannel", ctx, testServiceChannelURL, testServiceLocale).Return(channel, nil)
+	savedChannel := *channel
+	savedChannel.Locale = testServiceLocale
+	repositoryMock.On("Save", ctx, savedChannel).Return(nil)
+	jobRepositoryMock.On("UpdateStatus", ctx, testServiceJobID, JobStatusRunning, "").Return(nil).Once()
+	jobRepositoryMock.On("UpdateStatus", ctx, testServiceJobID, JobStatusDone, "").Return(nil).Once()
 
-	processor := NewChannelCrawlerProcessor(webCrawlerMock, repositoryMock)
+	processor := NewChannelCrawlerProcessor(webCrawlerMock, testServiceStoreResolver, repositoryMock, jobRepositoryMock)
 
-	err := processor.Crawl(ctx, testServiceChannelURL)
+	request := NewCrawlRequest(testServiceJobID, testServiceChannelURL, testServiceLocale, PriorityNormal)
+	err := processor.Crawl(ctx, *request)
 	require.NoError(t, err)
 	webCrawlerMock.AssertExpectations(t)
 	repositoryMock.AssertExpectations(t)
+	jobRepositoryMock.AssertExpectations(t)
+}
+
+func TestChannelCrawlerProcessor_Crawl_WithoutJob_Success(t *testing.T) {
+	ctx := context.Background()
+
+	repositoryMock := &channelRepositoryMock{}
+	webCrawlerMock := &channelWebCrawlerMock{}
+	jobRepositoryMock := &jobRepositoryMock{}
+
+	channel := NewChannel(
+		testServiceApplicationName,
+		testServiceChannelURL,
+		testServiceRating,
+		testServiceRatingsAmount,
+	)
+	webCrawlerMock.On("CrawlChannel", ctx, testServiceChannelURL, Locale("")).Return(channel, nil)
+	repositoryMock.On("Save", ctx, *channel).Return(nil)
+
+	processor := NewChannelCrawlerProcessor(webCrawlerMock, testServiceStoreResolver, repositoryMock, jobRepositoryMock)
+
+	err := processor.Crawl(ctx, *NewCrawlRequest("", testServiceChannelURL, "", PriorityNormal))
+	require.NoError(t, err)
+	jobRepositoryMock.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
 }
 
 func TestChannelCrawlerProcessor_Crawl_WebCrawlerFailed_ReturnsError(t *testing.T) {
 	ctx := context.Background()
 
 	repositoryMock := &channelRepositoryMock{}
-	webCrawlerMock := &rokuWebCrawlerMock{}
+	webCrawlerMock := &channelWebCrawlerMock{}
+	jobRepositoryMock := &jobRepositoryMock{}
 
 	crawlerErr := errors.New("crawler error")
-	webCrawlerMock.On("CrawlChannel", ctx, testServiceChannelURL).Return(nil, crawlerErr)
+	webCrawlerMock.On("CrawlChannel", ctx, testServiceChannelURL, Locale("")).Return(nil, crawlerErr)
+	jobRepositoryMock.On("UpdateStatus", ctx, testServiceJobID, JobStatusRunning, "").Return(nil).Once()
 
-	processor := NewChannelCrawlerProcessor(webCrawlerMock, repositoryMock)
+	processor := NewChannelCrawlerProcessor(webCrawlerMock, testServiceStoreResolver, repositoryMock, jobRepositoryMock)
 
-	err := processor.Crawl(ctx, testServiceChannelURL)
+	err := processor.Crawl(ctx, *NewCrawlRequest(testServiceJobID, testServiceChannelURL, "", PriorityNormal))
 	require.Error(t, err)
 	require.ErrorIs(t, err, crawlerErr)
 	webCrawlerMock.AssertExpectations(t)
 	repositoryMock.AssertExpectations(t)
+	jobRepositoryMock.AssertExpectations(t)
+}
+
+func TestChannelCrawlerProcessor_Crawl_WebCrawlerFailedWithArtifact_AttachesArtifact(t *testing.T) {
+	ctx := context.Background()
+
+	repositoryMock := &channelRepositoryMock{}
+	webCrawlerMock := &channelWebCrawlerMock{}
+	jobRepositoryMock := &jobRepositoryMock{}
+
+	artifactID := ArtifactID("20220401T120000Z-0a1b2c3d")
+	crawlerErr := NewArtifactError(artifactID, ErrElementNotFound)
+	webCrawlerMock.On("CrawlChannel", ctx, testServiceChannelURL, Locale("")).Return(nil, crawlerErr)
+	jobRepositoryMock.On("UpdateStatus", ctx, testServiceJobID, JobStatusRunning, "").Return(nil).Once()
+	jobRepositoryMock.On("AttachArtifact", ctx, testServiceJobID, artifactID).Return(nil).Once()
+
+	processor := NewChannelCrawlerProcessor(webCrawlerMock, testServiceStoreResolver, repositoryMock, jobRepositoryMock)
+
+	err := processor.Crawl(ctx, *NewCrawlRequest(testServiceJobID, testServiceChannelURL, "", PriorityNormal))
+	require.ErrorIs(t, err, ErrElementNotFound)
+	require.Equal(t, artifactID, ArtifactIDOf(err))
+	require.Contains(t, err.Error(), string(artifactID))
+	jobRepositoryMock.AssertExpectations(t)
+}
+
+func TestChannelCrawlerProcessor_Crawl_PageNotFound_MarksChannelDelisted(t *testing.T) {
+	ctx := context.Background()
+
+	repositoryMock := &channelRepositoryMock{}
+	webCrawlerMock := &channelWebCrawlerMock{}
+	jobRepositoryMock := &jobRepositoryMock{}
+
+	crawlerErr := NewCrawlError(CrawlErrorNotFound, errors.New("status code: 404"))
+	webCrawlerMock.On("CrawlChannel", ctx, testServiceChannelURL, Locale("")).Return(nil, crawlerErr)
+	delistedAt := mock.AnythingOfType("time.Time")
+	repositoryMock.On("MarkDelisted", ctx, testServiceStore, testServiceChannelURL, Locale(""), delistedAt).
+		Return(nil)
+	jobRepositoryMock.On("UpdateStatus", ctx, testServiceJobID, JobStatusRunning, "").Return(nil).Once()
+	jobRepositoryMock.On("UpdateStatus", ctx, testServiceJobID, JobStatusDone, "").Return(nil).Once()
+
+	processor := NewChannelCrawlerProcessor(webCrawlerMock, testServiceStoreResolver, repositoryMock, jobRepositoryMock)
+
+	err := processor.Crawl(ctx, *NewCrawlRequest(testServiceJobID, testServiceChannelURL, "", PriorityNormal))
+	require.NoError(t, err)
+	repositoryMock.AssertExpectations(t)
+	jobRepositoryMock.AssertExpectations(t)
+}
+
+func TestChannelCrawlerProcessor_Crawl_MarkDelistedFailed_ReturnsError(t *testing.T) {
+	ctx := context.Background()
+
+	repositoryMock := &channelRepositoryMock{}
+	webCrawlerMock := &channelWebCrawlerMock{}
+	jobRepositoryMock := &jobRepositoryMock{}
+
+	crawlerErr := NewCrawlError(CrawlErrorNotFound, errors.New("status code: 404"))
+	webCrawlerMock.On("CrawlChannel", ctx, testServiceChannelURL, Locale("")).Return(nil, crawlerErr)
+	repoErr := errors.New("repo err")
+	delistedAt := mock.AnythingOfType("time.Time")
+	repositoryMock.On("MarkDelisted", ctx, testServiceStore, testServiceChannelURL, Locale(""), delistedAt).
+		Return(repoErr)
+	jobRepositoryMock.On("UpdateStatus", ctx, testServiceJobID, JobStatusRunning, "").Return(nil).Once()
+
+	processor := NewChannelCrawlerProcessor(webCrawlerMock, testServiceStoreResolver, repositoryMock, jobRepositoryMock)
+
+	err := processor.Crawl(ctx, *NewCrawlRequest(testServiceJobID, testServiceChannelURL, "", PriorityNormal))
+	require.ErrorIs(t, err, repoErr)
+	repositoryMock.AssertExpectations(t)
+	jobRepositoryMock.AssertExpectations(t)
 }
 
 func TestChannelCrawlerProcessor_Crawl_RepositoryFailed_ReturnsError(t *testing.T) {
 	ctx := context.Background()
 
 	repositoryMock := &channelRepositoryMock{}
-	webCrawlerMock := &rokuWebCrawlerMock{}
+	webCrawlerMock := &channelWebCrawlerMock{}
+	jobRepositoryMock := &jobRepositoryMock{}
 
 	channel := NewChannel(
 		testServiceApplicationName,
@@ -67,16 +177,198 @@ func TestChannelCrawlerProcessor_Crawl_RepositoryFailed_ReturnsError(t *testing.
 		testServiceRating,
 		testServiceRatingsAmount,
 	)
-	webCrawlerMock.On("CrawlChannel", ctx, testServiceChannelURL).Return(channel, nil)
+	webCrawlerMock.On("CrawlChannel", ctx, testServiceChannelURL, Locale("")).Return(channel, nil)
+	jobRepositoryMock.On("UpdateStatus", ctx, testServiceJobID, JobStatusRunning, "").Return(nil).Once()
 
 	repoErr := errors.New("repo err")
 	repositoryMock.On("Save", ctx, *channel).Return(repoErr)
 
-	processor := NewChannelCrawlerProcessor(webCrawlerMock, repositoryMock)
+	processor := NewChannelCrawlerProcessor(webCrawlerMock, testServiceStoreResolver, repositoryMock, jobRepositoryMock)
 
-	err := processor.Crawl(ctx, testServiceChannelURL)
+	err := processor.Crawl(ctx, *NewCrawlRequest(testServiceJobID, testServiceChannelURL, "", PriorityNormal))
 	require.Error(t, err)
 	require.ErrorIs(t, err, repoErr)
 	webCrawlerMock.AssertExpectations(t)
 	repositoryMock.AssertExpectations(t)
+	jobRepositoryMock.AssertExpectations(t)
+}
+
+func TestTrackingCrawlerScheduler_ScheduleBatch_MarksUnpublishedJobsAsFailed(t *testing.T) {
+	ctx := context.Background()
+
+	schedulerMock := &channelCrawlerSchedulerMock{}
+	jobRepositoryMock := &jobRepositoryMock{}
+
+	requests := []CrawlRequest{
+		*NewCrawlRequest("first", testServiceChannelURL, "", PriorityNormal),
+		*NewCrawlRequest("second", testServiceChannelURL, "", PriorityNormal),
+	}
+	publishErr := errors.New("publish error")
+
+	jobRepositoryMock.On(
+		"Create", ctx, mock.MatchedBy(
+			func(jobs []Job) bool {
+				return len(jobs) == 2 && jobs[0].ID == "first" && jobs[1].ID == "second" &&
+					jobs[0].Status == JobStatusQueued && jobs[1].Status == JobStatusQueued
+			},
+		),
+	).Return([]error{nil, nil})
+	schedulerMock.On("ScheduleBatch", ctx, requests).Return([]error{nil, publishErr})
+	jobRepositoryMock.On("UpdateStatus", ctx, JobID("second"), JobStatusFailed, publishErr.Error()).Return(nil)
+
+	errs := NewTrackingCrawlerScheduler(schedulerMock, jobRepositoryMock).ScheduleBatch(ctx, requests)
+
+	require.Len(t, errs, 2)
+	require.NoError(t, errs[0])
+	require.ErrorIs(t, errs[1], publishErr)
+	schedulerMock.AssertExpectations(t)
+	jobRepositoryMock.AssertExpectations(t)
+}
+
+func TestTrackingCrawlerScheduler_ScheduleBatch_JobsNotCreated_ReturnsErrors(t *testing.T) {
+	ctx := context.Background()
+
+	schedulerMock := &channelCrawlerSchedulerMock{}
+	jobRepositoryMock := &jobRepositoryMock{}
+
+	requests := []CrawlRequest{*NewCrawlRequest("first", testServiceChannelURL, "", PriorityNormal)}
+	repoErr := errors.New("repo err")
+	jobRepositoryMock.On("Create", ctx, mock.Anything).Return([]error{repoErr})
+
+	errs := NewTrackingCrawlerScheduler(schedulerMock, jobRepositoryMock).ScheduleBatch(ctx, requests)
+
+	require.Len(t, errs, 1)
+	require.ErrorIs(t, errs[0], repoErr)
+	schedulerMock.AssertNotCalled(t, "ScheduleBatch", mock.Anything, mock.Anything)
+}
+
+func TestTrackingCrawlerScheduler_ScheduleBatch_DuplicateJobs_NotScheduled(t *testing.T) {
+	ctx := context.Background()
+
+	schedulerMock := &channelCrawlerSchedulerMock{}
+	jobRepositoryMock := &jobRepositoryMock{}
+
+	first := *NewCrawlRequest("first", testServiceChannelURL, "", PriorityNormal)
+	second := *NewCrawlRequest("second", "https://google.com/other", "", PriorityNormal)
+	jobRepositoryMock.On("Create", ctx, mock.Anything).Return([]error{NewDuplicateJobError("queued"), nil})
+	schedulerMock.On("ScheduleBatch", ctx, []CrawlRequest{second}).Return([]error{nil})
+
+	errs := NewTrackingCrawlerScheduler(schedulerMock, jobRepositoryMock).
+		ScheduleBatch(ctx, []CrawlRequest{first, second})
+
+	require.Len(t, errs, 2)
+	jobID, duplicate := DuplicateJobIDOf(errs[0])
+	require.True(t, duplicate)
+	require.Equal(t, JobID("queued"), jobID)
+	require.NoError(t, errs[1])
+	schedulerMock.AssertExpectations(t)
+}
+
+func TestChannelRecrawler_Recrawl_SchedulesStaleChannelsOfEveryTier(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2022, 4, 1, 12, 0, 0, 0, time.UTC)
+
+	channelRepositoryMock := &staleChannelRepositoryMock{}
+	schedulerMock := &channelCrawlerSchedulerMock{}
+
+	popularTier, _ := NewFreshnessTier(1000, time.Hour)
+	defaultTier, _ := NewFreshnessTier(0, 24*time.Hour)
+	popular := CrawlTarget{Url: "https://google.com/popular", Locale: "en-GB"}
+	first := CrawlTarget{Url: "https://google.com/first"}
+	second := CrawlTarget{Url: "https://google.com/second", Locale: "de-DE"}
+
+	channelRepositoryMock.On(
+		"FindStale", ctx, StaleChannelCriteria{
+			MinNumberOfRatings: 0,
+			MaxNumberOfRatings: &popularTier.MinNumberOfRatings,
+			StaleBefore:        now.Add(-24 * time.Hour),
+			Limit:              10,
+		},
+	).Return([]CrawlTarget{first, second}, nil)
+	channelRepositoryMock.On(
+		"FindStale", ctx, StaleChannelCriteria{
+			MinNumberOfRatings: 1000,
+			StaleBefore:        now.Add(-time.Hour),
+			Limit:              10,
+		},
+	).Return([]CrawlTarget{popular}, nil)
+
+	schedulerMock.On(
+		"ScheduleBatch", ctx, mock.MatchedBy(
+			func(requests []CrawlRequest) bool {
+				return len(requests) == 2 && requests[0].Url == first.Url && requests[1].Url == second.Url &&
+					requests[1].Locale == second.Locale
+			},
+		),
+	).Return([]error{nil, errors.New("publish error")})
+	schedulerMock.On(
+		"ScheduleBatch", ctx, mock.MatchedBy(
+			func(requests []CrawlRequest) bool {
+				return len(requests) == 1 && requests[0].Url == popular.Url && requests[0].Locale == popular.Locale &&
+					requests[0].JobID != "" && requests[0].Priority == PriorityLow
+			},
+		),
+	).Return([]error{nil})
+
+	channelRepositoryMock.On("MarkScheduled", ctx, []CrawlTarget{first}, now).Return(nil)
+	channelRepositoryMock.On("MarkScheduled", ctx, []CrawlTarget{popular}, now).Return(nil)
+
+	recrawler := NewChannelRecrawler(
+		channelRepositoryMock,
+		schedulerMock,
+		[]FreshnessTier{*popularTier, *defaultTier},
+		10,
+	)
+
+	scheduled, err := recrawler.Recrawl(ctx, now)
+	require.NoError(t, err)
+	require.Equal(t, 2, scheduled)
+	channelRepositoryMock.AssertExpectations(t)
+	schedulerMock.AssertExpectations(t)
+}
+
+func TestStoreWebCrawlerRegistry_CrawlChannel_RoutesByHost(t *testing.T) {
+	ctx := context.Background()
+
+	rokuRoute, _ := NewStoreRoute("*.roku.com", "roku")
+	appleRoute, _ := NewStoreRoute("apps.apple.com", "apple")
+	rokuCrawlerMock := &channelWebCrawlerMock{}
+	appleCrawlerMock := &channelWebCrawlerMock{}
+
+	appleUrl := Url("https://apps.apple.com/us/app/netflix/id363590051")
+	channel := NewChannel(testServiceApplicationName, appleUrl, testServiceRating, testServiceRatingsAmount)
+	appleCrawlerMock.On("CrawlChannel", ctx, appleUrl, testServiceLocale).Return(channel, nil)
+
+	registry := NewStoreWebCrawlerRegistry(
+		NewHostStoreResolver([]StoreRoute{*rokuRoute, *appleRoute}),
+		map[Store]ChannelWebCrawler{"roku": rokuCrawlerMock, "apple": appleCrawlerMock},
+	)
+
+	crawled, err := registry.CrawlChannel(ctx, appleUrl, testServiceLocale)
+	require.NoError(t, err)
+	require.EqualValues(t, "apple", crawled.Store)
+	appleCrawlerMock.AssertExpectations(t)
+	rokuCrawlerMock.AssertNotCalled(t, "CrawlChannel", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestStoreWebCrawlerRegistry_CrawlChannel_UnknownHost_ReturnsError(t *testing.T) {
+	rokuRoute, _ := NewStoreRoute("*.roku.com", "roku")
+	registry := NewStoreWebCrawlerRegistry(
+		NewHostStoreResolver([]StoreRoute{*rokuRoute}),
+		map[Store]ChannelWebCrawler{"roku": &channelWebCrawlerMock{}},
+	)
+
+	_, err := registry.CrawlChannel(context.Background(), testServiceChannelURL, "")
+	require.ErrorIs(t, err, ErrUnsupportedStore)
+}
+
+func TestCrawlErrorKindOf(t *testing.T) {
+	crawlErr := NewCrawlError(CrawlErrorNotFound, errors.New("status code: 404"))
+
+	require.Equal(t, CrawlErrorNotFound, CrawlErrorKindOf(fmt.Errorf("could not crawl channel, error: %w", crawlErr)))
+	require.Equal(t, CrawlErrorTimeout, CrawlErrorKindOf(fmt.Errorf("page not loaded, %w", context.DeadlineExceeded)))
+	require.Equal(t, CrawlErrorMarkupChanged, CrawlErrorKindOf(fmt.Errorf("%w: .title", ErrElementNotFound)))
+	require.Equal(t, CrawlErrorUnknown, CrawlErrorKindOf(errors.New("connection reset")))
+	require.False(t, CrawlErrorKindOf(crawlErr).IsRetryable())
+	require.True(t, CrawlErrorBlocked.IsRetryable())
 }
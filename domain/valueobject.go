@@ -1,17 +1,91 @@
 package domain
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"net"
 	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
 )
 
 type Url string
 type ApplicationName string
 type Rating float32
 type RatingsAmount uint32 // Not sure how many rating it could have but at least we know it will be a positive number
+type JobID string
+type JobStatus string
+type Store string // Identifier of the app store the channel is listed in
+type ArtifactID string
+type ChannelStatus string
 
+// Locale is the language and the country the store page is crawled in, e.g. en-GB. Store ratings
+// and availability differ by country, so the channel data are kept for every country separately.
+// Empty locale crawls the page in the store default.
+type Locale string
+
+// Priority is the lane the crawl request waits in, requests of higher priority are consumed more often,
+// so ad-hoc requests do not wait behind bulk imports
+type Priority string
+
+// Country is the uppercase ISO 3166-1 alpha-2 code of the country, e.g. GB, empty country is the store default
+type Country string
+
+// StoreChannelID identifies the channel in its store, it's derived from the url, so it does not change
+// when the channel is renamed
+type StoreChannelID string
+type Developer string
+type Category string
+type Price string // Price as shown by the store, e.g. "Free" or "$4.99"
+type Description string
+type ContentRating string
+
+// StarDistribution holds amounts of 1 to 5 star ratings, the amount of 1 star ratings is the first one
+type StarDistribution [5]RatingsAmount
+
+const (
+	JobStatusQueued  JobStatus = "queued"
+	JobStatusRunning JobStatus = "running"
+	JobStatusFailed  JobStatus = "failed"
+	JobStatusDone    JobStatus = "done"
+)
+
+const (
+	PriorityHigh   Priority = "high"
+	PriorityNormal Priority = "normal"
+	// PriorityLow is meant for bulk imports and periodic recrawls
+	PriorityLow Priority = "low"
+)
+
+// Priorities lists all the priorities from the highest one
+var Priorities = []Priority{PriorityHigh, PriorityNormal, PriorityLow}
+
+var (
+	appStoreIDPattern = regexp.MustCompile(`^id\d+$`)
+	localePattern     = regexp.MustCompile(`^([a-zA-Z]{2,3})[-_]([a-zA-Z]{2})$`)
+	countryPattern    = regexp.MustCompile(`^[a-zA-Z]{2}$`)
+)
+
+const (
+	ChannelStatusActive ChannelStatus = "active"
+	// ChannelStatusDelisted is a status of the channel which page is not found in the store anymore
+	ChannelStatusDelisted ChannelStatus = "delisted"
+)
+
+// NewURL validates the url and brings it to the canonical form, so the same channel page is always crawled
+// under a single url. Canonical url:
+//   - uses https, except of internal hosts (hosts without a dot and IP addresses) usually served over plain http
+//   - has lowercase host without the default port, no fragment and no trailing slash
+//   - has no tracking query parameters (utm_*, fbclid, ...), the remaining ones are sorted
+//   - ends with the store channel id when the path contains it, so the slug does not matter
+//     (/details/<id>/<slug> becomes /details/<id>, /app/<slug>/id<number> becomes /app/id<number>)
+//     and the query is dropped as the page is identified by the path
 func NewURL(value string) (*Url, error) {
+	value = strings.TrimSpace(value)
 	if value == "" {
 		return nil, errors.New("url could not be empty")
 	}
@@ -21,10 +95,251 @@ func NewURL(value string) (*Url, error) {
 		return nil, fmt.Errorf("invalid url specified %w", err)
 	}
 
-	crawlUrl := Url(value)
+	// ParseRequestURI keeps the fragment in the path
+	parsedUrl, err := url.Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url specified %w", err)
+	}
+
+	parsedUrl.Scheme = strings.ToLower(parsedUrl.Scheme)
+	if (parsedUrl.Scheme != "http" && parsedUrl.Scheme != "https") || parsedUrl.Hostname() == "" {
+		return nil, fmt.Errorf("invalid url specified, absolute http(s) url is required: %s", value)
+	}
+
+	crawlUrl := Url(canonicalURL(parsedUrl))
 	return &crawlUrl, nil
 }
 
+// Query parameters added by campaigns and share buttons, they never change the page
+var (
+	trackingQueryParams = []string{
+		"fbclid", "gclid", "dclid", "msclkid", "igshid", "mc_cid", "mc_eid", "ref", "ref_src",
+	}
+	trackingQueryParamPrefixes = []string{"utm_", "_ga"}
+)
+
+func canonicalURL(parsedUrl *url.URL) string {
+	host := strings.ToLower(parsedUrl.Hostname())
+	port := parsedUrl.Port()
+	internalHost := !strings.Contains(host, ".") || net.ParseIP(host) != nil
+	if parsedUrl.Scheme == "http" && !internalHost {
+		parsedUrl.Scheme = "https"
+	}
+	if (parsedUrl.Scheme == "https" && port == "443") || (parsedUrl.Scheme == "http" && port == "80") {
+		port = ""
+	}
+	parsedUrl.Host = host
+	if strings.Contains(host, ":") {
+		parsedUrl.Host = "[" + host + "]"
+	}
+	if port != "" {
+		parsedUrl.Host += ":" + port
+	}
+
+	segments := pathSegments(parsedUrl.Path)
+	if idSegment := storeChannelIDSegment(segments); idSegment >= 0 {
+		segments = segments[:idSegment+1]
+		if appStoreIDPattern.MatchString(segments[idSegment]) && idSegment >= 2 && segments[idSegment-2] == "app" {
+			segments = append(segments[:idSegment-1], segments[idSegment])
+		}
+		parsedUrl.RawQuery = ""
+	} else {
+		query := parsedUrl.Query()
+		for param := range query {
+			if isTrackingQueryParam(param) {
+				query.Del(param)
+			}
+		}
+		parsedUrl.RawQuery = query.Encode()
+	}
+
+	parsedUrl.Path = ""
+	if len(segments) > 0 {
+		parsedUrl.Path = "/" + strings.Join(segments, "/")
+	}
+	parsedUrl.RawPath = ""
+	parsedUrl.Fragment = ""
+	parsedUrl.RawFragment = ""
+	parsedUrl.ForceQuery = false
+
+	return parsedUrl.String()
+}
+
+func isTrackingQueryParam(param string) bool {
+	param = strings.ToLower(param)
+	for _, trackingParam := range trackingQueryParams {
+		if param == trackingParam {
+			return true
+		}
+	}
+	for _, prefix := range trackingQueryParamPrefixes {
+		if strings.HasPrefix(param, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func pathSegments(path string) []string {
+	return strings.FieldsFunc(
+		path, func(r rune) bool {
+			return r == '/'
+		},
+	)
+}
+
+// storeChannelIDSegment returns index of the path segment holding the store channel id, -1 when there is none.
+// The id is the segment following "details" or the App Store like id<number> last segment.
+func storeChannelIDSegment(segments []string) int {
+	for i, segment := range segments {
+		if strings.EqualFold(segment, "details") && i+1 < len(segments) {
+			return i + 1
+		}
+	}
+
+	if len(segments) > 0 && appStoreIDPattern.MatchString(segments[len(segments)-1]) {
+		return len(segments) - 1
+	}
+
+	return -1
+}
+
+// Host returns lowercase host of the url without the port, empty host when the url could not be parsed
+func (u Url) Host() string {
+	parsedUrl, err := url.Parse(string(u))
+	if err != nil {
+		return ""
+	}
+
+	return strings.ToLower(parsedUrl.Hostname())
+}
+
+// NewStoreChannelID derives the id from the url of the channel page. The id is the segment following "details"
+// (e.g. /details/<id>/<slug>), the App Store like id<number> segment or the id query parameter, the host
+// with the path is used when the url contains neither of them.
+func NewStoreChannelID(channelUrl Url) (*StoreChannelID, error) {
+	parsedUrl, err := url.Parse(string(channelUrl))
+	if err != nil || parsedUrl.Host == "" {
+		return nil, fmt.Errorf("store channel id could not be derived from url %s", channelUrl)
+	}
+
+	segments := pathSegments(parsedUrl.Path)
+
+	id := ""
+	if idSegment := storeChannelIDSegment(segments); idSegment >= 0 {
+		id = segments[idSegment]
+	}
+
+	if id == "" {
+		id = parsedUrl.Query().Get("id")
+	}
+
+	if id == "" {
+		id = strings.ToLower(parsedUrl.Host) + "/" + strings.Join(segments, "/")
+	}
+
+	storeChannelID := StoreChannelID(id)
+	return &storeChannelID, nil
+}
+
+// NewLocale validates the language-country pair and brings it to the canonical form, e.g. en_gb becomes en-GB.
+// Empty value is the store default locale.
+func NewLocale(value string) (*Locale, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		locale := Locale("")
+		return &locale, nil
+	}
+
+	parts := localePattern.FindStringSubmatch(value)
+	if parts == nil {
+		return nil, fmt.Errorf("invalid locale %s, language and country are required, e.g. en-GB", value)
+	}
+
+	locale := Locale(strings.ToLower(parts[1]) + "-" + strings.ToUpper(parts[2]))
+	return &locale, nil
+}
+
+// NewPriority validates the priority of the crawl request, empty value is the normal priority
+func NewPriority(value string) (*Priority, error) {
+	priority := Priority(strings.ToLower(strings.TrimSpace(value)))
+	switch priority {
+	case "":
+		priority = PriorityNormal
+	case PriorityHigh, PriorityNormal, PriorityLow:
+	default:
+		return nil, fmt.Errorf("invalid priority %s, one of high, normal or low is expected", value)
+	}
+
+	return &priority, nil
+}
+
+// Outranks tells whether the requests of the priority are consumed before the requests of the other one,
+// empty priority is the normal one
+func (p Priority) Outranks(other Priority) bool {
+	return p.rank() < other.rank()
+}
+
+// AtLeast returns the priorities which are not outranked by the priority, from the highest one
+func (p Priority) AtLeast() []Priority {
+	priorities := make([]Priority, 0, len(Priorities))
+	for _, priority := range Priorities {
+		if !p.Outranks(priority) {
+			priorities = append(priorities, priority)
+		}
+	}
+
+	return priorities
+}
+
+func (p Priority) rank() int {
+	if p == "" {
+		p = PriorityNormal
+	}
+
+	for rank, priority := range Priorities {
+		if priority == p {
+			return rank
+		}
+	}
+
+	return len(Priorities)
+}
+
+// Language returns lowercase language of the locale, empty language for the store default locale
+func (l Locale) Language() string {
+	language, _, _ := strings.Cut(string(l), "-")
+	return language
+}
+
+// Country returns the country of the locale, empty country for the store default locale
+func (l Locale) Country() Country {
+	_, country, _ := strings.Cut(string(l), "-")
+	return Country(country)
+}
+
+// AcceptLanguage returns value of the Accept-Language header preferring the locale over its language alone,
+// empty value for the store default locale
+func (l Locale) AcceptLanguage() string {
+	if l == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("%s,%s;q=0.9", l, l.Language())
+}
+
+// NewCountry validates the country code and brings it to uppercase, empty value is the store default country
+func NewCountry(value string) (*Country, error) {
+	value = strings.TrimSpace(value)
+	if value != "" && !countryPattern.MatchString(value) {
+		return nil, fmt.Errorf("invalid country %s, two letter code is required, e.g. GB", value)
+	}
+
+	country := Country(strings.ToUpper(value))
+	return &country, nil
+}
+
 func NewApplicationName(value string) (*ApplicationName, error) {
 	if value == "" {
 		return nil, errors.New("application name could not be empty")
@@ -49,3 +364,116 @@ func NewRatingsAmount(value uint32) (*RatingsAmount, error) {
 
 	return &ratingAmount, nil
 }
+
+func NewStore(value string) (*Store, error) {
+	if value == "" {
+		return nil, errors.New("store could not be empty")
+	}
+
+	store := Store(value)
+	return &store, nil
+}
+
+func NewJobID(value string) (*JobID, error) {
+	if value == "" {
+		return nil, errors.New("job id could not be empty")
+	}
+
+	jobID := JobID(value)
+	return &jobID, nil
+}
+
+// GenerateJobID creates new random job id
+func GenerateJobID() JobID {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		panic(fmt.Sprintf("could not generate random job id, %v", err))
+	}
+
+	return JobID(hex.EncodeToString(b))
+}
+
+// GenerateArtifactID creates id of the failure artifact, ids are ordered by the time of the failure
+func GenerateArtifactID(capturedAt time.Time) ArtifactID {
+	b := make([]byte, 4)
+	_, err := rand.Read(b)
+	if err != nil {
+		panic(fmt.Sprintf("could not generate random artifact id, %v", err))
+	}
+
+	return ArtifactID(capturedAt.UTC().Format("20060102T150405Z") + "-" + hex.EncodeToString(b))
+}
+
+func NewStarDistribution(amounts []uint32) (*StarDistribution, error) {
+	var distribution StarDistribution
+	if len(amounts) != len(distribution) {
+		return nil, fmt.Errorf("star distribution has to have %d amounts, got: %d", len(distribution), len(amounts))
+	}
+
+	for i, amount := range amounts {
+		distribution[i] = RatingsAmount(amount)
+	}
+
+	return &distribution, nil
+}
+
+// Total returns amount of all the ratings
+func (d StarDistribution) Total() RatingsAmount {
+	var total RatingsAmount
+	for _, amount := range d {
+		total += amount
+	}
+
+	return total
+}
+
+// IsFinal tells whether job in this status will not change anymore
+func (s JobStatus) IsFinal() bool {
+	return s == JobStatusFailed || s == JobStatusDone
+}
+
+// FreshnessTier defines how long data of channels with at least MinNumberOfRatings ratings stay fresh
+type FreshnessTier struct {
+	MinNumberOfRatings RatingsAmount
+	MaxAge             time.Duration
+}
+
+func NewFreshnessTier(minNumberOfRatings uint32, maxAge time.Duration) (*FreshnessTier, error) {
+	if maxAge <= 0 {
+		return nil, errors.New("freshness max age has to be positive duration")
+	}
+
+	return &FreshnessTier{MinNumberOfRatings: RatingsAmount(minNumberOfRatings), MaxAge: maxAge}, nil
+}
+
+// StoreRoute routes urls with host matching the pattern to the store, the pattern could contain wildcards,
+// e.g. *.roku.com matches every subdomain of roku.com
+type StoreRoute struct {
+	HostPattern string
+	Store       Store
+}
+
+func NewStoreRoute(hostPattern string, store string) (*StoreRoute, error) {
+	if hostPattern == "" {
+		return nil, errors.New("store host pattern could not be empty")
+	}
+
+	_, err := path.Match(hostPattern, "")
+	if err != nil {
+		return nil, fmt.Errorf("invalid store host pattern %s, %w", hostPattern, err)
+	}
+
+	storeValue, err := NewStore(store)
+	if err != nil {
+		return nil, err
+	}
+
+	return &StoreRoute{HostPattern: strings.ToLower(hostPattern), Store: *storeValue}, nil
+}
+
+// Matches tells whether the host belongs to the store
+func (r StoreRoute) Matches(host string) bool {
+	matched, _ := path.Match(r.HostPattern, strings.ToLower(host))
+	return matched
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"testing"
+	"time"
 )
 
 func TestNewApplicationName_ValidValue(t *testing.T) {
@@ -33,6 +34,43 @@ func TestNewURL_EmptyValue_ReturnsError(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestNewURL_Canonical(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected Url
+	}{
+		{
+			value:    "http://ChannelStore.Roku.com:443/details/12/netflix-free/?utm_source=newsletter",
+			expected: "https://channelstore.roku.com/details/12",
+		},
+		{
+			value:    " https://channelstore.roku.com/en-gb/details/96da35e0#reviews ",
+			expected: "https://channelstore.roku.com/en-gb/details/96da35e0",
+		},
+		{
+			value:    "https://apps.apple.com/us/app/netflix/id363590051?mt=8",
+			expected: "https://apps.apple.com/us/app/id363590051",
+		},
+		{
+			value:    "https://play.google.com/store/apps/details?id=com.netflix&gclid=1&hl=en",
+			expected: "https://play.google.com/store/apps/details?hl=en&id=com.netflix",
+		},
+		{value: "https://example.com//apps/netflix/?fbclid=abc", expected: "https://example.com/apps/netflix"},
+		{value: "https://google.com/", expected: "https://google.com"},
+		{value: "http://fake-channel-server/no-data.html", expected: "http://fake-channel-server/no-data.html"},
+		{value: "http://127.0.0.1:8080/", expected: "http://127.0.0.1:8080"},
+	}
+
+	for _, testCase := range testCases {
+		url, err := NewURL(testCase.value)
+		require.NoError(t, err, testCase.value)
+		assert.Equal(t, testCase.expected, *url, testCase.value)
+	}
+
+	_, err := NewURL("ftp://example.com/file")
+	require.Error(t, err)
+}
+
 func TestNewRating_ValidValue(t *testing.T) {
 	rating, err := NewRating(3.89)
 	require.NoError(t, err)
@@ -43,3 +81,145 @@ func TestNewRating_NegativeValue_ReturnsError(t *testing.T) {
 	_, err := NewRating(-1)
 	require.Error(t, err)
 }
+
+func TestNewJobID_ValidValue(t *testing.T) {
+	jobID, err := NewJobID("test")
+	require.NoError(t, err)
+	assert.EqualValues(t, "test", *jobID)
+}
+
+func TestNewJobID_EmptyValue_ReturnsError(t *testing.T) {
+	_, err := NewJobID("")
+	require.Error(t, err)
+}
+
+func TestGenerateJobID_UniqueValues(t *testing.T) {
+	first := GenerateJobID()
+	second := GenerateJobID()
+	assert.Len(t, first, 32)
+	assert.NotEqual(t, first, second)
+}
+
+func TestGenerateArtifactID_PrefixedWithCaptureTime(t *testing.T) {
+	capturedAt := time.Date(2022, 4, 1, 12, 30, 0, 0, time.UTC)
+	first := GenerateArtifactID(capturedAt)
+	second := GenerateArtifactID(capturedAt)
+	assert.Regexp(t, `^20220401T123000Z-[0-9a-f]{8}$`, first)
+	assert.NotEqual(t, first, second)
+}
+
+func TestNewStarDistribution(t *testing.T) {
+	distribution, err := NewStarDistribution([]uint32{1, 2, 3, 4, 10})
+	require.NoError(t, err)
+	assert.Equal(t, StarDistribution{1, 2, 3, 4, 10}, *distribution)
+	assert.EqualValues(t, 20, distribution.Total())
+
+	_, err = NewStarDistribution([]uint32{1, 2, 3})
+	require.Error(t, err)
+}
+
+func TestNewFreshnessTier_NotPositiveMaxAge_ReturnsError(t *testing.T) {
+	_, err := NewFreshnessTier(1000, 0)
+	require.Error(t, err)
+}
+
+func TestNewStoreRoute_InvalidPattern_ReturnsError(t *testing.T) {
+	_, err := NewStoreRoute("[roku.com", "roku")
+	require.Error(t, err)
+}
+
+func TestStoreRoute_Matches(t *testing.T) {
+	route, err := NewStoreRoute("*.Roku.com", "roku")
+	require.NoError(t, err)
+	assert.True(t, route.Matches("channelstore.roku.com"))
+	assert.False(t, route.Matches("roku.com"))
+	assert.False(t, route.Matches("apps.apple.com"))
+}
+
+func TestNewStoreChannelID(t *testing.T) {
+	testCases := map[Url]StoreChannelID{
+		"https://channelstore.roku.com/details/12/netflix":                      "12",
+		"https://channelstore.roku.com/en-gb/details/96da35e0/netflix-uk":       "96da35e0",
+		"https://apps.apple.com/us/app/netflix/id363590051":                     "id363590051",
+		"https://play.google.com/store/apps/details?id=com.netflix.mediaclient": "com.netflix.mediaclient",
+		"https://Example.com/apps/netflix/":                                     "example.com/apps/netflix",
+	}
+
+	for url, expected := range testCases {
+		id, err := NewStoreChannelID(url)
+		require.NoError(t, err)
+		assert.Equal(t, expected, *id, url)
+	}
+
+	_, err := NewStoreChannelID("details/12")
+	require.Error(t, err)
+}
+
+func TestNewLocale(t *testing.T) {
+	testCases := map[string]Locale{
+		"en-GB":   "en-GB",
+		" en_gb ": "en-GB",
+		"DE-at":   "de-AT",
+		"":        "",
+	}
+
+	for value, expected := range testCases {
+		locale, err := NewLocale(value)
+		require.NoError(t, err)
+		assert.Equal(t, expected, *locale, value)
+	}
+
+	for _, value := range []string{"en", "english-GB", "en-GBR"} {
+		_, err := NewLocale(value)
+		require.Error(t, err, value)
+	}
+}
+
+func TestLocale_Parts(t *testing.T) {
+	locale := Locale("en-GB")
+	assert.Equal(t, "en", locale.Language())
+	assert.Equal(t, Country("GB"), locale.Country())
+	assert.Equal(t, "en-GB,en;q=0.9", locale.AcceptLanguage())
+
+	assert.Equal(t, Country(""), Locale("").Country())
+	assert.Empty(t, Locale("").AcceptLanguage())
+}
+
+func TestNewCountry(t *testing.T) {
+	country, err := NewCountry("gb")
+	require.NoError(t, err)
+	assert.Equal(t, Country("GB"), *country)
+
+	_, err = NewCountry("GBR")
+	require.Error(t, err)
+}
+
+func TestNewPriority(t *testing.T) {
+	testCases := map[string]Priority{
+		"high":   PriorityHigh,
+		" LOW ":  PriorityLow,
+		"normal": PriorityNormal,
+		"":       PriorityNormal,
+	}
+
+	for value, expected := range testCases {
+		priority, err := NewPriority(value)
+		require.NoError(t, err)
+		assert.Equal(t, expected, *priority, value)
+	}
+
+	_, err := NewPriority("urgent")
+	require.Error(t, err)
+}
+
+func TestPriority_Outranks(t *testing.T) {
+	assert.True(t, PriorityHigh.Outranks(PriorityNormal))
+	assert.True(t, PriorityNormal.Outranks(PriorityLow))
+	assert.True(t, PriorityHigh.Outranks(""))
+	assert.False(t, Priority("").Outranks(PriorityNormal))
+	assert.False(t, PriorityLow.Outranks(PriorityHigh))
+
+	assert.Equal(t, []Priority{PriorityHigh}, PriorityHigh.AtLeast())
+	assert.Equal(t, []Priority{PriorityHigh, PriorityNormal}, Priority("").AtLeast())
+	assert.Equal(t, Priorities, PriorityLow.AtLeast())
+}
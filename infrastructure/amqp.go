@@ -2,41 +2,275 @@ package infrastructure
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/streadway/amqp"
 	"go-web-crawler-service/domain"
+	"go-web-crawler-service/metrics"
+	"go-web-crawler-service/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 	"log"
+	"sync"
+	"time"
+)
+
+const (
+	confirmsBufferSize = 1000
+
+	// JobIDHeader holds id of the job the url published in the legacy format belongs to
+	JobIDHeader = "x-job-id"
+	// LocaleHeader holds locale the url published in the legacy format is crawled in,
+	// the url is crawled in the store default without it
+	LocaleHeader = "x-locale"
+	// PriorityHeader holds priority of the url published in the legacy format, the url has normal priority without it
+	PriorityHeader = "x-priority"
+)
+
+var (
+	errPublishNotConfirmed = errors.New("broker did not confirm the message")
 )
 
 type amqpPublisher struct {
 	channel    *amqp.Channel
 	exchange   string
 	routingKey string
+	format     MessageFormat
+	// requester is the service publishing the crawl requests, it's recorded in the envelope
+	requester string
+
+	mu              sync.Mutex
+	confirms        chan amqp.Confirmation
+	lastDeliveryTag uint64
 }
 
-func NewAmqpPublisher(channel *amqp.Channel, exchange string, routingKey string) *amqpPublisher {
+// NewAmqpPublisher puts the channel into confirm mode, so every published message is confirmed by the broker
+func NewAmqpPublisher(
+	channel *amqp.Channel,
+	exchange string,
+	routingKey string,
+	format MessageFormat,
+	requester string,
+) (*amqpPublisher, error) {
+	err := channel.Confirm(false)
+	if err != nil {
+		return nil, fmt.Errorf("could not put AMQP channel into confirm mode, %w", err)
+	}
+
 	return &amqpPublisher{
 		channel:    channel,
 		exchange:   exchange,
 		routingKey: routingKey,
+		format:     format,
+		requester:  requester,
+		confirms:   channel.NotifyPublish(make(chan amqp.Confirmation, confirmsBufferSize)),
+	}, nil
+}
+
+func (p *amqpPublisher) Schedule(ctx context.Context, request domain.CrawlRequest) error {
+	return p.ScheduleBatch(ctx, []domain.CrawlRequest{request})[0]
+}
+
+// ScheduleBatch publishes the messages without waiting for the broker and then collects their confirmations.
+// Batches bigger than confirmations buffer are split into chunks so the connection is never blocked on confirmations.
+func (p *amqpPublisher) ScheduleBatch(ctx context.Context, requests []domain.CrawlRequest) []error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	errs := make([]error, len(requests))
+
+	for start := 0; start < len(requests); start += confirmsBufferSize {
+		end := start + confirmsBufferSize
+		if end > len(requests) {
+			end = len(requests)
+		}
+
+		p.publishChunk(ctx, requests[start:end], errs[start:end])
+	}
+
+	for i, request := range requests {
+		if errs[i] == nil {
+			log.Printf("Successfully published message to crawl, url: %s\n", request.Url)
+		}
 	}
+
+	return errs
 }
 
-func (p *amqpPublisher) Schedule(ctx context.Context, url domain.Url) error {
-	err := p.channel.Publish(
-		p.exchange, p.routingKey, false, false, amqp.Publishing{
-			Headers:      amqp.Table{},
-			ContentType:  "text/plain",
-			Body:         []byte(url),
-			DeliveryMode: amqp.Persistent,
-		},
-	)
+func (p *amqpPublisher) publishChunk(ctx context.Context, requests []domain.CrawlRequest, errs []error) {
+	deliveryTags := make(map[uint64]int, len(requests))
+	publishedAt := time.Now()
+
+	for i, request := range requests {
+		spanCtx, span := tracing.Tracer().Start(
+			ctx,
+			"publish crawl request",
+			trace.WithSpanKind(trace.SpanKindProducer),
+			trace.WithAttributes(attribute.String("url", string(request.Url))),
+		)
+		publishing, err := newCrawlRequestPublishing(spanCtx, request, p.format, p.requester)
+		if err == nil {
+			routingKey := LaneRoutingKey(p.routingKey, request.Priority)
+			err = p.channel.Publish(p.exchange, routingKey, false, false, publishing)
+		}
+		tracing.End(span, err)
+
+		if err != nil {
+			log.Printf("Failed to publish message with url %s\n", request.Url)
+			errs[i] = fmt.Errorf("failed to publish message with url %s, %w", request.Url, err)
+			metrics.PublishFailures.Inc()
+			continue
+		}
+
+		p.lastDeliveryTag++
+		deliveryTags[p.lastDeliveryTag] = i
+	}
+
+	p.waitForConfirms(ctx, deliveryTags, errs, publishedAt)
+}
+
+func (p *amqpPublisher) waitForConfirms(
+	ctx context.Context,
+	deliveryTags map[uint64]int,
+	errs []error,
+	publishedAt time.Time,
+) {
+	for len(deliveryTags) > 0 {
+		select {
+		case confirmation, ok := <-p.confirms:
+			if !ok {
+				p.failUnconfirmed(deliveryTags, errs, amqp.ErrClosed)
+				return
+			}
+
+			// Confirmations of messages that were given up on in previous batches are skipped
+			i, found := deliveryTags[confirmation.DeliveryTag]
+			if !found {
+				continue
+			}
+			delete(deliveryTags, confirmation.DeliveryTag)
 
+			if !confirmation.Ack {
+				errs[i] = errPublishNotConfirmed
+				metrics.PublishFailures.Inc()
+				continue
+			}
+
+			metrics.PublishDuration.Observe(time.Since(publishedAt).Seconds())
+		case <-ctx.Done():
+			p.failUnconfirmed(deliveryTags, errs, ctx.Err())
+			return
+		}
+	}
+}
+
+func (p *amqpPublisher) failUnconfirmed(deliveryTags map[uint64]int, errs []error, err error) {
+	for _, i := range deliveryTags {
+		errs[i] = fmt.Errorf("message was not confirmed, %w", err)
+	}
+	metrics.PublishFailures.Add(float64(len(deliveryTags)))
+}
+
+// newCrawlRequestPublishing encodes crawl request with the trace context of the publisher in the given format
+func newCrawlRequestPublishing(
+	ctx context.Context,
+	request domain.CrawlRequest,
+	format MessageFormat,
+	requester string,
+) (amqp.Publishing, error) {
+	if format == MessageFormatText {
+		return newLegacyCrawlRequestPublishing(ctx, request), nil
+	}
+
+	body, err := json.Marshal(newCrawlRequestEnvelope(ctx, request, requester))
+	if err != nil {
+		return amqp.Publishing{}, fmt.Errorf("failed to encode envelope, %w", err)
+	}
+
+	return amqp.Publishing{
+		Headers:      amqp.Table{},
+		ContentType:  CrawlRequestContentType,
+		Body:         body,
+		DeliveryMode: amqp.Persistent,
+	}, nil
+}
+
+// newLegacyCrawlRequestPublishing encodes crawl request as the plain url with the rest of the request in the headers
+func newLegacyCrawlRequestPublishing(ctx context.Context, request domain.CrawlRequest) amqp.Publishing {
+	headers := amqp.Table{JobIDHeader: string(request.JobID)}
+	if request.Locale != "" {
+		headers[LocaleHeader] = string(request.Locale)
+	}
+	if request.Priority != "" {
+		headers[PriorityHeader] = string(request.Priority)
+	}
+	otel.GetTextMapPropagator().Inject(ctx, amqpHeadersCarrier(headers))
+
+	return amqp.Publishing{
+		Headers:      headers,
+		ContentType:  LegacyContentType,
+		Body:         []byte(request.Url),
+		DeliveryMode: amqp.Persistent,
+	}
+}
+
+// NewCrawlRequestFromDelivery decodes crawl request from the consumed message, both the envelope and the legacy
+// plain url are decoded. Messages published without job have empty job id, the ones without locale have
+// empty locale and the ones without priority have normal priority.
+func NewCrawlRequestFromDelivery(d amqp.Delivery) (*domain.CrawlRequest, error) {
+	envelope, err := decodeCrawlRequestEnvelope(d)
+	if err != nil {
+		return nil, err
+	}
+
+	url, err := domain.NewURL(envelope.Url)
+	if err != nil {
+		return nil, fmt.Errorf("message contains invalid url, %w", err)
+	}
+
+	locale, err := domain.NewLocale(envelope.Locale)
 	if err != nil {
-		log.Printf("Failed to publish message with url %s\n", url)
-		return fmt.Errorf("failed to publish message with url %s", url)
+		return nil, fmt.Errorf("message contains invalid locale, %w", err)
+	}
+
+	priority, err := domain.NewPriority(envelope.Priority)
+	if err != nil {
+		return nil, fmt.Errorf("message contains invalid priority, %w", err)
+	}
+
+	return domain.NewCrawlRequest(domain.JobID(envelope.JobID), *url, *locale, *priority), nil
+}
+
+// ExtractTraceContext returns the context continuing the trace the consumed message was published in
+func ExtractTraceContext(ctx context.Context, d amqp.Delivery) context.Context {
+	envelope, err := decodeCrawlRequestEnvelope(d)
+	if err != nil || len(envelope.TraceContext) == 0 {
+		return ctx
+	}
+
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(envelope.TraceContext))
+}
+
+// amqpHeadersCarrier adapts message headers to the carrier of the trace context
+type amqpHeadersCarrier amqp.Table
+
+func (c amqpHeadersCarrier) Get(key string) string {
+	value, _ := c[key].(string)
+	return value
+}
+
+func (c amqpHeadersCarrier) Set(key string, value string) {
+	c[key] = value
+}
+
+func (c amqpHeadersCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
 	}
 
-	log.Printf("Successfully published message to crawl, url: %s\n", url)
-	return nil
+	return keys
 }
@@ -0,0 +1,160 @@
+package infrastructure
+
+import (
+	"context"
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go-web-crawler-service/domain"
+	"go-web-crawler-service/tracing"
+	"go.opentelemetry.io/otel/trace"
+	"testing"
+)
+
+func TestCrawlRequestPublishing_TraceContext(t *testing.T) {
+	shutdown, err := tracing.Init(context.Background(), "test", tracing.ExporterNoop, "", 1)
+	require.NoError(t, err)
+	t.Cleanup(
+		func() {
+			_ = shutdown(context.Background())
+		},
+	)
+
+	request := domain.NewCrawlRequest("job", "https://channelstore.roku.com/details/12", "", domain.PriorityNormal)
+
+	for _, format := range []MessageFormat{MessageFormatJSON, MessageFormatText} {
+		t.Run(
+			string(format)+" trace continued by the consumer", func(t *testing.T) {
+				ctx, span := tracing.Tracer().Start(context.Background(), "test")
+				defer span.End()
+
+				publishing, err := newCrawlRequestPublishing(ctx, *request, format, "api")
+				require.NoError(t, err)
+				consumerCtx := ExtractTraceContext(context.Background(), deliveryOf(publishing))
+
+				consumerSpan := trace.SpanContextFromContext(consumerCtx)
+				assert.True(t, consumerSpan.IsRemote())
+				assert.Equal(t, span.SpanContext().TraceID(), consumerSpan.TraceID())
+				assert.Equal(t, span.SpanContext().SpanID(), consumerSpan.SpanID())
+			},
+		)
+
+		t.Run(
+			string(format)+" message published without trace", func(t *testing.T) {
+				publishing, err := newCrawlRequestPublishing(context.Background(), *request, format, "api")
+				require.NoError(t, err)
+				consumerCtx := ExtractTraceContext(context.Background(), deliveryOf(publishing))
+
+				assert.False(t, trace.SpanContextFromContext(consumerCtx).IsValid())
+			},
+		)
+	}
+}
+
+func TestNewCrawlRequestFromDelivery(t *testing.T) {
+	request := domain.NewCrawlRequest("job", "https://channelstore.roku.com/details/12", "en-GB", domain.PriorityHigh)
+
+	for _, format := range []MessageFormat{MessageFormatJSON, MessageFormatText} {
+		t.Run(
+			string(format), func(t *testing.T) {
+				publishing, err := newCrawlRequestPublishing(context.Background(), *request, format, "api")
+				require.NoError(t, err)
+
+				decoded, err := NewCrawlRequestFromDelivery(deliveryOf(publishing))
+				require.NoError(t, err)
+				assert.Equal(t, request, decoded)
+			},
+		)
+	}
+
+	testCases := []struct {
+		name     string
+		delivery amqp.Delivery
+		expected *domain.CrawlRequest
+		err      bool
+	}{
+		{
+			name:     "legacy message without content type and headers",
+			delivery: amqp.Delivery{Body: []byte("https://channelstore.roku.com/details/12")},
+			expected: domain.NewCrawlRequest("", "https://channelstore.roku.com/details/12", "", domain.PriorityNormal),
+		},
+		{
+			name: "legacy message with invalid locale",
+			delivery: amqp.Delivery{
+				ContentType: LegacyContentType,
+				Headers:     amqp.Table{LocaleHeader: "gb"},
+				Body:        []byte("https://channelstore.roku.com/details/12"),
+			},
+			err: true,
+		},
+		{
+			name: "envelope with unknown fields",
+			delivery: amqp.Delivery{
+				ContentType: CrawlRequestContentType,
+				Body:        []byte(`{"version":1,"url":"https://channelstore.roku.com/details/12","unknown":true}`),
+			},
+			expected: domain.NewCrawlRequest("", "https://channelstore.roku.com/details/12", "", domain.PriorityNormal),
+		},
+		{
+			name: "envelope with invalid priority",
+			delivery: amqp.Delivery{
+				ContentType: CrawlRequestContentType,
+				Body:        []byte(`{"version":1,"url":"https://google.com/","priority":"urgent"}`),
+			},
+			err: true,
+		},
+		{
+			name: "envelope of newer version",
+			delivery: amqp.Delivery{
+				ContentType: CrawlRequestContentType,
+				Body:        []byte(`{"version":2,"url":"https://channelstore.roku.com/details/12"}`),
+			},
+			err: true,
+		},
+		{
+			name: "envelope with invalid url",
+			delivery: amqp.Delivery{
+				ContentType: CrawlRequestContentType,
+				Body:        []byte(`{"version":1,"url":"channel"}`),
+			},
+			err: true,
+		},
+		{
+			name:     "invalid envelope",
+			delivery: amqp.Delivery{ContentType: CrawlRequestContentType, Body: []byte("https://google.com/")},
+			err:      true,
+		},
+		{
+			name:     "unknown content type",
+			delivery: amqp.Delivery{ContentType: "application/xml", Body: []byte("<url/>")},
+			err:      true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(
+			testCase.name, func(t *testing.T) {
+				decoded, err := NewCrawlRequestFromDelivery(testCase.delivery)
+				if testCase.err {
+					require.Error(t, err)
+					return
+				}
+
+				require.NoError(t, err)
+				assert.Equal(t, testCase.expected, decoded)
+			},
+		)
+	}
+}
+
+func TestLaneNames(t *testing.T) {
+	assert.Equal(t, "channel_crawler", LaneQueueName("channel_crawler", domain.PriorityNormal))
+	assert.Equal(t, "channel_crawler", LaneQueueName("channel_crawler", ""))
+	assert.Equal(t, "channel_crawler.high", LaneQueueName("channel_crawler", domain.PriorityHigh))
+	assert.Equal(t, "channel_url", LaneRoutingKey("channel_url", domain.PriorityNormal))
+	assert.Equal(t, "channel_url.low", LaneRoutingKey("channel_url", domain.PriorityLow))
+}
+
+func deliveryOf(publishing amqp.Publishing) amqp.Delivery {
+	return amqp.Delivery{Headers: publishing.Headers, ContentType: publishing.ContentType, Body: publishing.Body}
+}
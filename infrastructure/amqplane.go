@@ -0,0 +1,22 @@
+package infrastructure
+
+import "go-web-crawler-service/domain"
+
+// LaneQueueName is a name of the queue holding crawl requests of the priority. Normal priority keeps the queue name,
+// so the queue of the version before the lanes becomes the normal lane.
+func LaneQueueName(queue string, priority domain.Priority) string {
+	if priority == domain.PriorityNormal || priority == "" {
+		return queue
+	}
+
+	return queue + "." + string(priority)
+}
+
+// LaneRoutingKey is a routing key of the crawl requests of the priority, normal priority keeps the routing key
+func LaneRoutingKey(routingKey string, priority domain.Priority) string {
+	if priority == domain.PriorityNormal || priority == "" {
+		return routingKey
+	}
+
+	return routingKey + "." + string(priority)
+}
@@ -0,0 +1,154 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/streadway/amqp"
+	"go-web-crawler-service/domain"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"time"
+)
+
+// MessageFormat is the format the crawl requests are published in
+type MessageFormat string
+
+const (
+	// MessageFormatJSON publishes the crawl request as the versioned JSON envelope
+	MessageFormatJSON MessageFormat = "json"
+	// MessageFormatText publishes the plain url with the rest of the request in the headers,
+	// it's understood by the workers released before the envelope
+	MessageFormatText MessageFormat = "text"
+)
+
+const (
+	// CrawlRequestContentType is the content type of the crawl request envelope
+	CrawlRequestContentType = "application/vnd.web-crawler.crawl-request+json"
+	// LegacyContentType is the content type of the crawl request published as the plain url
+	LegacyContentType = "text/plain"
+
+	// crawlRequestVersion is the latest version of the envelope, consumers decode all the versions up to it
+	crawlRequestVersion = 1
+)
+
+// NewMessageFormat validates the format of the published crawl requests
+func NewMessageFormat(value string) (MessageFormat, error) {
+	switch format := MessageFormat(value); format {
+	case MessageFormatJSON, MessageFormatText:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unknown message format: %s", value)
+	}
+}
+
+// crawlRequestEnvelope is the JSON body of the crawl request message. New fields are added as optional ones,
+// the version is raised only when the existing fields change their meaning.
+type crawlRequestEnvelope struct {
+	Version int    `json:"version"`
+	JobID   string `json:"job_id,omitempty"`
+	Url     string `json:"url"`
+	Locale  string `json:"locale,omitempty"`
+	// Priority is the lane the message was published to, the normal priority when it's empty
+	Priority string `json:"priority,omitempty"`
+	// Attempt is the number of the delivery attempt, the retry publisher raises it
+	Attempt int `json:"attempt"`
+	// Requester is the service that scheduled the crawl, e.g. api or scheduler
+	Requester   string    `json:"requester,omitempty"`
+	PublishedAt time.Time `json:"published_at"`
+	// TraceContext holds the trace context of the publisher (traceparent, tracestate, baggage)
+	TraceContext map[string]string `json:"trace_context,omitempty"`
+}
+
+func newCrawlRequestEnvelope(ctx context.Context, request domain.CrawlRequest, requester string) crawlRequestEnvelope {
+	traceContext := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, traceContext)
+
+	return crawlRequestEnvelope{
+		Version:      crawlRequestVersion,
+		JobID:        string(request.JobID),
+		Url:          string(request.Url),
+		Locale:       string(request.Locale),
+		Priority:     string(request.Priority),
+		Attempt:      1,
+		Requester:    requester,
+		PublishedAt:  time.Now().UTC(),
+		TraceContext: traceContext,
+	}
+}
+
+// decodeCrawlRequestEnvelope decodes the envelope of the consumed message, the legacy plain url message is decoded
+// into the envelope from its body and headers
+func decodeCrawlRequestEnvelope(d amqp.Delivery) (*crawlRequestEnvelope, error) {
+	switch d.ContentType {
+	case CrawlRequestContentType:
+		var envelope crawlRequestEnvelope
+		err := json.Unmarshal(d.Body, &envelope)
+		if err != nil {
+			return nil, fmt.Errorf("message body is not a valid envelope, %w", err)
+		}
+		if envelope.Version < 1 || envelope.Version > crawlRequestVersion {
+			return nil, fmt.Errorf("unsupported envelope version %d", envelope.Version)
+		}
+		if envelope.Attempt < 1 {
+			envelope.Attempt = 1
+		}
+
+		return &envelope, nil
+	case LegacyContentType, "":
+		jobID, _ := d.Headers[JobIDHeader].(string)
+		locale, _ := d.Headers[LocaleHeader].(string)
+		priority, _ := d.Headers[PriorityHeader].(string)
+		traceContext := propagation.MapCarrier{}
+		for _, key := range otel.GetTextMapPropagator().Fields() {
+			if value, ok := d.Headers[key].(string); ok {
+				traceContext[key] = value
+			}
+		}
+
+		return &crawlRequestEnvelope{
+			JobID:        jobID,
+			Url:          string(d.Body),
+			Locale:       locale,
+			Priority:     priority,
+			Attempt:      headerAttempt(d.Headers),
+			TraceContext: traceContext,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported message content type %s", d.ContentType)
+	}
+}
+
+// withAttempt sets the attempt of the republished message, legacy messages keep it in the header. Only the attempt
+// of the envelope is replaced, so the fields added by newer publishers are not lost on the retry.
+func withAttempt(publishing amqp.Publishing, attempt int) (amqp.Publishing, error) {
+	if publishing.ContentType != CrawlRequestContentType {
+		publishing.Headers[AttemptHeader] = int32(attempt)
+		return publishing, nil
+	}
+
+	var envelope map[string]json.RawMessage
+	err := json.Unmarshal(publishing.Body, &envelope)
+	if err != nil {
+		return publishing, fmt.Errorf("message body is not a valid envelope, %w", err)
+	}
+	envelope["attempt"], _ = json.Marshal(attempt)
+
+	publishing.Body, err = json.Marshal(envelope)
+	if err != nil {
+		return publishing, fmt.Errorf("failed to encode envelope, %w", err)
+	}
+
+	return publishing, nil
+}
+
+func headerAttempt(headers amqp.Table) int {
+	switch attempt := headers[AttemptHeader].(type) {
+	case int32:
+		return int(attempt)
+	case int64:
+		return int(attempt)
+	default:
+		return 1
+	}
+}
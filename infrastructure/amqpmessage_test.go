@@ -0,0 +1,88 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go-web-crawler-service/domain"
+	"testing"
+)
+
+func TestNewMessageFormat(t *testing.T) {
+	format, err := NewMessageFormat("json")
+	require.NoError(t, err)
+	assert.Equal(t, MessageFormatJSON, format)
+
+	format, err = NewMessageFormat("text")
+	require.NoError(t, err)
+	assert.Equal(t, MessageFormatText, format)
+
+	_, err = NewMessageFormat("xml")
+	require.Error(t, err)
+}
+
+func TestCrawlRequestEnvelope(t *testing.T) {
+	request := domain.NewCrawlRequest("job", "https://channelstore.roku.com/details/12", "en-GB", domain.PriorityNormal)
+	publishing, err := newCrawlRequestPublishing(context.Background(), *request, MessageFormatJSON, "scheduler")
+	require.NoError(t, err)
+
+	var envelope crawlRequestEnvelope
+	require.NoError(t, json.Unmarshal(publishing.Body, &envelope))
+	assert.Equal(t, CrawlRequestContentType, publishing.ContentType)
+	assert.Equal(t, crawlRequestVersion, envelope.Version)
+	assert.Equal(t, "job", envelope.JobID)
+	assert.Equal(t, "https://channelstore.roku.com/details/12", envelope.Url)
+	assert.Equal(t, "en-GB", envelope.Locale)
+	assert.Equal(t, 1, envelope.Attempt)
+	assert.Equal(t, "scheduler", envelope.Requester)
+	assert.False(t, envelope.PublishedAt.IsZero())
+}
+
+func TestDeliveryAttempt(t *testing.T) {
+	request := domain.NewCrawlRequest("job", "https://channelstore.roku.com/details/12", "", domain.PriorityNormal)
+
+	for _, format := range []MessageFormat{MessageFormatJSON, MessageFormatText} {
+		t.Run(
+			string(format), func(t *testing.T) {
+				publishing, err := newCrawlRequestPublishing(context.Background(), *request, format, "api")
+				require.NoError(t, err)
+				assert.Equal(t, 1, DeliveryAttempt(deliveryOf(publishing)))
+
+				retried, err := withAttempt(newRepublishing(deliveryOf(publishing), assert.AnError), 2)
+				require.NoError(t, err)
+				assert.Equal(t, 2, DeliveryAttempt(deliveryOf(retried)))
+
+				decoded, err := NewCrawlRequestFromDelivery(deliveryOf(retried))
+				require.NoError(t, err)
+				assert.Equal(t, request, decoded)
+			},
+		)
+	}
+
+	t.Run(
+		"envelope keeps unknown fields on retry", func(t *testing.T) {
+			publishing := amqp.Publishing{
+				Headers:     amqp.Table{},
+				ContentType: CrawlRequestContentType,
+				Body:        []byte(`{"version":1,"url":"https://google.com/","attempt":1,"unknown":"value"}`),
+			}
+
+			retried, err := withAttempt(publishing, 3)
+			require.NoError(t, err)
+			assert.JSONEq(
+				t,
+				`{"version":1,"url":"https://google.com/","attempt":3,"unknown":"value"}`,
+				string(retried.Body),
+			)
+		},
+	)
+
+	t.Run(
+		"legacy message with attempt header", func(t *testing.T) {
+			d := amqp.Delivery{Headers: amqp.Table{AttemptHeader: int64(3)}, Body: []byte("https://google.com/")}
+			assert.Equal(t, 3, DeliveryAttempt(d))
+		},
+	)
+}
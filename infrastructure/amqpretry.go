@@ -0,0 +1,122 @@
+package infrastructure
+
+import (
+	"fmt"
+	"github.com/streadway/amqp"
+	"go-web-crawler-service/domain"
+	"time"
+)
+
+const (
+	// AttemptHeader holds the number of the delivery attempt, messages without the header are on their first attempt
+	AttemptHeader   = "x-attempt"
+	LastErrorHeader = "x-last-error"
+	// ArtifactIDHeader holds id of the page artifact captured when the last attempt failed
+	ArtifactIDHeader = "x-artifact-id"
+	// ErrorKindHeader holds kind of the last failure, see domain.CrawlErrorKind
+	ErrorKindHeader = "x-error-kind"
+)
+
+// RetryExchangeName is a name of the exchange delaying messages for given retry tier
+func RetryExchangeName(exchange string, tier int) string {
+	return fmt.Sprintf("%s.retry.%d", exchange, tier+1)
+}
+
+// RetryQueueName is a name of the queue holding messages of given retry tier until their TTL expires
+func RetryQueueName(queue string, tier int) string {
+	return fmt.Sprintf("%s.retry.%d", queue, tier+1)
+}
+
+// DeadLetterExchangeName is a name of the exchange routing messages that will not be retried anymore
+func DeadLetterExchangeName(exchange string) string {
+	return exchange + ".dlx"
+}
+
+// ParkingQueueName is a name of the queue holding messages that will not be retried anymore
+func ParkingQueueName(queue string) string {
+	return queue + ".parked"
+}
+
+// DeliveryAttempt returns the number of the delivery attempt, message that could not be decoded is on its first one
+func DeliveryAttempt(d amqp.Delivery) int {
+	envelope, err := decodeCrawlRequestEnvelope(d)
+	if err != nil {
+		return 1
+	}
+
+	return envelope.Attempt
+}
+
+type amqpRetryPublisher struct {
+	channel     *amqp.Channel
+	exchange    string
+	retryDelays []time.Duration
+}
+
+// NewAmqpRetryPublisher creates publisher that moves failed messages to delayed retry queues or to the parking queue
+func NewAmqpRetryPublisher(channel *amqp.Channel, exchange string, retryDelays []time.Duration) *amqpRetryPublisher {
+	return &amqpRetryPublisher{
+		channel:     channel,
+		exchange:    exchange,
+		retryDelays: retryDelays,
+	}
+}
+
+// Retry publishes the message to the retry tier matching its attempt, the delay grows with every attempt
+// until the last tier is reached. Message keeps its routing key, so it comes back to the queue it was consumed from.
+func (p *amqpRetryPublisher) Retry(d amqp.Delivery, reason error) error {
+	if len(p.retryDelays) == 0 {
+		return p.Park(d, reason)
+	}
+
+	attempt := DeliveryAttempt(d)
+	tier := attempt - 1
+	if tier >= len(p.retryDelays) {
+		tier = len(p.retryDelays) - 1
+	}
+
+	publishing, err := withAttempt(newRepublishing(d, reason), attempt+1)
+	if err != nil {
+		return err
+	}
+
+	err = p.channel.Publish(RetryExchangeName(p.exchange, tier), d.RoutingKey, false, false, publishing)
+	if err != nil {
+		return fmt.Errorf("failed to publish message to retry tier %d, %w", tier+1, err)
+	}
+
+	return nil
+}
+
+// Park publishes the message to the parking queue where it waits for manual inspection
+func (p *amqpRetryPublisher) Park(d amqp.Delivery, reason error) error {
+	err := p.channel.Publish(DeadLetterExchangeName(p.exchange), d.RoutingKey, false, false, newRepublishing(d, reason))
+	if err != nil {
+		return fmt.Errorf("failed to publish message to parking queue, %w", err)
+	}
+
+	return nil
+}
+
+func newRepublishing(d amqp.Delivery, reason error) amqp.Publishing {
+	headers := amqp.Table{}
+	for key, value := range d.Headers {
+		headers[key] = value
+	}
+	headers[LastErrorHeader] = reason.Error()
+	headers[ErrorKindHeader] = string(domain.CrawlErrorKindOf(reason))
+	if artifactID := domain.ArtifactIDOf(reason); artifactID != "" {
+		headers[ArtifactIDHeader] = string(artifactID)
+	} else {
+		delete(headers, ArtifactIDHeader)
+	}
+
+	return amqp.Publishing{
+		Headers:         headers,
+		ContentType:     d.ContentType,
+		ContentEncoding: d.ContentEncoding,
+		Body:            d.Body,
+		DeliveryMode:    amqp.Persistent,
+		MessageId:       d.MessageId,
+	}
+}
@@ -0,0 +1,161 @@
+package infrastructure
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"go-web-crawler-service/domain"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	artifactScreenshotFile = "screenshot.png"
+	artifactDOMFile        = "dom.html"
+	artifactConsoleLogFile = "console.log"
+	artifactMetadataFile   = "metadata.json"
+)
+
+type artifactMetadata struct {
+	ID         string    `json:"id"`
+	Url        string    `json:"url"`
+	Reason     string    `json:"reason"`
+	Proxy      string    `json:"proxy,omitempty"`
+	CapturedAt time.Time `json:"capturedAt"`
+}
+
+type artifactFile struct {
+	name        string
+	contentType string
+	content     []byte
+}
+
+// artifactFiles lays out the artifact as a set of files, every store keeps them under the artifact id
+func artifactFiles(artifact domain.FailureArtifact) ([]artifactFile, error) {
+	metadata, err := json.MarshalIndent(
+		artifactMetadata{
+			ID:         string(artifact.ID),
+			Url:        string(artifact.Url),
+			Reason:     artifact.Reason,
+			Proxy:      artifact.Proxy,
+			CapturedAt: artifact.CapturedAt,
+		}, "", "  ",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode artifact metadata, error: %w", err)
+	}
+
+	files := []artifactFile{
+		{name: artifactMetadataFile, contentType: "application/json", content: metadata},
+		{name: artifactDOMFile, contentType: "text/html", content: []byte(artifact.DOM)},
+		{
+			name:        artifactConsoleLogFile,
+			contentType: "text/plain",
+			content:     []byte(strings.Join(artifact.ConsoleLog, "\n")),
+		},
+	}
+
+	// Screenshot is missing when the page could not be rendered at all
+	if len(artifact.Screenshot) > 0 {
+		files = append(
+			files,
+			artifactFile{name: artifactScreenshotFile, contentType: "image/png", content: artifact.Screenshot},
+		)
+	}
+
+	return files, nil
+}
+
+type fileArtifactStore struct {
+	dir string
+}
+
+// NewFileArtifactStore creates store keeping every artifact in its own directory inside the dir
+func NewFileArtifactStore(dir string) *fileArtifactStore {
+	return &fileArtifactStore{
+		dir: dir,
+	}
+}
+
+func (s *fileArtifactStore) Save(_ context.Context, artifact domain.FailureArtifact) error {
+	files, err := artifactFiles(artifact)
+	if err != nil {
+		return err
+	}
+
+	artifactDir := filepath.Join(s.dir, string(artifact.ID))
+	err = os.MkdirAll(artifactDir, 0o755)
+	if err != nil {
+		return fmt.Errorf("failed to create artifact directory %s, error: %w", artifactDir, err)
+	}
+
+	for _, file := range files {
+		err = os.WriteFile(filepath.Join(artifactDir, file.name), file.content, 0o644)
+		if err != nil {
+			return fmt.Errorf("failed to write artifact file %s, error: %w", file.name, err)
+		}
+	}
+
+	return nil
+}
+
+type s3ArtifactStore struct {
+	client *minio.Client
+	bucket string
+}
+
+// NewS3ArtifactStore creates store keeping artifacts in the bucket of S3 compatible storage,
+// files of the artifact are prefixed with its id
+func NewS3ArtifactStore(
+	endpoint string,
+	region string,
+	bucket string,
+	accessKey string,
+	secretKey string,
+	useSSL bool,
+) (*s3ArtifactStore, error) {
+	client, err := minio.New(
+		endpoint, &minio.Options{
+			Creds:  credentials.NewStaticV4(accessKey, secretKey, ""),
+			Secure: useSSL,
+			Region: region,
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 client, error: %w", err)
+	}
+
+	return &s3ArtifactStore{
+		client: client,
+		bucket: bucket,
+	}, nil
+}
+
+func (s *s3ArtifactStore) Save(ctx context.Context, artifact domain.FailureArtifact) error {
+	files, err := artifactFiles(artifact)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		key := string(artifact.ID) + "/" + file.name
+		_, err = s.client.PutObject(
+			ctx,
+			s.bucket,
+			key,
+			bytes.NewReader(file.content),
+			int64(len(file.content)),
+			minio.PutObjectOptions{ContentType: file.contentType},
+		)
+		if err != nil {
+			return fmt.Errorf("failed to upload artifact file %s, error: %w", key, err)
+		}
+	}
+
+	return nil
+}
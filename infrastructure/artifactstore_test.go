@@ -0,0 +1,93 @@
+package infrastructure
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go-web-crawler-service/domain"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+var testFailureArtifact = domain.FailureArtifact{
+	ID:         "20220401T120000Z-0a1b2c3d",
+	Url:        "https://channelstore.roku.com/details/12",
+	Reason:     "element not found: h1",
+	Screenshot: []byte("png"),
+	DOM:        "<html><body>Access denied</body></html>",
+	ConsoleLog: []string{"[error] blocked", "[log] done"},
+	CapturedAt: time.Date(2022, 4, 1, 12, 0, 0, 0, time.UTC),
+}
+
+func TestFileArtifactStore_Save(t *testing.T) {
+	dir := t.TempDir()
+
+	err := NewFileArtifactStore(dir).Save(context.Background(), testFailureArtifact)
+	require.NoError(t, err)
+
+	artifactDir := filepath.Join(dir, string(testFailureArtifact.ID))
+	for name, expected := range map[string]string{
+		artifactScreenshotFile: "png",
+		artifactDOMFile:        testFailureArtifact.DOM,
+		artifactConsoleLogFile: "[error] blocked\n[log] done",
+	} {
+		content, err := os.ReadFile(filepath.Join(artifactDir, name))
+		require.NoError(t, err)
+		assert.Equal(t, expected, string(content), name)
+	}
+
+	metadata, err := os.ReadFile(filepath.Join(artifactDir, artifactMetadataFile))
+	require.NoError(t, err)
+	assert.Contains(t, string(metadata), testFailureArtifact.Reason)
+	assert.Contains(t, string(metadata), string(testFailureArtifact.Url))
+}
+
+func TestS3ArtifactStore_Save(t *testing.T) {
+	var mu sync.Mutex
+	uploaded := map[string]string{}
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPut {
+					w.WriteHeader(http.StatusMethodNotAllowed)
+					return
+				}
+
+				body, _ := io.ReadAll(r.Body)
+				mu.Lock()
+				uploaded[r.URL.Path] = string(body)
+				mu.Unlock()
+
+				w.Header().Set("ETag", `"etag"`)
+			},
+		),
+	)
+	t.Cleanup(server.Close)
+
+	store, err := NewS3ArtifactStore(
+		strings.TrimPrefix(server.URL, "http://"),
+		"us-east-1",
+		"artifacts",
+		"access",
+		"secret",
+		false,
+	)
+	require.NoError(t, err)
+
+	err = store.Save(context.Background(), testFailureArtifact)
+	require.NoError(t, err)
+
+	prefix := "/artifacts/" + string(testFailureArtifact.ID) + "/"
+	// Uploads over plain HTTP are chunk signed, so the content is surrounded by the signatures
+	assert.Contains(t, uploaded[prefix+artifactScreenshotFile], "png")
+	assert.Contains(t, uploaded[prefix+artifactDOMFile], testFailureArtifact.DOM)
+	assert.Contains(t, uploaded, prefix+artifactConsoleLogFile)
+	assert.Contains(t, uploaded, prefix+artifactMetadataFile)
+}
@@ -0,0 +1,416 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/launcher"
+	"github.com/go-rod/rod/lib/proto"
+	"go-web-crawler-service/metrics"
+	"log"
+	"sync"
+	"time"
+)
+
+const browserHealthCheckTimeout = 5 * time.Second
+
+var (
+	errBrowserPoolClosed = errors.New("browser pool is closed")
+)
+
+// pooledBrowser is a browser process managed by the pool
+type pooledBrowser interface {
+	rod() *rod.Browser
+	// check fails when the browser does not respond
+	check(ctx context.Context) error
+	// disconnected is closed when the connection to the browser is lost
+	disconnected() <-chan struct{}
+	close()
+}
+
+// BrowserPoolStats is a snapshot of the pool state
+type BrowserPoolStats struct {
+	// Browsers is an amount of running browsers, including the retired ones still rendering pages
+	Browsers       int
+	PagesInUse     int
+	PagesServed    uint64
+	Launches       uint64
+	LaunchFailures uint64
+}
+
+// browserInstance is a single launch of the browser in the slot
+type browserInstance struct {
+	browser     pooledBrowser
+	pagesInUse  int
+	pagesServed int
+	// retired instance does not get new pages and it's closed when the pages in use are released
+	retired bool
+	closed  bool
+}
+
+// browserSlot holds the current browser instance, the instance is replaced when it's recycled or it crashed
+type browserSlot struct {
+	mu      sync.Mutex
+	current *browserInstance
+	// launchReason is the reason the empty slot will be launched for
+	launchReason string
+}
+
+type browserPool struct {
+	launch             func() (pooledBrowser, error)
+	maxPagesPerBrowser int
+	slots              []*browserSlot
+	// tabs holds a slot for every free tab, so acquiring the tab blocks until a page is released
+	tabs    chan *browserSlot
+	closing sync.WaitGroup
+
+	mu     sync.Mutex
+	stats  BrowserPoolStats
+	closed bool
+}
+
+// NewBrowserPool creates pool of the browsers each rendering limited amount of pages at once. Browsers are launched
+// on the first use, relaunched when they crash and recycled after serving maxPagesPerBrowser pages (0 disables it)
+// to bound the memory they leak.
+func NewBrowserPool(bin string, size int, tabsPerBrowser int, maxPagesPerBrowser int) *browserPool {
+	return newBrowserPool(
+		func() (pooledBrowser, error) {
+			return launchRodBrowser(bin)
+		},
+		size,
+		tabsPerBrowser,
+		maxPagesPerBrowser,
+	)
+}
+
+func newBrowserPool(
+	launch func() (pooledBrowser, error),
+	size int,
+	tabsPerBrowser int,
+	maxPagesPerBrowser int,
+) *browserPool {
+	pool := &browserPool{
+		launch:             launch,
+		maxPagesPerBrowser: maxPagesPerBrowser,
+		tabs:               make(chan *browserSlot, size*tabsPerBrowser),
+	}
+
+	for i := 0; i < size; i++ {
+		pool.slots = append(pool.slots, &browserSlot{launchReason: metrics.BrowserLaunchInitial})
+	}
+
+	// Tabs of the browsers are interleaved, so the pages are spread over all the browsers
+	for i := 0; i < tabsPerBrowser; i++ {
+		for _, slot := range pool.slots {
+			pool.tabs <- slot
+		}
+	}
+
+	return pool
+}
+
+// browserLease is a tab of the browser acquired from the pool
+type browserLease struct {
+	pool     *browserPool
+	slot     *browserSlot
+	instance *browserInstance
+}
+
+// Browser returns the browser the page is opened in
+func (l *browserLease) Browser() *rod.Browser {
+	return l.instance.browser.rod()
+}
+
+// Release returns the tab to the pool, it's called when the page is closed
+func (l *browserLease) Release() {
+	l.slot.mu.Lock()
+	l.instance.pagesInUse--
+	if l.instance.retired && l.instance.pagesInUse == 0 {
+		l.pool.closeInstance(l.instance)
+	}
+	l.slot.mu.Unlock()
+
+	l.pool.updateStats(
+		func(stats *BrowserPoolStats) {
+			stats.PagesInUse--
+		},
+	)
+	metrics.BrowserPagesInUse.Dec()
+
+	l.pool.tabs <- l.slot
+}
+
+// Acquire waits for a free tab and returns the browser to open the page in, browser is (re)launched
+// when the slot is empty or its browser served too many pages
+func (p *browserPool) Acquire(ctx context.Context) (*browserLease, error) {
+	var slot *browserSlot
+	select {
+	case slot = <-p.tabs:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("no browser tab available, %w", ctx.Err())
+	}
+
+	slot.mu.Lock()
+	defer slot.mu.Unlock()
+
+	if p.isClosed() {
+		p.tabs <- slot
+		return nil, errBrowserPoolClosed
+	}
+
+	instance := slot.current
+	if instance == nil || (p.maxPagesPerBrowser > 0 && instance.pagesServed >= p.maxPagesPerBrowser) {
+		reason := slot.launchReason
+		if instance != nil {
+			reason = metrics.BrowserLaunchRecycled
+		}
+
+		var err error
+		instance, err = p.replace(slot, reason)
+		if err != nil {
+			p.tabs <- slot
+			return nil, err
+		}
+	}
+
+	instance.pagesInUse++
+	instance.pagesServed++
+	p.updateStats(
+		func(stats *BrowserPoolStats) {
+			stats.PagesInUse++
+			stats.PagesServed++
+		},
+	)
+	metrics.BrowserPagesInUse.Inc()
+
+	return &browserLease{pool: p, slot: slot, instance: instance}, nil
+}
+
+// RunHealthChecks checks the browsers in the interval and relaunches the ones not responding
+// until the context is done
+func (p *browserPool) RunHealthChecks(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.checkHealth(ctx)
+		}
+	}
+}
+
+func (p *browserPool) checkHealth(ctx context.Context) {
+	for _, slot := range p.slots {
+		slot.mu.Lock()
+		instance := slot.current
+		slot.mu.Unlock()
+
+		if instance == nil {
+			continue
+		}
+
+		checkCtx, cancel := context.WithTimeout(ctx, browserHealthCheckTimeout)
+		err := instance.browser.check(checkCtx)
+		cancel()
+		if err == nil || ctx.Err() != nil {
+			continue
+		}
+
+		slot.mu.Lock()
+		if slot.current == instance && !p.isClosed() {
+			log.Printf("Browser is not responding, relaunching it, error: %v\n", err)
+			_, err = p.replace(slot, metrics.BrowserLaunchUnhealthy)
+			if err != nil {
+				log.Println(err)
+			}
+		}
+		slot.mu.Unlock()
+	}
+}
+
+// watch relaunches the browser as soon as the connection to it is lost
+func (p *browserPool) watch(slot *browserSlot, instance *browserInstance) {
+	<-instance.browser.disconnected()
+
+	slot.mu.Lock()
+	defer slot.mu.Unlock()
+
+	if slot.current != instance || p.isClosed() {
+		return
+	}
+
+	log.Println("Browser disconnected, relaunching it")
+	_, err := p.replace(slot, metrics.BrowserLaunchDisconnected)
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+// replace retires the current browser of the slot and launches a new one, slot has to be locked.
+// Slot is left empty when the browser could not be launched, so the launch is retried by the next acquire.
+func (p *browserPool) replace(slot *browserSlot, reason string) (*browserInstance, error) {
+	if slot.current != nil {
+		p.retire(slot.current)
+		slot.current = nil
+	}
+
+	browser, err := p.launch()
+	if err != nil {
+		slot.launchReason = reason
+		p.updateStats(
+			func(stats *BrowserPoolStats) {
+				stats.LaunchFailures++
+			},
+		)
+		metrics.BrowserLaunchFailures.Inc()
+		return nil, fmt.Errorf("failed to launch browser, %w", err)
+	}
+
+	instance := &browserInstance{browser: browser}
+	slot.current = instance
+	p.updateStats(
+		func(stats *BrowserPoolStats) {
+			stats.Browsers++
+			stats.Launches++
+		},
+	)
+	metrics.BrowserLaunches.WithLabelValues(reason).Inc()
+	metrics.BrowsersRunning.Inc()
+
+	go p.watch(slot, instance)
+
+	return instance, nil
+}
+
+// retire stops giving pages of the instance out and closes it once its pages are released, slot has to be locked
+func (p *browserPool) retire(instance *browserInstance) {
+	instance.retired = true
+	if instance.pagesInUse == 0 {
+		p.closeInstance(instance)
+	}
+}
+
+// closeInstance closes the browser in the background as closing the process takes a while, slot has to be locked
+func (p *browserPool) closeInstance(instance *browserInstance) {
+	if instance.closed {
+		return
+	}
+	instance.closed = true
+
+	p.updateStats(
+		func(stats *BrowserPoolStats) {
+			stats.Browsers--
+		},
+	)
+	metrics.BrowsersRunning.Dec()
+
+	p.closing.Add(1)
+	go func() {
+		defer p.closing.Done()
+		instance.browser.close()
+	}()
+}
+
+// Close closes all the browsers, the ones rendering pages are closed when the pages are released
+func (p *browserPool) Close() {
+	p.mu.Lock()
+	p.closed = true
+	p.mu.Unlock()
+
+	for _, slot := range p.slots {
+		slot.mu.Lock()
+		if slot.current != nil {
+			p.retire(slot.current)
+			slot.current = nil
+		}
+		slot.mu.Unlock()
+	}
+
+	p.closing.Wait()
+}
+
+// Stats returns the current state of the pool
+func (p *browserPool) Stats() BrowserPoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.stats
+}
+
+func (p *browserPool) updateStats(update func(stats *BrowserPoolStats)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	update(&p.stats)
+}
+
+func (p *browserPool) isClosed() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.closed
+}
+
+// rodPooledBrowser is a headless browser launched by rod
+type rodPooledBrowser struct {
+	browser  *rod.Browser
+	launcher *launcher.Launcher
+	done     chan struct{}
+}
+
+func launchRodBrowser(bin string) (pooledBrowser, error) {
+	browserLauncher := launcher.New().Bin(bin)
+	controlURL, err := browserLauncher.Launch()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start browser %s, error: %w", bin, err)
+	}
+
+	browser := rod.New().ControlURL(controlURL)
+	err = browser.Connect()
+	if err != nil {
+		browserLauncher.Kill()
+		return nil, fmt.Errorf("failed to connect to browser, error: %w", err)
+	}
+
+	pooled := &rodPooledBrowser{
+		browser:  browser,
+		launcher: browserLauncher,
+		done:     make(chan struct{}),
+	}
+
+	// Events stop being delivered once the connection is closed
+	go func() {
+		defer close(pooled.done)
+		for range browser.Event() {
+		}
+	}()
+
+	return pooled, nil
+}
+
+func (b *rodPooledBrowser) rod() *rod.Browser {
+	return b.browser
+}
+
+func (b *rodPooledBrowser) check(ctx context.Context) error {
+	_, err := proto.BrowserGetVersion{}.Call(b.browser.Context(ctx))
+	if err != nil {
+		return fmt.Errorf("browser health check failed, error: %w", err)
+	}
+
+	return nil
+}
+
+func (b *rodPooledBrowser) disconnected() <-chan struct{} {
+	return b.done
+}
+
+func (b *rodPooledBrowser) close() {
+	_ = b.browser.Close()
+	b.launcher.Kill()
+	b.launcher.Cleanup()
+}
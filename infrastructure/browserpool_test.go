@@ -0,0 +1,244 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"github.com/go-rod/rod"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakePooledBrowser struct {
+	mu       sync.Mutex
+	checkErr error
+	closed   bool
+	done     chan struct{}
+	once     sync.Once
+}
+
+func newFakePooledBrowser() *fakePooledBrowser {
+	return &fakePooledBrowser{done: make(chan struct{})}
+}
+
+func (b *fakePooledBrowser) rod() *rod.Browser {
+	return nil
+}
+
+func (b *fakePooledBrowser) check(context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.checkErr
+}
+
+func (b *fakePooledBrowser) disconnected() <-chan struct{} {
+	return b.done
+}
+
+func (b *fakePooledBrowser) close() {
+	b.mu.Lock()
+	b.closed = true
+	b.mu.Unlock()
+	b.disconnect()
+}
+
+func (b *fakePooledBrowser) disconnect() {
+	b.once.Do(
+		func() {
+			close(b.done)
+		},
+	)
+}
+
+func (b *fakePooledBrowser) isClosed() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.closed
+}
+
+type fakeBrowserLauncher struct {
+	mu       sync.Mutex
+	launched []*fakePooledBrowser
+	err      error
+}
+
+func (l *fakeBrowserLauncher) launch() (pooledBrowser, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.err != nil {
+		return nil, l.err
+	}
+
+	browser := newFakePooledBrowser()
+	l.launched = append(l.launched, browser)
+
+	return browser, nil
+}
+
+func (l *fakeBrowserLauncher) browsers() []*fakePooledBrowser {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return append([]*fakePooledBrowser(nil), l.launched...)
+}
+
+func TestBrowserPool_Acquire(t *testing.T) {
+	t.Run(
+		"browsers are launched on first use", func(t *testing.T) {
+			launcher := &fakeBrowserLauncher{}
+			pool := newBrowserPool(launcher.launch, 2, 2, 0)
+			defer pool.Close()
+
+			assert.Empty(t, launcher.browsers())
+
+			first, err := pool.Acquire(context.Background())
+			require.NoError(t, err)
+			second, err := pool.Acquire(context.Background())
+			require.NoError(t, err)
+
+			assert.Len(t, launcher.browsers(), 2)
+			assert.NotSame(t, first.instance, second.instance)
+			assert.Equal(t, BrowserPoolStats{Browsers: 2, PagesInUse: 2, PagesServed: 2, Launches: 2}, pool.Stats())
+
+			first.Release()
+			second.Release()
+			assert.Equal(t, 0, pool.Stats().PagesInUse)
+		},
+	)
+
+	t.Run(
+		"waits for free tab", func(t *testing.T) {
+			launcher := &fakeBrowserLauncher{}
+			pool := newBrowserPool(launcher.launch, 1, 1, 0)
+			defer pool.Close()
+
+			lease, err := pool.Acquire(context.Background())
+			require.NoError(t, err)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+			_, err = pool.Acquire(ctx)
+			require.ErrorIs(t, err, context.DeadlineExceeded)
+
+			lease.Release()
+			lease, err = pool.Acquire(context.Background())
+			require.NoError(t, err)
+			lease.Release()
+		},
+	)
+
+	t.Run(
+		"browser recycled after max pages", func(t *testing.T) {
+			launcher := &fakeBrowserLauncher{}
+			pool := newBrowserPool(launcher.launch, 1, 2, 2)
+			defer pool.Close()
+
+			first, err := pool.Acquire(context.Background())
+			require.NoError(t, err)
+			second, err := pool.Acquire(context.Background())
+			require.NoError(t, err)
+			second.Release()
+
+			third, err := pool.Acquire(context.Background())
+			require.NoError(t, err)
+
+			browsers := launcher.browsers()
+			require.Len(t, browsers, 2)
+			assert.Same(t, browsers[1], third.instance.browser)
+			assert.False(t, browsers[0].isClosed(), "browser rendering page must not be closed")
+
+			first.Release()
+			assert.Eventually(t, browsers[0].isClosed, time.Second, time.Millisecond)
+			third.Release()
+		},
+	)
+
+	t.Run(
+		"launch failure", func(t *testing.T) {
+			launcher := &fakeBrowserLauncher{err: errors.New("no browser")}
+			pool := newBrowserPool(launcher.launch, 1, 1, 0)
+			defer pool.Close()
+
+			_, err := pool.Acquire(context.Background())
+			require.Error(t, err)
+
+			launcher.mu.Lock()
+			launcher.err = nil
+			launcher.mu.Unlock()
+
+			lease, err := pool.Acquire(context.Background())
+			require.NoError(t, err)
+			lease.Release()
+			assert.EqualValues(t, 1, pool.Stats().LaunchFailures)
+		},
+	)
+
+	t.Run(
+		"closed pool", func(t *testing.T) {
+			pool := newBrowserPool((&fakeBrowserLauncher{}).launch, 1, 1, 0)
+			pool.Close()
+
+			_, err := pool.Acquire(context.Background())
+			require.ErrorIs(t, err, errBrowserPoolClosed)
+		},
+	)
+}
+
+func TestBrowserPool_Relaunch(t *testing.T) {
+	t.Run(
+		"disconnected browser", func(t *testing.T) {
+			launcher := &fakeBrowserLauncher{}
+			pool := newBrowserPool(launcher.launch, 1, 1, 0)
+			defer pool.Close()
+
+			lease, err := pool.Acquire(context.Background())
+			require.NoError(t, err)
+			lease.Release()
+
+			launcher.browsers()[0].disconnect()
+
+			assert.Eventually(
+				t, func() bool {
+					return len(launcher.browsers()) == 2
+				}, time.Second, time.Millisecond,
+			)
+			assert.Equal(t, 1, pool.Stats().Browsers)
+		},
+	)
+
+	t.Run(
+		"unhealthy browser", func(t *testing.T) {
+			launcher := &fakeBrowserLauncher{}
+			pool := newBrowserPool(launcher.launch, 1, 1, 0)
+			defer pool.Close()
+
+			lease, err := pool.Acquire(context.Background())
+			require.NoError(t, err)
+			lease.Release()
+
+			pool.checkHealth(context.Background())
+			assert.Len(t, launcher.browsers(), 1)
+
+			unhealthy := launcher.browsers()[0]
+			unhealthy.mu.Lock()
+			unhealthy.checkErr = errors.New("not responding")
+			unhealthy.mu.Unlock()
+
+			pool.checkHealth(context.Background())
+
+			browsers := launcher.browsers()
+			require.Len(t, browsers, 2)
+			assert.Eventually(t, unhealthy.isClosed, time.Second, time.Millisecond)
+
+			lease, err = pool.Acquire(context.Background())
+			require.NoError(t, err)
+			assert.Same(t, browsers[1], lease.instance.browser)
+			lease.Release()
+		},
+	)
+}
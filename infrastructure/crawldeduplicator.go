@@ -0,0 +1,157 @@
+package infrastructure
+
+import (
+	"context"
+	"go-web-crawler-service/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"sync"
+	"time"
+)
+
+type mongoCrawlDeduplicator struct {
+	db     *mongo.Database
+	window time.Duration
+}
+
+// NewMongoCrawlDeduplicator creates deduplicator reading the jobs, so the urls scheduled by any API instance
+// are found. Urls which jobs failed could be scheduled again right away, urls which jobs are queued with lower
+// priority could be scheduled again with the higher one.
+func NewMongoCrawlDeduplicator(db *mongo.Database, window time.Duration) *mongoCrawlDeduplicator {
+	return &mongoCrawlDeduplicator{
+		db:     db,
+		window: window,
+	}
+}
+
+func (d *mongoCrawlDeduplicator) FindScheduled(
+	ctx context.Context,
+	locale domain.Locale,
+	priority domain.Priority,
+	urls []domain.Url,
+) (map[domain.Url]domain.JobID, error) {
+	priorities := bson.A{}
+	for _, jobPriority := range priority.AtLeast() {
+		priorities = append(priorities, jobPriority)
+		// Jobs created before the priority lanes have no priority, they waited in the normal lane
+		if jobPriority == domain.PriorityNormal {
+			priorities = append(priorities, nil)
+		}
+	}
+
+	cursor, err := d.db.Collection(jobCollection).Find(
+		ctx,
+		bson.M{
+			"url":       bson.M{"$in": urls},
+			"locale":    locale,
+			"status":    bson.M{"$ne": domain.JobStatusFailed},
+			"createdAt": bson.M{"$gte": time.Now().Add(-d.window)},
+			"$or": bson.A{
+				bson.M{"status": bson.M{"$ne": domain.JobStatusQueued}},
+				bson.M{"priority": bson.M{"$in": priorities}},
+			},
+		},
+		options.Find().
+			SetSort(bson.D{{Key: "createdAt", Value: -1}}).
+			SetProjection(bson.M{"_id": 1, "url": 1}),
+	)
+	if err != nil {
+		return nil, storageError("failed to find scheduled jobs, error: %w", err)
+	}
+
+	var dtos []jobMongoDTO
+	err = cursor.All(ctx, &dtos)
+	if err != nil {
+		return nil, storageError("failed to decode scheduled jobs, error: %w", err)
+	}
+
+	// Jobs are sorted from the newest one, so the latest job of the url is kept
+	scheduled := make(map[domain.Url]domain.JobID, len(dtos))
+	for _, dto := range dtos {
+		if _, found := scheduled[domain.Url(dto.Url)]; !found {
+			scheduled[domain.Url(dto.Url)] = domain.JobID(dto.ID)
+		}
+	}
+
+	return scheduled, nil
+}
+
+// Remember does nothing as the jobs of the scheduled requests are already stored
+func (d *mongoCrawlDeduplicator) Remember(context.Context, []domain.CrawlRequest) error {
+	return nil
+}
+
+type scheduledCrawl struct {
+	jobID       domain.JobID
+	priority    domain.Priority
+	scheduledAt time.Time
+}
+
+type memoryCrawlDeduplicator struct {
+	window time.Duration
+	now    func() time.Time
+
+	mu        sync.Mutex
+	scheduled map[domain.CrawlTarget]scheduledCrawl
+	evictedAt time.Time
+}
+
+// NewMemoryCrawlDeduplicator creates deduplicator keeping the urls scheduled within the window in memory,
+// every API instance deduplicates only the urls submitted to it. The jobs are not read, so the url requested
+// with higher priority than it was scheduled with is scheduled again even when it was already crawled.
+func NewMemoryCrawlDeduplicator(window time.Duration) *memoryCrawlDeduplicator {
+	return &memoryCrawlDeduplicator{
+		window:    window,
+		now:       time.Now,
+		scheduled: map[domain.CrawlTarget]scheduledCrawl{},
+	}
+}
+
+func (d *memoryCrawlDeduplicator) FindScheduled(
+	_ context.Context,
+	locale domain.Locale,
+	priority domain.Priority,
+	urls []domain.Url,
+) (map[domain.Url]domain.JobID, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	since := d.now().Add(-d.window)
+	scheduled := make(map[domain.Url]domain.JobID)
+	for _, url := range urls {
+		crawl, found := d.scheduled[domain.CrawlTarget{Url: url, Locale: locale}]
+		if found && crawl.scheduledAt.After(since) && !priority.Outranks(crawl.priority) {
+			scheduled[url] = crawl.jobID
+		}
+	}
+
+	return scheduled, nil
+}
+
+func (d *memoryCrawlDeduplicator) Remember(_ context.Context, requests []domain.CrawlRequest) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.now()
+	if now.Sub(d.evictedAt) >= d.window {
+		d.evict(now.Add(-d.window))
+		d.evictedAt = now
+	}
+	for _, request := range requests {
+		target := domain.CrawlTarget{Url: request.Url, Locale: request.Locale}
+		d.scheduled[target] = scheduledCrawl{jobID: request.JobID, priority: request.Priority, scheduledAt: now}
+	}
+
+	return nil
+}
+
+// evict forgets the urls scheduled before the window, it's done once per window, so the set holds the urls
+// of two windows at most
+func (d *memoryCrawlDeduplicator) evict(since time.Time) {
+	for target, crawl := range d.scheduled {
+		if !crawl.scheduledAt.After(since) {
+			delete(d.scheduled, target)
+		}
+	}
+}
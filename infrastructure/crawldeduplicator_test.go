@@ -0,0 +1,121 @@
+package infrastructure
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go-web-crawler-service/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+	"testing"
+	"time"
+)
+
+func TestMongoCrawlDeduplicator_FindScheduled(t *testing.T) {
+	options := mtest.NewOptions().ClientType(mtest.Mock).CollectionName(jobCollection)
+	mt := mtest.New(t, options)
+	defer mt.Close()
+
+	mt.Run(
+		"latest job of the url", func(t *mtest.T) {
+			namespace := t.DB.Name() + "." + jobCollection
+			t.AddMockResponses(
+				mtest.CreateCursorResponse(
+					0, namespace, mtest.FirstBatch,
+					bson.D{{Key: "_id", Value: "newer"}, {Key: "url", Value: string(testRepoChannelURL)}},
+					bson.D{{Key: "_id", Value: "older"}, {Key: "url", Value: string(testRepoChannelURL)}},
+				),
+			)
+
+			deduplicator := NewMongoCrawlDeduplicator(t.DB, time.Hour)
+			scheduled, err := deduplicator.FindScheduled(
+				context.Background(),
+				"en-GB",
+				domain.PriorityHigh,
+				[]domain.Url{testRepoChannelURL, "https://google.com/other"},
+			)
+
+			require.NoError(t, err)
+			assert.Equal(t, map[domain.Url]domain.JobID{testRepoChannelURL: "newer"}, scheduled)
+
+			findEvent := t.GetStartedEvent()
+			require.NotNil(t, findEvent)
+			assert.Equal(
+				t,
+				string(domain.JobStatusFailed),
+				findEvent.Command.Lookup("filter", "status", "$ne").StringValue(),
+			)
+			assert.Equal(t, "en-GB", findEvent.Command.Lookup("filter", "locale").StringValue())
+
+			// Job queued with lower priority than the high one is not a duplicate
+			conditions := findEvent.Command.Lookup("filter", "$or").Array()
+			assert.Equal(
+				t,
+				string(domain.JobStatusQueued),
+				conditions.Index(0).Value().Document().Lookup("status", "$ne").StringValue(),
+			)
+			priorities := conditions.Index(1).Value().Document().Lookup("priority", "$in").Array()
+			values, err := priorities.Values()
+			require.NoError(t, err)
+			require.Len(t, values, 1)
+			assert.Equal(t, string(domain.PriorityHigh), values[0].StringValue())
+		},
+	)
+
+	mt.Run(
+		"storage error", func(t *mtest.T) {
+			t.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "failure"}))
+
+			deduplicator := NewMongoCrawlDeduplicator(t.DB, time.Hour)
+			_, err := deduplicator.FindScheduled(
+				context.Background(),
+				"",
+				domain.PriorityNormal,
+				[]domain.Url{testRepoChannelURL},
+			)
+
+			assert.Equal(t, domain.CrawlErrorStorage, domain.CrawlErrorKindOf(err))
+		},
+	)
+}
+
+func TestMemoryCrawlDeduplicator(t *testing.T) {
+	now := time.Date(2022, 4, 1, 12, 0, 0, 0, time.UTC)
+	deduplicator := NewMemoryCrawlDeduplicator(time.Hour)
+	deduplicator.now = func() time.Time {
+		return now
+	}
+	ctx := context.Background()
+
+	first := domain.NewCrawlRequest("first", testRepoChannelURL, "", domain.PriorityNormal)
+	err := deduplicator.Remember(ctx, []domain.CrawlRequest{*first})
+	require.NoError(t, err)
+
+	now = now.Add(30 * time.Minute)
+	urls := []domain.Url{testRepoChannelURL, "https://google.com/other"}
+	scheduled, err := deduplicator.FindScheduled(ctx, "", domain.PriorityNormal, urls)
+	require.NoError(t, err)
+	assert.Equal(t, map[domain.Url]domain.JobID{testRepoChannelURL: "first"}, scheduled)
+
+	scheduled, err = deduplicator.FindScheduled(ctx, "", domain.PriorityLow, urls)
+	require.NoError(t, err)
+	assert.Equal(t, map[domain.Url]domain.JobID{testRepoChannelURL: "first"}, scheduled)
+
+	scheduled, err = deduplicator.FindScheduled(ctx, "", domain.PriorityHigh, urls)
+	require.NoError(t, err)
+	assert.Empty(t, scheduled, "url requested with higher priority is not a duplicate")
+
+	scheduled, err = deduplicator.FindScheduled(ctx, "en-GB", domain.PriorityNormal, []domain.Url{testRepoChannelURL})
+	require.NoError(t, err)
+	assert.Empty(t, scheduled, "url scheduled in other locale is not a duplicate")
+
+	now = now.Add(time.Hour)
+	scheduled, err = deduplicator.FindScheduled(ctx, "", domain.PriorityNormal, []domain.Url{testRepoChannelURL})
+	require.NoError(t, err)
+	assert.Empty(t, scheduled)
+
+	second := domain.NewCrawlRequest("second", "https://google.com/other", "", domain.PriorityNormal)
+	err = deduplicator.Remember(ctx, []domain.CrawlRequest{*second})
+	require.NoError(t, err)
+	assert.Len(t, deduplicator.scheduled, 1, "urls scheduled before the window must be evicted")
+}
@@ -0,0 +1,474 @@
+package infrastructure
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"go-web-crawler-service/domain"
+	"go-web-crawler-service/metrics"
+	"go-web-crawler-service/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gopkg.in/yaml.v3"
+	"log"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	fieldApplicationName = "applicationName"
+	fieldRating          = "rating"
+	fieldNumberOfRatings = "numberOfRatings"
+	fieldDeveloper       = "developer"
+	fieldCategory        = "category"
+	fieldPrice           = "price"
+	fieldDescription     = "description"
+	fieldContentRating   = "contentRating"
+
+	fieldTypeString = "string"
+	fieldTypeFloat  = "float"
+	fieldTypeInt    = "int"
+
+	defaultProfileRoot = "body"
+	// fieldRoot labels extraction failures of the element the fields are looked up in
+	fieldRoot = "root"
+
+	// detailLookupTimeout is the time a missing detail element is waited for, every detail is waited for
+	// separately, so the missing one does not use up the time of the details extracted after it
+	detailLookupTimeout = 500 * time.Millisecond
+)
+
+//go:embed profiles/roku.yaml
+var defaultExtractionProfile []byte
+
+// localeSegmentPattern matches the locale path segment, e.g. en-gb
+var localeSegmentPattern = regexp.MustCompile(`^(?i)[a-z]{2,3}-[a-z]{2}$`)
+
+// channelFields are the fields every profile has to define, in the order they are extracted
+var channelFields = []string{fieldApplicationName, fieldRating, fieldNumberOfRatings}
+
+// starDistributionFields are amounts of 1 to 5 star ratings
+var starDistributionFields = []string{"stars1", "stars2", "stars3", "stars4", "stars5"}
+
+// detailFields are the optional fields profile could define, in the order they are extracted
+var detailFields = append(
+	[]string{fieldDeveloper, fieldCategory, fieldPrice, fieldDescription, fieldContentRating},
+	starDistributionFields...,
+)
+
+// channelFieldTypes are types the channel and detail fields have to be coerced to
+var channelFieldTypes = map[string]string{
+	fieldApplicationName: fieldTypeString,
+	fieldRating:          fieldTypeFloat,
+	fieldNumberOfRatings: fieldTypeInt,
+	fieldDeveloper:       fieldTypeString,
+	fieldCategory:        fieldTypeString,
+	fieldPrice:           fieldTypeString,
+	fieldDescription:     fieldTypeString,
+	fieldContentRating:   fieldTypeString,
+}
+
+func init() {
+	for _, name := range starDistributionFields {
+		channelFieldTypes[name] = fieldTypeInt
+	}
+}
+
+// fieldRule describes how a single channel field is extracted from the page. Value is read from the text
+// of the element (or only from its own text nodes, or from the attribute), optionally narrowed down
+// by the regex (the first capturing group if the regex has one) and coerced to the type.
+// Missing element of the required field fails the crawl, otherwise the default is used, the default is also
+// used when the value is empty or the regex does not match. Optional number without the default is left empty.
+type fieldRule struct {
+	Selector string `yaml:"selector"`
+	// Page makes the element looked up in the whole page instead of the root element
+	Page      bool   `yaml:"page"`
+	Attribute string `yaml:"attribute"`
+	OwnText   bool   `yaml:"ownText"`
+	Regex     string `yaml:"regex"`
+	Type      string `yaml:"type"`
+	Required  bool   `yaml:"required"`
+	Default   string `yaml:"default"`
+
+	regexp *regexp.Regexp
+}
+
+// ExtractionProfile is a versioned set of rules describing how the channel is extracted from the details page
+type ExtractionProfile struct {
+	Version string `yaml:"version"`
+	// Root is a selector of the element the fields are looked up in, crawlers wait for it to be rendered
+	Root string `yaml:"root"`
+	// JSONLD makes crawlers reading server rendered pages prefer application described by JSON-LD script
+	JSONLD bool `yaml:"jsonLD"`
+	// LocalePath makes the page crawled in the locale prefixed by the lowercase locale, e.g. /en-gb/details/<id>
+	LocalePath bool `yaml:"localePath"`
+	// BlockedSelector matches the page shown instead of the details page when the crawler is blocked, e.g. captcha
+	BlockedSelector string `yaml:"blockedSelector"`
+	// NotFoundSelector matches the page shown when the channel does not exist
+	NotFoundSelector string                `yaml:"notFoundSelector"`
+	Fields           map[string]*fieldRule `yaml:"fields"`
+}
+
+// NewExtractionProfile parses the profile from YAML (or JSON, which is valid YAML as well) and validates it
+func NewExtractionProfile(data []byte) (*ExtractionProfile, error) {
+	var profile ExtractionProfile
+	err := yaml.Unmarshal(data, &profile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse extraction profile, error: %w", err)
+	}
+
+	err = profile.validate()
+	if err != nil {
+		return nil, fmt.Errorf("extraction profile %s is invalid, error: %w", profile.Version, err)
+	}
+
+	return &profile, nil
+}
+
+// LoadExtractionProfile reads the profile from the file
+func LoadExtractionProfile(path string) (*ExtractionProfile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read extraction profile %s, error: %w", path, err)
+	}
+
+	return NewExtractionProfile(data)
+}
+
+// DefaultExtractionProfile returns the profile of the Roku channel store built into the binary
+func DefaultExtractionProfile() (*ExtractionProfile, error) {
+	return NewExtractionProfile(defaultExtractionProfile)
+}
+
+func (p *ExtractionProfile) validate() error {
+	if p.Version == "" {
+		return errors.New("version is required")
+	}
+
+	if p.Root == "" {
+		p.Root = defaultProfileRoot
+	}
+
+	for _, name := range channelFields {
+		if rule, found := p.Fields[name]; !found || rule == nil {
+			return fmt.Errorf("field %s is required", name)
+		}
+	}
+
+	for name, rule := range p.Fields {
+		if _, found := channelFieldTypes[name]; !found {
+			return fmt.Errorf("unknown field %s", name)
+		}
+
+		if rule == nil {
+			return fmt.Errorf("field %s has no rule", name)
+		}
+
+		if rule.Selector == "" {
+			return fmt.Errorf("field %s has no selector", name)
+		}
+
+		if rule.Type != channelFieldTypes[name] {
+			return fmt.Errorf("field %s has to be of type %s, got: %s", name, channelFieldTypes[name], rule.Type)
+		}
+
+		if rule.Regex != "" {
+			compiled, err := regexp.Compile(rule.Regex)
+			if err != nil {
+				return fmt.Errorf("field %s has invalid regex, error: %w", name, err)
+			}
+			rule.regexp = compiled
+		}
+	}
+
+	return nil
+}
+
+// localizedUrl returns url of the page in the locale. The locale the url was submitted with is replaced,
+// so the page is crawled in the requested locale only. Url is kept as it is for the store default locale
+// and for the stores telling the locale by the Accept-Language header only.
+func (p *ExtractionProfile) localizedUrl(channelUrl domain.Url, locale domain.Locale) domain.Url {
+	if !p.LocalePath || locale == "" {
+		return channelUrl
+	}
+
+	parsedUrl, err := url.Parse(string(channelUrl))
+	if err != nil {
+		return channelUrl
+	}
+
+	var segments []string
+	if path := strings.Trim(parsedUrl.Path, "/"); path != "" {
+		segments = strings.Split(path, "/")
+	}
+	if len(segments) > 0 && localeSegmentPattern.MatchString(segments[0]) {
+		segments = segments[1:]
+	}
+
+	parsedUrl.Path = "/" + strings.Join(append([]string{strings.ToLower(string(locale))}, segments...), "/")
+	parsedUrl.RawPath = ""
+
+	return domain.Url(parsedUrl.String())
+}
+
+// extractionNode is an element of the crawled page the profile rules are evaluated on
+type extractionNode interface {
+	// find returns the first matching descendant or domain.ErrElementNotFound
+	find(ctx context.Context, selector string) (extractionNode, error)
+	text() (string, error)
+	ownText() (string, error)
+	// attribute returns empty value when the element has no such attribute
+	attribute(name string) (string, error)
+}
+
+// extractChannel evaluates the rules of the profile on the root element of the page, rules of the page fields
+// are evaluated on the whole page
+func (p *ExtractionProfile) extractChannel(
+	ctx context.Context,
+	url domain.Url,
+	page extractionNode,
+	root extractionNode,
+) (*domain.Channel, error) {
+	values := make(map[string]interface{}, len(channelFields))
+	for _, name := range channelFields {
+		value, err := p.extractField(ctx, name, page, root)
+		if err != nil {
+			metrics.ExtractionFailures.WithLabelValues(name).Inc()
+			return nil, fmt.Errorf("failed to get %s, %w", name, err)
+		}
+		values[name] = value
+	}
+
+	rating, _ := values[fieldRating].(float64)
+	numberOfRatings, _ := values[fieldNumberOfRatings].(uint64)
+	channel, err := createChannel(
+		url,
+		stringValue(values[fieldApplicationName]),
+		float32(rating),
+		uint32(numberOfRatings),
+		p.Version,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create channel entity from scrapped data, error: %w", err)
+	}
+
+	err = p.extractDetails(ctx, channel, page, root)
+	if err != nil {
+		return nil, err
+	}
+
+	return channel, nil
+}
+
+// extractDetails sets the optional details of the channel, detail that could not be extracted is left empty
+// unless its rule is required
+func (p *ExtractionProfile) extractDetails(
+	ctx context.Context,
+	channel *domain.Channel,
+	page extractionNode,
+	root extractionNode,
+) error {
+	values := make(map[string]interface{}, len(detailFields))
+	for _, name := range detailFields {
+		rule, found := p.Fields[name]
+		if !found {
+			continue
+		}
+
+		value, err := p.extractDetail(ctx, name, page, root)
+		if err != nil {
+			metrics.ExtractionFailures.WithLabelValues(name).Inc()
+			if rule.Required {
+				return fmt.Errorf("failed to get %s, %w", name, err)
+			}
+
+			log.Printf("Skipping %s of channel %s, error: %v\n", name, channel.Url, err)
+			continue
+		}
+		values[name] = value
+	}
+
+	channel.Developer = domain.Developer(stringValue(values[fieldDeveloper]))
+	channel.Category = domain.Category(stringValue(values[fieldCategory]))
+	channel.Price = domain.Price(stringValue(values[fieldPrice]))
+	channel.Description = domain.Description(stringValue(values[fieldDescription]))
+	channel.ContentRating = domain.ContentRating(stringValue(values[fieldContentRating]))
+
+	// Distribution is set only when the page shows all the star amounts
+	amounts := make([]uint32, 0, len(starDistributionFields))
+	for _, name := range starDistributionFields {
+		amount, found := values[name].(uint64)
+		if !found {
+			return nil
+		}
+		amounts = append(amounts, uint32(amount))
+	}
+
+	distribution, err := domain.NewStarDistribution(amounts)
+	if err != nil {
+		return domain.NewCrawlError(domain.CrawlErrorInvalidData, err)
+	}
+	channel.StarDistribution = distribution
+
+	return nil
+}
+
+// extractDetail evaluates rule of the detail waiting for its element for the detail lookup timeout, element that
+// is not found in time is reported as domain.ErrElementNotFound unless the whole crawl timed out
+func (p *ExtractionProfile) extractDetail(
+	ctx context.Context,
+	name string,
+	page extractionNode,
+	root extractionNode,
+) (interface{}, error) {
+	detailCtx, cancel := context.WithTimeout(ctx, detailLookupTimeout)
+	defer cancel()
+
+	value, err := p.extractField(detailCtx, name, page, root)
+	if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+		return nil, fmt.Errorf("%w: %s", domain.ErrElementNotFound, p.Fields[name].Selector)
+	}
+
+	return value, err
+}
+
+// extractField evaluates rule of the field on the root element or the whole page
+func (p *ExtractionProfile) extractField(
+	ctx context.Context,
+	name string,
+	page extractionNode,
+	root extractionNode,
+) (interface{}, error) {
+	rule := p.Fields[name]
+	ctx, span := tracing.Tracer().Start(
+		ctx,
+		"extract "+name,
+		trace.WithAttributes(
+			attribute.String("selector", rule.Selector),
+			attribute.String("profile", p.Version),
+		),
+	)
+
+	node := root
+	if rule.Page {
+		node = page
+	}
+
+	value, err := rule.evaluate(ctx, node)
+	tracing.End(span, err)
+
+	return value, err
+}
+
+// stringValue returns the extracted string value, missing value is empty
+func stringValue(value interface{}) string {
+	stringValue, _ := value.(string)
+	return stringValue
+}
+
+// missingRootError classifies the page without the root element, blocked and not found pages do not have it,
+// otherwise the markup has changed
+func (p *ExtractionProfile) missingRootError(ctx context.Context, page extractionNode, err error) error {
+	if p.BlockedSelector != "" {
+		_, findErr := page.find(ctx, p.BlockedSelector)
+		if findErr == nil {
+			return domain.NewCrawlError(domain.CrawlErrorBlocked, fmt.Errorf("blocked page: %s", p.BlockedSelector))
+		}
+	}
+
+	if p.NotFoundSelector != "" {
+		_, findErr := page.find(ctx, p.NotFoundSelector)
+		if findErr == nil {
+			return domain.NewCrawlError(domain.CrawlErrorNotFound, fmt.Errorf("not found page: %s", p.NotFoundSelector))
+		}
+	}
+
+	return domain.NewCrawlError(domain.CrawlErrorMarkupChanged, err)
+}
+
+func (r *fieldRule) evaluate(ctx context.Context, root extractionNode) (interface{}, error) {
+	value, err := r.read(ctx, root)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.regexp != nil {
+		match := r.regexp.FindStringSubmatch(value)
+		switch {
+		case match == nil:
+			value = ""
+		case len(match) > 1:
+			value = match[1]
+		default:
+			value = match[0]
+		}
+	}
+
+	if value == "" {
+		value = r.Default
+	}
+
+	// Missing optional number is left empty, so it could be told apart from zero
+	if value == "" && !r.Required && r.Type != fieldTypeString {
+		return nil, nil
+	}
+
+	return r.coerce(value)
+}
+
+func (r *fieldRule) read(ctx context.Context, root extractionNode) (string, error) {
+	element, err := root.find(ctx, r.Selector)
+	if errors.Is(err, domain.ErrElementNotFound) && !r.Required {
+		return r.Default, nil
+	} else if err != nil {
+		return "", err
+	}
+
+	var value string
+	switch {
+	case r.Attribute != "":
+		value, err = element.attribute(r.Attribute)
+	case r.OwnText:
+		value, err = element.ownText()
+	default:
+		value, err = element.text()
+	}
+	if err != nil {
+		return "", err
+	}
+
+	// Server rendered markup keeps the source formatting, so the whitespace is collapsed as the browser would do
+	return strings.Join(strings.Fields(value), " "), nil
+}
+
+func (r *fieldRule) coerce(value string) (interface{}, error) {
+	switch r.Type {
+	case fieldTypeFloat:
+		floatValue, err := strconv.ParseFloat(value, 32)
+		if err != nil {
+			return nil, domain.NewCrawlError(
+				domain.CrawlErrorInvalidData,
+				fmt.Errorf("failed to create float value, value: %s, error: %w", value, err),
+			)
+		}
+
+		return floatValue, nil
+	case fieldTypeInt:
+		intValue, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return nil, domain.NewCrawlError(
+				domain.CrawlErrorInvalidData,
+				fmt.Errorf("failed to create integer value, value: %s, error: %w", value, err),
+			)
+		}
+
+		return intValue, nil
+	default:
+		return value, nil
+	}
+}
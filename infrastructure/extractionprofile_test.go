@@ -0,0 +1,214 @@
+package infrastructure
+
+import (
+	"context"
+	"github.com/PuerkitoBio/goquery"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go-web-crawler-service/domain"
+	"strings"
+	"testing"
+)
+
+const (
+	testProfilePage = `<html><body><div class="app"><h1 class="app-name" data-name="Netflix">Movies</h1>
+<span class="score">Rated 4.5 of 5</span><span class="votes">120</span>
+<ul class="stars"><li>1</li><li>2</li><li>3</li><li>4</li><li>110</li></ul>
+</div><p class="developer">Netflix, Inc.</p></body></html>`
+	testProfile = `
+version: test-1
+root: .app
+fields:
+  applicationName:
+    selector: .app-name
+    attribute: data-name
+    type: string
+  rating:
+    selector: .score
+    regex: '(\d+\.\d+) of 5'
+    type: float
+    required: true
+  numberOfRatings:
+    selector: .missing
+    type: int
+    default: "7"
+  developer:
+    selector: .developer
+    page: true
+    type: string
+  price:
+    selector: .price
+    type: string
+  stars1:
+    selector: .stars li:nth-child(1)
+    type: int
+  stars2:
+    selector: .stars li:nth-child(2)
+    type: int
+  stars3:
+    selector: .stars li:nth-child(3)
+    type: int
+  stars4:
+    selector: .stars li:nth-child(4)
+    type: int
+  stars5:
+    selector: .stars li:nth-child(5)
+    type: int
+`
+)
+
+func TestDefaultExtractionProfile(t *testing.T) {
+	profile, err := DefaultExtractionProfile()
+
+	require.NoError(t, err)
+	assert.NotEmpty(t, profile.Version)
+	assert.Equal(t, ".Roku-Page-Details-Hero", profile.Root)
+}
+
+func TestNewExtractionProfile_Invalid_ReturnsError(t *testing.T) {
+	testCases := map[string]string{
+		"missing version": strings.Replace(testProfile, "version: test-1", "", 1),
+		"unknown field":   testProfile + "  publisher:\n    selector: .publisher\n    type: string\n",
+		"invalid type":    strings.Replace(testProfile, "type: float", "type: string", 1),
+		"invalid detail":  strings.Replace(testProfile, "type: string\n  price", "type: int\n  price", 1),
+		"invalid regex":   strings.Replace(testProfile, `'(\d+\.\d+) of 5'`, `'(\d+'`, 1),
+		"missing field":   strings.Split(testProfile, "  numberOfRatings:")[0],
+	}
+
+	for name, data := range testCases {
+		t.Run(
+			name, func(t *testing.T) {
+				_, err := NewExtractionProfile([]byte(data))
+
+				require.Error(t, err)
+			},
+		)
+	}
+}
+
+func TestExtractionProfile_ExtractChannel(t *testing.T) {
+	profile, err := NewExtractionProfile([]byte(testProfile))
+	require.NoError(t, err)
+
+	document, err := goquery.NewDocumentFromReader(strings.NewReader(testProfilePage))
+	require.NoError(t, err)
+	url := domain.Url("https://channelstore.roku.com/details/12")
+
+	page := &goqueryExtractionNode{selection: document.Selection}
+
+	t.Run(
+		"fields extracted", func(t *testing.T) {
+			root := &goqueryExtractionNode{selection: document.Find(profile.Root)}
+
+			channel, err := profile.extractChannel(context.Background(), url, page, root)
+
+			require.NoError(t, err)
+			assert.EqualValues(t, "Netflix", channel.ApplicationName)
+			assert.EqualValues(t, 4.5, channel.Rating)
+			assert.EqualValues(t, 7, channel.NumberOfRatings)
+			assert.Equal(t, "test-1", channel.ProfileVersion)
+			assert.EqualValues(t, "Netflix, Inc.", channel.Developer)
+			assert.Empty(t, channel.Price)
+			require.NotNil(t, channel.StarDistribution)
+			assert.Equal(t, domain.StarDistribution{1, 2, 3, 4, 110}, *channel.StarDistribution)
+		},
+	)
+
+	t.Run(
+		"star distribution missing", func(t *testing.T) {
+			profile.Fields["stars5"].Selector = ".stars li:nth-child(6)"
+			defer func() {
+				profile.Fields["stars5"].Selector = ".stars li:nth-child(5)"
+			}()
+			root := &goqueryExtractionNode{selection: document.Find(profile.Root)}
+
+			channel, err := profile.extractChannel(context.Background(), url, page, root)
+
+			require.NoError(t, err)
+			assert.Nil(t, channel.StarDistribution)
+		},
+	)
+
+	t.Run(
+		"required element missing", func(t *testing.T) {
+			profile.Fields[fieldRating].Selector = ".rating"
+			root := &goqueryExtractionNode{selection: document.Find(profile.Root)}
+
+			_, err := profile.extractChannel(context.Background(), url, page, root)
+
+			require.ErrorIs(t, err, domain.ErrElementNotFound)
+		},
+	)
+}
+
+// renderingExtractionNode waits for the elements which are not rendered like the browser does
+type renderingExtractionNode struct {
+	extractionNode
+	missing map[string]bool
+}
+
+func (n *renderingExtractionNode) find(ctx context.Context, selector string) (extractionNode, error) {
+	if n.missing[selector] {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	return n.extractionNode.find(ctx, selector)
+}
+
+func TestExtractionProfile_ExtractChannel_MissingDetailsWaitedSeparately(t *testing.T) {
+	profile, err := NewExtractionProfile([]byte(testProfile))
+	require.NoError(t, err)
+
+	document, err := goquery.NewDocumentFromReader(strings.NewReader(testProfilePage))
+	require.NoError(t, err)
+	missing := map[string]bool{".developer": true, ".price": true}
+	page := &renderingExtractionNode{
+		extractionNode: &goqueryExtractionNode{selection: document.Selection},
+		missing:        missing,
+	}
+	root := &renderingExtractionNode{
+		extractionNode: &goqueryExtractionNode{selection: document.Find(profile.Root)},
+		missing:        missing,
+	}
+
+	channel, err := profile.extractChannel(context.Background(), "https://channelstore.roku.com/details/12", page, root)
+
+	require.NoError(t, err)
+	assert.Empty(t, channel.Developer)
+	require.NotNil(t, channel.StarDistribution, "details after the missing ones have to be extracted")
+	assert.Equal(t, domain.StarDistribution{1, 2, 3, 4, 110}, *channel.StarDistribution)
+}
+
+func TestExtractionProfile_LocalizedUrl(t *testing.T) {
+	profile := &ExtractionProfile{LocalePath: true}
+	testCases := []struct {
+		url      domain.Url
+		locale   domain.Locale
+		expected domain.Url
+	}{
+		{
+			url:      "https://channelstore.roku.com/details/12",
+			locale:   "en-GB",
+			expected: "https://channelstore.roku.com/en-gb/details/12",
+		},
+		{
+			url:      "https://channelstore.roku.com/fr-ca/details/12",
+			locale:   "de-DE",
+			expected: "https://channelstore.roku.com/de-de/details/12",
+		},
+		{
+			url:      "https://channelstore.roku.com/en-gb/details/12",
+			locale:   "",
+			expected: "https://channelstore.roku.com/en-gb/details/12",
+		},
+		{url: "https://channelstore.roku.com", locale: "en-GB", expected: "https://channelstore.roku.com/en-gb"},
+	}
+
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.expected, profile.localizedUrl(testCase.url, testCase.locale), testCase.url)
+	}
+
+	profile.LocalePath = false
+	assert.EqualValues(t, "https://example.com/12", profile.localizedUrl("https://example.com/12", "en-GB"))
+}
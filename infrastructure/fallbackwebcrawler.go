@@ -0,0 +1,46 @@
+package infrastructure
+
+import (
+	"context"
+	"go-web-crawler-service/domain"
+	"log"
+)
+
+type fallbackWebCrawler struct {
+	primary  domain.ChannelWebCrawler
+	fallback domain.ChannelWebCrawler
+}
+
+// NewFallbackWebCrawler creates crawler that uses the fallback crawler only when the primary one could not read
+// the page, so the cheap crawler could be tried before the expensive one. Classified failures like a missing or
+// blocked page are returned as they are, the fallback crawler would only repeat them.
+func NewFallbackWebCrawler(primary domain.ChannelWebCrawler, fallback domain.ChannelWebCrawler) *fallbackWebCrawler {
+	return &fallbackWebCrawler{
+		primary:  primary,
+		fallback: fallback,
+	}
+}
+
+func (c *fallbackWebCrawler) CrawlChannel(
+	ctx context.Context,
+	url domain.Url,
+	locale domain.Locale,
+) (*domain.Channel, error) {
+	channel, err := c.primary.CrawlChannel(ctx, url, locale)
+	if err == nil {
+		return channel, nil
+	}
+
+	if ctx.Err() != nil {
+		return nil, err
+	}
+
+	kind := domain.CrawlErrorKindOf(err)
+	if kind != domain.CrawlErrorUnknown && kind != domain.CrawlErrorMarkupChanged {
+		return nil, err
+	}
+
+	log.Printf("Primary crawler failed for url %s, using fallback crawler, error: %v\n", url, err)
+
+	return c.fallback.CrawlChannel(ctx, url, locale)
+}
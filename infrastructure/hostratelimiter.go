@@ -0,0 +1,307 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"go-web-crawler-service/domain"
+	"go-web-crawler-service/metrics"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+const (
+	hostRateCollection = "host_rate"
+	// hostRateRetention is the time after which the interval of the host that is not crawled anymore is forgotten
+	hostRateRetention = 24 * time.Hour
+
+	// backoffFactor slows the crawls of the overloaded host down
+	backoffFactor = 2
+	// recoveryFactor speeds the crawls of the host up after every crawl that did not overload it
+	recoveryFactor = 0.9
+	// slowLatencyFactor is how many times the crawl has to be slower than the average one to overload the host
+	slowLatencyFactor = 2
+	// latencySmoothing is a weight of the latest crawl in the average latency
+	latencySmoothing = 0.2
+	// minLatencySamples is an amount of crawls the average latency is known from
+	minLatencySamples = 5
+)
+
+// RateLimitPolicy bounds the interval between crawls of the same host. The interval starts at Interval, grows up to
+// MaxInterval while the host is overloaded and every crawl is delayed by random Jitter on top of it.
+type RateLimitPolicy struct {
+	Interval    time.Duration
+	MaxInterval time.Duration
+	Jitter      time.Duration
+}
+
+// bounded keeps the interval between Interval and MaxInterval of the policy
+func (p RateLimitPolicy) bounded(interval time.Duration) time.Duration {
+	if interval < p.Interval {
+		return p.Interval
+	}
+	if interval > p.MaxInterval {
+		return p.MaxInterval
+	}
+
+	return interval
+}
+
+// HostRateStore keeps the interval and the next free crawl slot of every host
+type HostRateStore interface {
+	// Reserve takes the next free slot of the host and returns the time it starts at
+	Reserve(ctx context.Context, host string, now time.Time) (time.Time, error)
+	// Adapt multiplies the interval of the host by the factor, the interval is bounded by the policy
+	Adapt(ctx context.Context, host string, factor float64) error
+}
+
+type hostLatency struct {
+	average time.Duration
+	samples int
+}
+
+type hostRateLimiter struct {
+	store  HostRateStore
+	policy RateLimitPolicy
+
+	mu        sync.Mutex
+	latencies map[string]hostLatency
+}
+
+// NewHostRateLimiter creates limiter spacing the crawls of the same host by the interval kept in the store
+func NewHostRateLimiter(store HostRateStore, policy RateLimitPolicy) *hostRateLimiter {
+	return &hostRateLimiter{
+		store:     store,
+		policy:    policy,
+		latencies: map[string]hostLatency{},
+	}
+}
+
+// Wait reserves the next free slot of the host and waits until it starts. When the slot could not be reserved,
+// the crawl is delayed by the initial interval instead of being stopped.
+func (l *hostRateLimiter) Wait(ctx context.Context, host string) error {
+	now := time.Now()
+	slot, err := l.store.Reserve(ctx, host, now)
+	if err != nil {
+		log.Printf("Could not reserve crawl of host %s, error: %v\n", host, err)
+		slot = now.Add(l.policy.Interval)
+	}
+
+	delay := slot.Sub(now)
+	if l.policy.Jitter > 0 {
+		delay += time.Duration(rand.Int63n(int64(l.policy.Jitter)))
+	}
+	metrics.RateLimitDelay.Observe(delay.Seconds())
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Observe backs off when the crawl failed because the host is overloaded or the crawl was much slower than usual,
+// every other successful crawl brings the interval back towards the initial one
+func (l *hostRateLimiter) Observe(ctx context.Context, host string, latency time.Duration, crawlErr error) {
+	slow := l.recordLatency(host, latency)
+
+	var factor float64
+	switch {
+	case crawlErr != nil && domain.CrawlErrorKindOf(crawlErr).IsThrottling(), slow:
+		factor = backoffFactor
+		metrics.RateLimitBackoffs.Inc()
+		log.Printf("Host %s is overloaded, slowing the crawls down, latency: %s, error: %v\n", host, latency, crawlErr)
+	case crawlErr == nil:
+		factor = recoveryFactor
+	default:
+		// Other failures, e.g. missing page, tell nothing about the load of the host
+		return
+	}
+
+	err := l.store.Adapt(ctx, host, factor)
+	if err != nil {
+		log.Printf("Could not adapt crawl interval of host %s, error: %v\n", host, err)
+	}
+}
+
+// recordLatency adds the latency to the average one of the host and tells whether the crawl was too slow
+func (l *hostRateLimiter) recordLatency(host string, latency time.Duration) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	hostLatency := l.latencies[host]
+	slow := hostLatency.samples >= minLatencySamples && latency > hostLatency.average*slowLatencyFactor
+
+	if hostLatency.samples == 0 {
+		hostLatency.average = latency
+	} else {
+		hostLatency.average += time.Duration(latencySmoothing * float64(latency-hostLatency.average))
+	}
+	hostLatency.samples++
+	l.latencies[host] = hostLatency
+
+	return slow
+}
+
+type hostRate struct {
+	interval time.Duration
+	nextAt   time.Time
+}
+
+type memoryHostRateStore struct {
+	policy RateLimitPolicy
+
+	mu    sync.Mutex
+	rates map[string]hostRate
+}
+
+// NewMemoryHostRateStore creates store keeping the intervals in memory, every worker spaces only its own crawls
+func NewMemoryHostRateStore(policy RateLimitPolicy) *memoryHostRateStore {
+	return &memoryHostRateStore{
+		policy: policy,
+		rates:  map[string]hostRate{},
+	}
+}
+
+func (s *memoryHostRateStore) Reserve(_ context.Context, host string, now time.Time) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rate := s.rate(host)
+	slot := rate.nextAt
+	if slot.Before(now) {
+		slot = now
+	}
+	rate.nextAt = slot.Add(rate.interval)
+	s.rates[host] = rate
+
+	return slot, nil
+}
+
+func (s *memoryHostRateStore) Adapt(_ context.Context, host string, factor float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rate := s.rate(host)
+	rate.interval = s.policy.bounded(time.Duration(float64(rate.interval) * factor))
+	s.rates[host] = rate
+
+	return nil
+}
+
+func (s *memoryHostRateStore) rate(host string) hostRate {
+	rate, found := s.rates[host]
+	if !found {
+		rate.interval = s.policy.Interval
+	}
+
+	return rate
+}
+
+type hostRateMongoDTO struct {
+	Host       string    `bson:"_id"`
+	IntervalMs int64     `bson:"intervalMs"`
+	SlotAt     time.Time `bson:"slotAt"`
+	NextAt     time.Time `bson:"nextAt"`
+}
+
+type mongoHostRateStore struct {
+	db     *mongo.Database
+	policy RateLimitPolicy
+}
+
+// NewMongoHostRateStore creates store keeping the intervals in the database, so the crawls of all the workers
+// are spaced together
+func NewMongoHostRateStore(db *mongo.Database, policy RateLimitPolicy) *mongoHostRateStore {
+	return &mongoHostRateStore{
+		db:     db,
+		policy: policy,
+	}
+}
+
+// EnsureIndexes creates TTL index, so the hosts that are not crawled anymore do not pile up forever
+func (s *mongoHostRateStore) EnsureIndexes(ctx context.Context) error {
+	_, err := s.getCollection().Indexes().CreateOne(
+		ctx,
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "nextAt", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(int32(hostRateRetention.Seconds())),
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create host rate index, error: %w", err)
+	}
+
+	return nil
+}
+
+// Reserve moves the next free slot of the host by its interval in a single update, so the workers reserving
+// the slot at the same time get the following slots
+func (s *mongoHostRateStore) Reserve(ctx context.Context, host string, now time.Time) (time.Time, error) {
+	upsert := true
+	after := options.After
+
+	var dto hostRateMongoDTO
+	err := s.getCollection().FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": host},
+		mongo.Pipeline{
+			{
+				{Key: "$set", Value: bson.M{
+					"intervalMs": bson.M{"$ifNull": bson.A{"$intervalMs", s.policy.Interval.Milliseconds()}},
+					"slotAt":     bson.M{"$max": bson.A{bson.M{"$ifNull": bson.A{"$nextAt", now}}, now}},
+				}},
+			},
+			{
+				{Key: "$set", Value: bson.M{"nextAt": bson.M{"$add": bson.A{"$slotAt", "$intervalMs"}}}},
+			},
+		},
+		&options.FindOneAndUpdateOptions{Upsert: &upsert, ReturnDocument: &after},
+	).Decode(&dto)
+	if err != nil {
+		return time.Time{}, storageError("failed to reserve crawl of host %s, error: %w", host, err)
+	}
+
+	return dto.SlotAt, nil
+}
+
+func (s *mongoHostRateStore) Adapt(ctx context.Context, host string, factor float64) error {
+	interval := bson.M{"$ifNull": bson.A{"$intervalMs", s.policy.Interval.Milliseconds()}}
+	_, err := s.getCollection().UpdateOne(
+		ctx,
+		bson.M{"_id": host},
+		mongo.Pipeline{
+			{
+				{Key: "$set", Value: bson.M{
+					"intervalMs": bson.M{"$toLong": bson.M{"$min": bson.A{
+						bson.M{"$max": bson.A{
+							bson.M{"$multiply": bson.A{interval, factor}},
+							s.policy.Interval.Milliseconds(),
+						}},
+						s.policy.MaxInterval.Milliseconds(),
+					}}},
+				}},
+			},
+		},
+	)
+	if err != nil {
+		return storageError("failed to adapt crawl interval of host %s, error: %w", host, err)
+	}
+
+	return nil
+}
+
+func (s *mongoHostRateStore) getCollection() *mongo.Collection {
+	return s.db.Collection(hostRateCollection)
+}
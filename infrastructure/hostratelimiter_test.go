@@ -0,0 +1,141 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go-web-crawler-service/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+	"testing"
+	"time"
+)
+
+var testRateLimitPolicy = RateLimitPolicy{Interval: time.Second, MaxInterval: 4 * time.Second}
+
+func TestMemoryHostRateStore_Reserve(t *testing.T) {
+	store := NewMemoryHostRateStore(testRateLimitPolicy)
+	now := time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	first, err := store.Reserve(context.Background(), "roku.com", now)
+	require.NoError(t, err)
+	second, err := store.Reserve(context.Background(), "roku.com", now)
+	require.NoError(t, err)
+	otherHost, err := store.Reserve(context.Background(), "apps.apple.com", now)
+	require.NoError(t, err)
+	afterPause, err := store.Reserve(context.Background(), "roku.com", now.Add(time.Minute))
+	require.NoError(t, err)
+
+	assert.Equal(t, now, first)
+	assert.Equal(t, now.Add(time.Second), second)
+	assert.Equal(t, now, otherHost)
+	assert.Equal(t, now.Add(time.Minute), afterPause)
+}
+
+func TestHostRateLimiter_Observe(t *testing.T) {
+	blockedErr := domain.NewCrawlError(domain.CrawlErrorBlocked, errors.New("too many requests"))
+	notFoundErr := domain.NewCrawlError(domain.CrawlErrorNotFound, errors.New("missing page"))
+
+	testCases := []struct {
+		name             string
+		crawlErrors      []error
+		expectedInterval time.Duration
+	}{
+		{name: "throttling failure backs off", crawlErrors: []error{blockedErr}, expectedInterval: 2 * time.Second},
+		{
+			name:             "back off is bounded",
+			crawlErrors:      []error{blockedErr, blockedErr, blockedErr},
+			expectedInterval: 4 * time.Second,
+		},
+		{
+			name:             "successful crawl recovers",
+			crawlErrors:      []error{blockedErr, nil},
+			expectedInterval: 1800 * time.Millisecond,
+		},
+		{name: "recovery is bounded", crawlErrors: []error{nil}, expectedInterval: time.Second},
+		{name: "other failure is ignored", crawlErrors: []error{notFoundErr}, expectedInterval: time.Second},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(
+			testCase.name, func(t *testing.T) {
+				store := NewMemoryHostRateStore(testRateLimitPolicy)
+				limiter := NewHostRateLimiter(store, testRateLimitPolicy)
+
+				for _, crawlErr := range testCase.crawlErrors {
+					limiter.Observe(context.Background(), "roku.com", time.Second, crawlErr)
+				}
+
+				assert.Equal(t, testCase.expectedInterval, store.rate("roku.com").interval)
+			},
+		)
+	}
+}
+
+func TestHostRateLimiter_Observe_BacksOffOnRisingLatency(t *testing.T) {
+	store := NewMemoryHostRateStore(testRateLimitPolicy)
+	limiter := NewHostRateLimiter(store, testRateLimitPolicy)
+
+	for i := 0; i < minLatencySamples; i++ {
+		limiter.Observe(context.Background(), "roku.com", time.Second, nil)
+	}
+	require.Equal(t, time.Second, store.rate("roku.com").interval)
+
+	limiter.Observe(context.Background(), "roku.com", 3*time.Second, nil)
+
+	assert.Equal(t, 2*time.Second, store.rate("roku.com").interval)
+}
+
+func TestHostRateLimiter_Wait_StopsWhenContextIsDone(t *testing.T) {
+	limiter := NewHostRateLimiter(NewMemoryHostRateStore(testRateLimitPolicy), testRateLimitPolicy)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	require.NoError(t, limiter.Wait(ctx, "roku.com"))
+
+	cancel()
+	assert.ErrorIs(t, limiter.Wait(ctx, "roku.com"), context.Canceled)
+}
+
+func TestMongoHostRateStore_Reserve(t *testing.T) {
+	options := mtest.NewOptions().ClientType(mtest.Mock).CollectionName(hostRateCollection)
+	mt := mtest.New(t, options)
+	defer mt.Close()
+
+	slotAt := time.Date(2022, 5, 1, 12, 0, 1, 0, time.UTC)
+
+	mt.Run(
+		"slot reserved", func(t *mtest.T) {
+			t.AddMockResponses(
+				mtest.CreateSuccessResponse(
+					bson.E{Key: "value", Value: bson.M{
+						"_id":        "roku.com",
+						"intervalMs": 1000,
+						"slotAt":     slotAt,
+						"nextAt":     slotAt.Add(time.Second),
+					}},
+				),
+			)
+
+			store := NewMongoHostRateStore(t.DB, testRateLimitPolicy)
+			slot, err := store.Reserve(context.Background(), "roku.com", slotAt.Add(-time.Second))
+
+			require.NoError(t, err)
+			assert.Equal(t, slotAt, slot.UTC())
+		},
+	)
+
+	mt.Run(
+		"storage failure", func(t *mtest.T) {
+			t.AddMockResponses(
+				mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "storage failure"}),
+			)
+
+			store := NewMongoHostRateStore(t.DB, testRateLimitPolicy)
+			_, err := store.Reserve(context.Background(), "roku.com", slotAt)
+
+			require.Error(t, err)
+			assert.Equal(t, domain.CrawlErrorStorage, domain.CrawlErrorKindOf(err))
+		},
+	)
+}
@@ -0,0 +1,173 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"github.com/PuerkitoBio/goquery"
+	"go-web-crawler-service/domain"
+	"go-web-crawler-service/metrics"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	httpCrawlerUserAgent = "Mozilla/5.0 (compatible; go-web-crawler-service)"
+	// Details pages are a few hundred kilobytes, anything bigger is not a page worth parsing
+	maxPageBytes = 10 << 20
+)
+
+type httpRokuWebCrawler struct {
+	client  *http.Client
+	profile *ExtractionProfile
+}
+
+// NewHttpRokuWebCrawler creates crawler that reads channel data from server rendered details page without
+// launching the browser
+func NewHttpRokuWebCrawler(client *http.Client, profile *ExtractionProfile) *httpRokuWebCrawler {
+	return &httpRokuWebCrawler{
+		client:  client,
+		profile: profile,
+	}
+}
+
+// CrawlChannel fetches the page in the locale, the channel keeps the url it was requested with
+func (c *httpRokuWebCrawler) CrawlChannel(
+	ctx context.Context,
+	url domain.Url,
+	locale domain.Locale,
+) (*domain.Channel, error) {
+	ctx, cancel := context.WithTimeout(ctx, crawlerTTLSeconds*time.Second)
+	defer cancel()
+
+	document, err := fetchDocument(ctx, c.client, c.profile.localizedUrl(url, locale), locale)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Successfully fetched page with url: %s\n", url)
+
+	// Structured data are preferred as they do not depend on the page layout
+	if c.profile.JSONLD {
+		channel, err := getChannelFromJSONLD(url, document, c.profile.Version)
+		if err != nil {
+			return nil, err
+		}
+		if channel != nil {
+			return channel, nil
+		}
+	}
+
+	root, err := findSelection(document.Selection, c.profile.Root)
+	if err != nil {
+		metrics.ExtractionFailures.WithLabelValues(fieldRoot).Inc()
+		return nil, c.profile.missingRootError(ctx, &goqueryExtractionNode{selection: document.Selection}, err)
+	}
+
+	return c.profile.extractChannel(
+		ctx,
+		url,
+		&goqueryExtractionNode{selection: document.Selection},
+		&goqueryExtractionNode{selection: root},
+	)
+}
+
+// fetchDocument reads the page asking for the language of the locale, store default language is served
+// for the empty locale
+func fetchDocument(
+	ctx context.Context,
+	client *http.Client,
+	url domain.Url,
+	locale domain.Locale,
+) (*goquery.Document, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, string(url), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request, error: %w", err)
+	}
+	request.Header.Set("User-Agent", httpCrawlerUserAgent)
+	request.Header.Set("Accept", "text/html")
+	if locale != "" {
+		request.Header.Set("Accept-Language", locale.AcceptLanguage())
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch website, error: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, domain.NewCrawlError(
+			responseErrorKind(response.StatusCode),
+			fmt.Errorf("unable to fetch website, unexpected status code: %d", response.StatusCode),
+		)
+	}
+
+	document, err := goquery.NewDocumentFromReader(io.LimitReader(response.Body, maxPageBytes))
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse website, error: %w", err)
+	}
+
+	return document, nil
+}
+
+// responseErrorKind classifies the failure by the status code of the page response
+func responseErrorKind(statusCode int) domain.CrawlErrorKind {
+	switch {
+	case statusCode == http.StatusNotFound, statusCode == http.StatusGone:
+		return domain.CrawlErrorNotFound
+	case statusCode == http.StatusUnauthorized, statusCode == http.StatusForbidden,
+		statusCode == http.StatusTooManyRequests:
+		return domain.CrawlErrorBlocked
+	case statusCode >= http.StatusInternalServerError:
+		return domain.CrawlErrorUnavailable
+	default:
+		return domain.CrawlErrorUnknown
+	}
+}
+
+// goqueryExtractionNode is an element of the server rendered page
+type goqueryExtractionNode struct {
+	selection *goquery.Selection
+}
+
+func (n *goqueryExtractionNode) find(_ context.Context, selector string) (extractionNode, error) {
+	selection, err := findSelection(n.selection, selector)
+	if err != nil {
+		return nil, err
+	}
+
+	return &goqueryExtractionNode{selection: selection}, nil
+}
+
+func (n *goqueryExtractionNode) text() (string, error) {
+	return n.selection.Text(), nil
+}
+
+func (n *goqueryExtractionNode) ownText() (string, error) {
+	var text strings.Builder
+	n.selection.Contents().Each(
+		func(_ int, node *goquery.Selection) {
+			if goquery.NodeName(node) == "#text" {
+				text.WriteString(node.Text())
+			}
+		},
+	)
+
+	return text.String(), nil
+}
+
+func (n *goqueryExtractionNode) attribute(name string) (string, error) {
+	return n.selection.AttrOr(name, ""), nil
+}
+
+func findSelection(parent *goquery.Selection, selector string) (*goquery.Selection, error) {
+	selection := parent.Find(selector).First()
+	if selection.Length() == 0 {
+		return nil, fmt.Errorf("%w: %s", domain.ErrElementNotFound, selector)
+	}
+
+	return selection, nil
+}
@@ -0,0 +1,294 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go-web-crawler-service/domain"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const (
+	testCrawlerTestdataDir = "../tests/fake-channel-server/testdata"
+	testCrawlerJSONLDPage  = `<html><head><script type="application/ld+json">
+{"@type": "SoftwareApplication", "name": "Netflix", "aggregateRating": {"ratingValue": "3.8", "ratingCount": 4195815}}
+</script></head><body></body></html>`
+)
+
+func newTestChannelServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc(
+		"/", func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, testCrawlerTestdataDir+"/mock-channel-hero-page.html")
+		},
+	)
+	mux.HandleFunc(
+		"/no-data.html", func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, testCrawlerTestdataDir+"/mock-channel-no-data-page.html")
+		},
+	)
+	mux.HandleFunc(
+		"/invalid.html", func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, testCrawlerTestdataDir+"/mock-inavalid-page.html")
+		},
+	)
+	mux.HandleFunc(
+		"/channel-unavailable.html", func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, testCrawlerTestdataDir+"/mock-channel-unavailable-page.html")
+		},
+	)
+	mux.HandleFunc(
+		"/json-ld.html", func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(testCrawlerJSONLDPage))
+		},
+	)
+	mux.HandleFunc("/missing.html", http.NotFound)
+	mux.HandleFunc(
+		"/forbidden.html", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		},
+	)
+	mux.HandleFunc(
+		"/unavailable.html", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		},
+	)
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestHttpRokuWebCrawler_CrawlChannel(t *testing.T) {
+	server := newTestChannelServer(t)
+	profile, err := DefaultExtractionProfile()
+	require.NoError(t, err)
+	crawler := NewHttpRokuWebCrawler(server.Client(), profile)
+
+	t.Run(
+		"channel from markup", func(t *testing.T) {
+			url := domain.Url(server.URL + "/")
+			channel, err := crawler.CrawlChannel(context.Background(), url, "")
+
+			require.NoError(t, err)
+			assert.EqualValues(t, "Netflix", channel.ApplicationName)
+			assert.EqualValues(t, 3.8, channel.Rating)
+			assert.EqualValues(t, 4195815, channel.NumberOfRatings)
+			assert.Equal(t, url, channel.Url)
+			assert.Equal(t, profile.Version, channel.ProfileVersion)
+			assert.EqualValues(t, "Netflix", channel.Developer)
+			assert.EqualValues(t, "Movies & TV", channel.Category)
+			assert.True(t, strings.HasPrefix(string(channel.Description), "Watch TV shows and movies recommended"))
+		},
+	)
+
+	t.Run(
+		"channel without ratings", func(t *testing.T) {
+			channel, err := crawler.CrawlChannel(context.Background(), domain.Url(server.URL+"/no-data.html"), "")
+
+			require.NoError(t, err)
+			assert.EqualValues(t, "Kingdomcity", channel.ApplicationName)
+			assert.EqualValues(t, 0, channel.Rating)
+			assert.EqualValues(t, 0, channel.NumberOfRatings)
+		},
+	)
+
+	t.Run(
+		"channel from json-ld", func(t *testing.T) {
+			channel, err := crawler.CrawlChannel(context.Background(), domain.Url(server.URL+"/json-ld.html"), "")
+
+			require.NoError(t, err)
+			assert.EqualValues(t, "Netflix", channel.ApplicationName)
+			assert.EqualValues(t, 3.8, channel.Rating)
+			assert.EqualValues(t, 4195815, channel.NumberOfRatings)
+		},
+	)
+
+	t.Run(
+		"no elements found", func(t *testing.T) {
+			_, err := crawler.CrawlChannel(context.Background(), domain.Url(server.URL+"/invalid.html"), "")
+
+			require.ErrorIs(t, err, domain.ErrElementNotFound)
+			assert.Equal(t, domain.CrawlErrorMarkupChanged, domain.CrawlErrorKindOf(err))
+		},
+	)
+
+	t.Run(
+		"page not found", func(t *testing.T) {
+			_, err := crawler.CrawlChannel(context.Background(), domain.Url(server.URL+"/missing.html"), "")
+
+			require.Error(t, err)
+			assert.Equal(t, domain.CrawlErrorNotFound, domain.CrawlErrorKindOf(err))
+		},
+	)
+
+	t.Run(
+		"crawler blocked", func(t *testing.T) {
+			_, err := crawler.CrawlChannel(context.Background(), domain.Url(server.URL+"/forbidden.html"), "")
+
+			require.Error(t, err)
+			assert.Equal(t, domain.CrawlErrorBlocked, domain.CrawlErrorKindOf(err))
+		},
+	)
+
+	t.Run(
+		"store unavailable", func(t *testing.T) {
+			_, err := crawler.CrawlChannel(context.Background(), domain.Url(server.URL+"/unavailable.html"), "")
+
+			require.Error(t, err)
+			assert.Equal(t, domain.CrawlErrorUnavailable, domain.CrawlErrorKindOf(err))
+		},
+	)
+}
+
+func TestHttpRokuWebCrawler_CrawlChannel_InLocale(t *testing.T) {
+	var requestedPath, acceptLanguage string
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				requestedPath = r.URL.Path
+				acceptLanguage = r.Header.Get("Accept-Language")
+				_, _ = w.Write([]byte(testCrawlerJSONLDPage))
+			},
+		),
+	)
+	t.Cleanup(server.Close)
+
+	profile, err := DefaultExtractionProfile()
+	require.NoError(t, err)
+	crawler := NewHttpRokuWebCrawler(server.Client(), profile)
+
+	url := domain.Url(server.URL + "/details/12")
+	channel, err := crawler.CrawlChannel(context.Background(), url, "en-GB")
+
+	require.NoError(t, err)
+	assert.Equal(t, "/en-gb/details/12", requestedPath)
+	assert.Equal(t, "en-GB,en;q=0.9", acceptLanguage)
+	assert.Equal(t, url, channel.Url)
+}
+
+func TestHttpRokuWebCrawler_CrawlChannel_ClassifiesPageWithoutRoot(t *testing.T) {
+	server := newTestChannelServer(t)
+	server.Config.Handler.(*http.ServeMux).HandleFunc(
+		"/captcha.html", func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`<html><body><div id="px-captcha"></div></body></html>`))
+		},
+	)
+
+	profile, err := DefaultExtractionProfile()
+	require.NoError(t, err)
+	crawler := NewHttpRokuWebCrawler(server.Client(), profile)
+
+	_, err = crawler.CrawlChannel(context.Background(), domain.Url(server.URL+"/captcha.html"), "")
+	assert.Equal(t, domain.CrawlErrorBlocked, domain.CrawlErrorKindOf(err))
+
+	_, err = crawler.CrawlChannel(context.Background(), domain.Url(server.URL+"/channel-unavailable.html"), "")
+	assert.Equal(t, domain.CrawlErrorNotFound, domain.CrawlErrorKindOf(err))
+
+	_, err = crawler.CrawlChannel(context.Background(), domain.Url(server.URL+"/invalid.html"), "")
+	assert.Equal(t, domain.CrawlErrorMarkupChanged, domain.CrawlErrorKindOf(err))
+}
+
+type webCrawlerFunc func(ctx context.Context, url domain.Url, locale domain.Locale) (*domain.Channel, error)
+
+func (f webCrawlerFunc) CrawlChannel(
+	ctx context.Context,
+	url domain.Url,
+	locale domain.Locale,
+) (*domain.Channel, error) {
+	return f(ctx, url, locale)
+}
+
+func TestFallbackWebCrawler_CrawlChannel(t *testing.T) {
+	url := domain.Url("https://channelstore.roku.com/details/12")
+	channel := domain.NewChannel("Netflix", url, 3.8, 100)
+	failing := webCrawlerFunc(
+		func(ctx context.Context, url domain.Url, locale domain.Locale) (*domain.Channel, error) {
+			return nil, errors.New("crawler error")
+		},
+	)
+	succeeding := webCrawlerFunc(
+		func(ctx context.Context, url domain.Url, locale domain.Locale) (*domain.Channel, error) {
+			return channel, nil
+		},
+	)
+
+	t.Run(
+		"primary crawler succeeded", func(t *testing.T) {
+			result, err := NewFallbackWebCrawler(succeeding, failing).CrawlChannel(context.Background(), url, "")
+
+			require.NoError(t, err)
+			assert.Equal(t, channel, result)
+		},
+	)
+
+	t.Run(
+		"primary crawler failed", func(t *testing.T) {
+			result, err := NewFallbackWebCrawler(failing, succeeding).CrawlChannel(context.Background(), url, "")
+
+			require.NoError(t, err)
+			assert.Equal(t, channel, result)
+		},
+	)
+
+	t.Run(
+		"primary crawler could not read the markup", func(t *testing.T) {
+			markupChanged := webCrawlerFunc(
+				func(ctx context.Context, url domain.Url, locale domain.Locale) (*domain.Channel, error) {
+					return nil, domain.NewCrawlError(domain.CrawlErrorMarkupChanged, errors.New("no hero"))
+				},
+			)
+
+			result, err := NewFallbackWebCrawler(markupChanged, succeeding).CrawlChannel(context.Background(), url, "")
+
+			require.NoError(t, err)
+			assert.Equal(t, channel, result)
+		},
+	)
+
+	for _, kind := range []domain.CrawlErrorKind{
+		domain.CrawlErrorNotFound,
+		domain.CrawlErrorBlocked,
+		domain.CrawlErrorUnavailable,
+		domain.CrawlErrorTimeout,
+	} {
+		classified := webCrawlerFunc(
+			func(ctx context.Context, url domain.Url, locale domain.Locale) (*domain.Channel, error) {
+				return nil, domain.NewCrawlError(kind, errors.New("crawler error"))
+			},
+		)
+
+		t.Run(
+			"primary crawler failed with "+string(kind), func(t *testing.T) {
+				fallbackCalled := false
+				fallback := webCrawlerFunc(
+					func(ctx context.Context, url domain.Url, locale domain.Locale) (*domain.Channel, error) {
+						fallbackCalled = true
+						return channel, nil
+					},
+				)
+
+				_, err := NewFallbackWebCrawler(classified, fallback).CrawlChannel(context.Background(), url, "")
+
+				assert.Equal(t, kind, domain.CrawlErrorKindOf(err))
+				assert.False(t, fallbackCalled)
+			},
+		)
+	}
+
+	t.Run(
+		"context cancelled", func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			_, err := NewFallbackWebCrawler(failing, succeeding).CrawlChannel(ctx, url, "")
+
+			require.Error(t, err)
+		},
+	)
+}
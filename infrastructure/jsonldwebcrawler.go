@@ -0,0 +1,241 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/PuerkitoBio/goquery"
+	"go-web-crawler-service/domain"
+	"go-web-crawler-service/metrics"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// jsonLDProfileVersion identifies channels extracted from JSON-LD by the generic store crawler
+const jsonLDProfileVersion = "json-ld-v1"
+
+type jsonLDWebCrawler struct {
+	client *http.Client
+}
+
+// NewJSONLDWebCrawler creates generic crawler of the stores describing their applications with JSON-LD
+// AggregateRating, e.g. Apple App Store
+func NewJSONLDWebCrawler(client *http.Client) *jsonLDWebCrawler {
+	return &jsonLDWebCrawler{
+		client: client,
+	}
+}
+
+// CrawlChannel asks for the language of the locale only, stores telling the country by the url are crawled
+// in the country of the submitted url
+func (c *jsonLDWebCrawler) CrawlChannel(
+	ctx context.Context,
+	url domain.Url,
+	locale domain.Locale,
+) (*domain.Channel, error) {
+	ctx, cancel := context.WithTimeout(ctx, crawlerTTLSeconds*time.Second)
+	defer cancel()
+
+	document, err := fetchDocument(ctx, c.client, url, locale)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Successfully fetched page with url: %s\n", url)
+
+	channel, err := getChannelFromJSONLD(url, document, jsonLDProfileVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	if channel == nil {
+		metrics.ExtractionFailures.WithLabelValues(fieldRoot).Inc()
+		return nil, fmt.Errorf("%w: application described by JSON-LD", domain.ErrElementNotFound)
+	}
+
+	return channel, nil
+}
+
+type jsonLDAggregateRating struct {
+	RatingValue json.Number `json:"ratingValue"`
+	RatingCount json.Number `json:"ratingCount"`
+	ReviewCount json.Number `json:"reviewCount"`
+}
+
+type jsonLDOffer struct {
+	Price         json.Number `json:"price"`
+	PriceCurrency string      `json:"priceCurrency"`
+}
+
+// jsonLDApplication is an entity described by JSON-LD, properties that could be either text or the entity
+// are kept raw, so the unexpected shape of them does not make the whole entity invalid
+type jsonLDApplication struct {
+	Name                string                 `json:"name"`
+	Description         json.RawMessage        `json:"description"`
+	ApplicationCategory json.RawMessage        `json:"applicationCategory"`
+	ContentRating       json.RawMessage        `json:"contentRating"`
+	Author              json.RawMessage        `json:"author"`
+	Publisher           json.RawMessage        `json:"publisher"`
+	Offers              json.RawMessage        `json:"offers"`
+	AggregateRating     *jsonLDAggregateRating `json:"aggregateRating"`
+	Graph               []jsonLDApplication    `json:"@graph"`
+}
+
+// getChannelFromJSONLD looks for application described by JSON-LD scripts, nil channel is returned
+// when the page does not contain one. Rated entities are preferred as pages describe their publisher as well.
+func getChannelFromJSONLD(url domain.Url, document *goquery.Document, profileVersion string) (
+	*domain.Channel,
+	error,
+) {
+	var candidates []jsonLDApplication
+	document.Find(`script[type="application/ld+json"]`).Each(
+		func(_ int, script *goquery.Selection) {
+			candidates = append(candidates, parseJSONLDScript(script.Text())...)
+		},
+	)
+
+	var application *jsonLDApplication
+	for i := range candidates {
+		if candidates[i].Name == "" {
+			continue
+		}
+
+		if application == nil || (application.AggregateRating == nil && candidates[i].AggregateRating != nil) {
+			application = &candidates[i]
+		}
+	}
+
+	if application == nil {
+		return nil, nil
+	}
+
+	var ratingVal float64
+	var ratingsAmountVal int64
+	if application.AggregateRating != nil {
+		var err error
+		ratingVal, err = parseJSONLDNumber(application.AggregateRating.RatingValue).Float64()
+		if err != nil {
+			metrics.ExtractionFailures.WithLabelValues(fieldRating).Inc()
+			return nil, domain.NewCrawlError(domain.CrawlErrorInvalidData, fmt.Errorf("failed to get average rating, %w", err))
+		}
+
+		ratingsAmount := application.AggregateRating.RatingCount
+		if ratingsAmount == "" {
+			ratingsAmount = application.AggregateRating.ReviewCount
+		}
+		ratingsAmountVal, err = parseJSONLDNumber(ratingsAmount).Int64()
+		if err != nil {
+			metrics.ExtractionFailures.WithLabelValues(fieldNumberOfRatings).Inc()
+			return nil, domain.NewCrawlError(domain.CrawlErrorInvalidData, fmt.Errorf("failed to get ratings amount, %w", err))
+		}
+	}
+
+	channel, err := createChannel(
+		url,
+		application.Name,
+		float32(ratingVal),
+		uint32(ratingsAmountVal),
+		profileVersion,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create channel entity from scrapped data, error: %w", err)
+	}
+
+	developer := parseJSONLDText(application.Author)
+	if developer == "" {
+		developer = parseJSONLDText(application.Publisher)
+	}
+	channel.Developer = domain.Developer(developer)
+	channel.Category = domain.Category(parseJSONLDText(application.ApplicationCategory))
+	channel.Description = domain.Description(parseJSONLDText(application.Description))
+	channel.ContentRating = domain.ContentRating(parseJSONLDText(application.ContentRating))
+	channel.Price = parseJSONLDPrice(application.Offers)
+
+	return channel, nil
+}
+
+// parseJSONLDText reads the text property, which could be the text itself, the entity with the name or list
+// of them, the first one is used. Empty text is returned when the property has other shape.
+func parseJSONLDText(property json.RawMessage) string {
+	if len(property) == 0 {
+		return ""
+	}
+
+	var text string
+	if json.Unmarshal(property, &text) == nil {
+		return strings.TrimSpace(text)
+	}
+
+	var entity struct {
+		Name string `json:"name"`
+	}
+	if json.Unmarshal(property, &entity) == nil {
+		return strings.TrimSpace(entity.Name)
+	}
+
+	var list []json.RawMessage
+	if json.Unmarshal(property, &list) == nil && len(list) > 0 {
+		return parseJSONLDText(list[0])
+	}
+
+	return ""
+}
+
+// parseJSONLDPrice reads price of the first offer, which could be a single offer or list of them
+func parseJSONLDPrice(offers json.RawMessage) domain.Price {
+	if len(offers) == 0 {
+		return ""
+	}
+
+	var offer jsonLDOffer
+	if json.Unmarshal(offers, &offer) != nil {
+		var list []jsonLDOffer
+		if json.Unmarshal(offers, &list) != nil || len(list) == 0 {
+			return ""
+		}
+		offer = list[0]
+	}
+
+	price, err := offer.Price.Float64()
+	switch {
+	case err != nil:
+		return ""
+	case price == 0:
+		return "Free"
+	default:
+		return domain.Price(strings.TrimSpace(string(offer.Price) + " " + offer.PriceCurrency))
+	}
+}
+
+// parseJSONLDScript returns entities described by the script, which could contain a single entity, list of them
+// or the graph of them. Invalid scripts are skipped as they are out of our control.
+func parseJSONLDScript(script string) []jsonLDApplication {
+	var entities []jsonLDApplication
+	err := json.Unmarshal([]byte(script), &entities)
+	if err != nil {
+		var entity jsonLDApplication
+		err = json.Unmarshal([]byte(script), &entity)
+		if err != nil {
+			return nil
+		}
+		entities = []jsonLDApplication{entity}
+	}
+
+	var flattened []jsonLDApplication
+	for _, entity := range entities {
+		flattened = append(flattened, entity)
+		flattened = append(flattened, entity.Graph...)
+	}
+
+	return flattened
+}
+
+func parseJSONLDNumber(number json.Number) json.Number {
+	if number == "" {
+		return "0"
+	}
+
+	return number
+}
@@ -0,0 +1,64 @@
+package infrastructure
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go-web-crawler-service/domain"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const (
+	testJSONLDGraphPage = `<html><head>
+<script type="application/ld+json">{"@type": "Organization", "name": "Netflix, Inc."}</script>
+<script type="application/ld+json">{"@graph": [{"@type": "SoftwareApplication", "name": "Netflix",
+"author": {"@type": "Organization", "name": "Netflix, Inc."}, "applicationCategory": ["Entertainment"],
+"offers": {"@type": "Offer", "price": "4.99", "priceCurrency": "USD"}, "contentRating": {"@type": "Rating"},
+"aggregateRating": {"@type": "AggregateRating", "ratingValue": 4.5, "reviewCount": "321"}}]}</script>
+</head><body></body></html>`
+	testJSONLDInvalidPage = `<html><head><script type="application/ld+json">{invalid</script></head></html>`
+)
+
+func TestJSONLDWebCrawler_CrawlChannel(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc(
+		"/graph.html", func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(testJSONLDGraphPage))
+		},
+	)
+	mux.HandleFunc(
+		"/invalid.html", func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(testJSONLDInvalidPage))
+		},
+	)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	crawler := NewJSONLDWebCrawler(server.Client())
+
+	t.Run(
+		"rated application preferred", func(t *testing.T) {
+			channel, err := crawler.CrawlChannel(context.Background(), domain.Url(server.URL+"/graph.html"), "")
+
+			require.NoError(t, err)
+			assert.EqualValues(t, "Netflix", channel.ApplicationName)
+			assert.EqualValues(t, 4.5, channel.Rating)
+			assert.EqualValues(t, 321, channel.NumberOfRatings)
+			assert.Equal(t, jsonLDProfileVersion, channel.ProfileVersion)
+			assert.EqualValues(t, "Netflix, Inc.", channel.Developer)
+			assert.EqualValues(t, "Entertainment", channel.Category)
+			assert.EqualValues(t, "4.99 USD", channel.Price)
+			assert.Empty(t, channel.ContentRating)
+		},
+	)
+
+	t.Run(
+		"no application described", func(t *testing.T) {
+			_, err := crawler.CrawlChannel(context.Background(), domain.Url(server.URL+"/invalid.html"), "")
+
+			require.ErrorIs(t, err, domain.ErrElementNotFound)
+		},
+	)
+}
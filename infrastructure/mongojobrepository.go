@@ -0,0 +1,274 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-web-crawler-service/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+	"time"
+)
+
+const (
+	jobCollection = "job"
+	jobRetention  = 7 * 24 * time.Hour
+
+	duplicateKeyErrorCode = 11000
+)
+
+type mongoJobRepository struct {
+	db           *mongo.Database
+	activeWindow time.Duration
+}
+
+// NewMongoJobRepository creates repository of the jobs, job created before the active window does not keep the url
+// from being scheduled again even when it never finished, e.g. as the API crashed before its message was published
+func NewMongoJobRepository(db *mongo.Database, activeWindow time.Duration) *mongoJobRepository {
+	return &mongoJobRepository{
+		db:           db,
+		activeWindow: activeWindow,
+	}
+}
+
+// jobMongoDTO is the stored job, active is set while the job is queued or running, so only one job of the url
+// is processed at once
+type jobMongoDTO struct {
+	ID         string    `bson:"_id"`
+	Url        string    `bson:"url"`
+	Locale     string    `bson:"locale"`
+	Priority   string    `bson:"priority,omitempty"`
+	Status     string    `bson:"status"`
+	Active     bool      `bson:"active,omitempty"`
+	Error      string    `bson:"error,omitempty"`
+	ArtifactID string    `bson:"artifactId,omitempty"`
+	CreatedAt  time.Time `bson:"createdAt"`
+	UpdatedAt  time.Time `bson:"updatedAt"`
+}
+
+func newJobMongoDTO(job domain.Job) jobMongoDTO {
+	return jobMongoDTO{
+		ID:         string(job.ID),
+		Url:        string(job.Url),
+		Locale:     string(job.Locale),
+		Priority:   string(job.Priority),
+		Status:     string(job.Status),
+		Active:     !job.Status.IsFinal(),
+		Error:      job.Error,
+		ArtifactID: string(job.ArtifactID),
+		CreatedAt:  job.CreatedAt,
+		UpdatedAt:  job.UpdatedAt,
+	}
+}
+
+func (d jobMongoDTO) toJob() domain.Job {
+	// Jobs created before the priority lanes waited in the only queue, which became the normal lane
+	priority := domain.Priority(d.Priority)
+	if priority == "" {
+		priority = domain.PriorityNormal
+	}
+
+	return domain.Job{
+		ID:         domain.JobID(d.ID),
+		Url:        domain.Url(d.Url),
+		Locale:     domain.Locale(d.Locale),
+		Priority:   priority,
+		Status:     domain.JobStatus(d.Status),
+		Error:      d.Error,
+		ArtifactID: domain.ArtifactID(d.ArtifactID),
+		CreatedAt:  d.CreatedAt,
+		UpdatedAt:  d.UpdatedAt,
+	}
+}
+
+// EnsureIndexes creates TTL index, so finished jobs do not pile up forever, index of the url used
+// to find the recently scheduled jobs of the url and unique index of the active jobs, so the same url
+// is not scheduled twice in the same locale with the same priority by the concurrent requests
+func (r *mongoJobRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.getCollection().Indexes().CreateMany(
+		ctx, []mongo.IndexModel{
+			{
+				Keys:    bson.D{{Key: "createdAt", Value: 1}},
+				Options: options.Index().SetExpireAfterSeconds(int32(jobRetention.Seconds())),
+			},
+			{
+				Keys: bson.D{{Key: "url", Value: 1}, {Key: "createdAt", Value: -1}},
+			},
+			{
+				Keys: bson.D{{Key: "url", Value: 1}, {Key: "locale", Value: 1}, {Key: "priority", Value: 1}},
+				Options: options.Index().
+					SetUnique(true).
+					SetPartialFilterExpression(bson.M{"active": true}),
+			},
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create job index, error: %w", err)
+	}
+
+	return nil
+}
+
+// Create inserts the jobs unordered, so the job rejected by the unique index of the active jobs does not stop
+// the rest of them from being inserted
+func (r *mongoJobRepository) Create(ctx context.Context, jobs []domain.Job) []error {
+	errs := make([]error, len(jobs))
+	if len(jobs) == 0 {
+		return errs
+	}
+
+	documents := make([]interface{}, 0, len(jobs))
+	for _, job := range jobs {
+		documents = append(documents, newJobMongoDTO(job))
+	}
+
+	_, err := r.getCollection().InsertMany(ctx, documents, options.InsertMany().SetOrdered(false))
+	if err == nil {
+		return errs
+	}
+
+	var bulkErr mongo.BulkWriteException
+	if !errors.As(err, &bulkErr) || bulkErr.WriteConcernError != nil {
+		for i := range errs {
+			errs[i] = storageError("failed to save %d jobs in MongoDB collection, error: %w", len(jobs), err)
+		}
+
+		return errs
+	}
+
+	for _, writeErr := range bulkErr.WriteErrors {
+		job := jobs[writeErr.Index]
+		if writeErr.Code == duplicateKeyErrorCode {
+			errs[writeErr.Index] = r.createDuplicate(ctx, job)
+			continue
+		}
+
+		errs[writeErr.Index] = storageError("failed to save job %s, error: %w", job.ID, writeErr)
+	}
+
+	return errs
+}
+
+// createDuplicate inserts the job rejected by the unique index of the active jobs again once the active job it
+// duplicates turns out to be stale, otherwise DuplicateJobError with the active job is returned. Stale job is only
+// deactivated, so it's still processed when its message is consumed after all.
+func (r *mongoJobRepository) createDuplicate(ctx context.Context, job domain.Job) error {
+	filter := bson.M{"url": job.Url, "locale": job.Locale, "priority": job.Priority, "active": true}
+	if job.Priority == "" {
+		filter["priority"] = nil
+	}
+
+	staleFilter := bson.M{"createdAt": bson.M{"$lt": time.Now().Add(-r.activeWindow)}}
+	for key, value := range filter {
+		staleFilter[key] = value
+	}
+
+	result, err := r.getCollection().UpdateMany(ctx, staleFilter, bson.M{"$unset": bson.M{"active": ""}})
+	if err != nil {
+		return storageError("failed to deactivate stale jobs duplicated by job %s, error: %w", job.ID, err)
+	}
+
+	if result.ModifiedCount > 0 {
+		log.Printf("Deactivated %d stale jobs duplicated by job %s\n", result.ModifiedCount, job.ID)
+
+		_, err = r.getCollection().InsertOne(ctx, newJobMongoDTO(job))
+		if err == nil {
+			return nil
+		}
+		if !mongo.IsDuplicateKeyError(err) {
+			return storageError("failed to save job %s, error: %w", job.ID, err)
+		}
+	}
+
+	var dto jobMongoDTO
+	err = r.getCollection().FindOne(ctx, filter).Decode(&dto)
+	if err != nil {
+		log.Printf("Could not find job duplicated by job %s, error: %v\n", job.ID, err)
+		return domain.NewDuplicateJobError("")
+	}
+
+	return domain.NewDuplicateJobError(domain.JobID(dto.ID))
+}
+
+func (r *mongoJobRepository) UpdateStatus(
+	ctx context.Context,
+	id domain.JobID,
+	status domain.JobStatus,
+	reason string,
+) error {
+	update := bson.M{"$set": bson.M{"status": status, "error": reason, "updatedAt": time.Now()}}
+	if status.IsFinal() {
+		// Finished job does not keep the url from being scheduled again
+		update["$unset"] = bson.M{"active": ""}
+	}
+
+	result, err := r.getCollection().UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return storageError("failed to update job %s status, error: %w", id, err)
+	}
+
+	if result.MatchedCount == 0 {
+		return domain.ErrJobNotFound
+	}
+
+	return nil
+}
+
+// AttachArtifact links the artifact captured when the crawl of the job failed, previous one is replaced
+func (r *mongoJobRepository) AttachArtifact(ctx context.Context, id domain.JobID, artifactID domain.ArtifactID) error {
+	result, err := r.getCollection().UpdateOne(
+		ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": bson.M{"artifactId": artifactID}},
+	)
+	if err != nil {
+		return storageError("failed to attach artifact to job %s, error: %w", id, err)
+	}
+
+	if result.MatchedCount == 0 {
+		return domain.ErrJobNotFound
+	}
+
+	return nil
+}
+
+func (r *mongoJobRepository) FindByID(ctx context.Context, id domain.JobID) (*domain.Job, error) {
+	var dto jobMongoDTO
+	err := r.getCollection().FindOne(ctx, bson.M{"_id": id}).Decode(&dto)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.ErrJobNotFound
+		}
+
+		return nil, storageError("failed to find job %s, error: %w", id, err)
+	}
+
+	job := dto.toJob()
+	return &job, nil
+}
+
+func (r *mongoJobRepository) FindByIDs(ctx context.Context, ids []domain.JobID) ([]domain.Job, error) {
+	cursor, err := r.getCollection().Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, storageError("failed to find jobs, error: %w", err)
+	}
+
+	var dtos []jobMongoDTO
+	err = cursor.All(ctx, &dtos)
+	if err != nil {
+		return nil, storageError("failed to decode jobs, error: %w", err)
+	}
+
+	jobs := make([]domain.Job, 0, len(dtos))
+	for _, dto := range dtos {
+		jobs = append(jobs, dto.toJob())
+	}
+
+	return jobs, nil
+}
+
+func (r *mongoJobRepository) getCollection() *mongo.Collection {
+	return r.db.Collection(jobCollection)
+}
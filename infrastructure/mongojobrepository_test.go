@@ -0,0 +1,247 @@
+package infrastructure
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go-web-crawler-service/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+	"testing"
+	"time"
+)
+
+const (
+	testRepoJobID domain.JobID = "job-id"
+)
+
+func TestJobRepository_Create(t *testing.T) {
+	options := mtest.NewOptions().ClientType(mtest.Mock).CollectionName(jobCollection)
+	mt := mtest.New(t, options)
+	defer mt.Close()
+
+	createdAt := time.Date(2022, 4, 1, 12, 0, 0, 0, time.UTC)
+	jobs := []domain.Job{
+		*domain.NewJob(*domain.NewCrawlRequest("first", testRepoChannelURL, "", domain.PriorityNormal), createdAt),
+		*domain.NewJob(
+			*domain.NewCrawlRequest("second", "https://google.com/other", "", domain.PriorityHigh),
+			createdAt,
+		),
+	}
+
+	mt.Run(
+		"create jobs successfully", func(t *mtest.T) {
+			t.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 2}))
+
+			repository := NewMongoJobRepository(t.DB, time.Hour)
+			errs := repository.Create(context.Background(), jobs)
+
+			require.Len(t, errs, 2)
+			require.NoError(t, errs[0])
+			require.NoError(t, errs[1])
+
+			insertEvent := t.GetStartedEvent()
+			require.Equal(t, "insert", insertEvent.CommandName)
+			assert.False(t, insertEvent.Command.Lookup("ordered").Boolean(), "jobs after the duplicate are inserted")
+			document := insertEvent.Command.Lookup("documents").Array().Index(0).Value().Document()
+			assert.True(t, document.Lookup("active").Boolean(), "queued job is active")
+		},
+	)
+
+	mt.Run(
+		"job duplicates active job", func(t *mtest.T) {
+			namespace := t.DB.Name() + "." + jobCollection
+			t.AddMockResponses(
+				mtest.CreateWriteErrorsResponse(
+					mtest.WriteError{
+						Index:   0,
+						Code:    11000,
+						Message: "duplicate key error",
+					},
+				),
+				mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}, bson.E{Key: "nModified", Value: 0}),
+				mtest.CreateCursorResponse(
+					0, namespace, mtest.FirstBatch,
+					bson.D{
+						{Key: "_id", Value: "queued"},
+						{Key: "url", Value: string(testRepoChannelURL)},
+						{Key: "priority", Value: string(domain.PriorityNormal)},
+						{Key: "status", Value: string(domain.JobStatusQueued)},
+						{Key: "active", Value: true},
+					},
+				),
+			)
+
+			repository := NewMongoJobRepository(t.DB, time.Hour)
+			errs := repository.Create(context.Background(), jobs)
+
+			require.Len(t, errs, 2)
+			jobID, duplicate := domain.DuplicateJobIDOf(errs[0])
+			assert.True(t, duplicate)
+			assert.Equal(t, domain.JobID("queued"), jobID)
+			require.NoError(t, errs[1])
+
+			t.GetStartedEvent()
+			updateEvent := t.GetStartedEvent()
+			require.Equal(t, "update", updateEvent.CommandName)
+			staleFilter := updateEvent.Command.Lookup("updates").Array().Index(0).Value().Document().Lookup("q")
+			_, err := staleFilter.Document().LookupErr("createdAt", "$lt")
+			require.NoError(t, err, "only jobs created before the active window are deactivated")
+
+			findEvent := t.GetStartedEvent()
+			require.Equal(t, "find", findEvent.CommandName)
+			assert.Equal(t, string(testRepoChannelURL), findEvent.Command.Lookup("filter", "url").StringValue())
+			assert.True(t, findEvent.Command.Lookup("filter", "active").Boolean())
+		},
+	)
+
+	mt.Run(
+		"job duplicates stale active job", func(t *mtest.T) {
+			// Active job was never published, e.g. as the API crashed after creating it
+			t.AddMockResponses(
+				mtest.CreateWriteErrorsResponse(
+					mtest.WriteError{
+						Index:   0,
+						Code:    11000,
+						Message: "duplicate key error",
+					},
+				),
+				mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}),
+				mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}),
+			)
+
+			repository := NewMongoJobRepository(t.DB, time.Hour)
+			errs := repository.Create(context.Background(), jobs)
+
+			require.Len(t, errs, 2)
+			require.NoError(t, errs[0])
+			require.NoError(t, errs[1])
+
+			t.GetStartedEvent()
+			updateEvent := t.GetStartedEvent()
+			require.Equal(t, "update", updateEvent.CommandName)
+			update := updateEvent.Command.Lookup("updates").Array().Index(0).Value().Document()
+			_, err := update.LookupErr("u", "$unset", "active")
+			require.NoError(t, err)
+
+			insertEvent := t.GetStartedEvent()
+			require.Equal(t, "insert", insertEvent.CommandName)
+			document := insertEvent.Command.Lookup("documents").Array().Index(0).Value().Document()
+			assert.Equal(t, "first", document.Lookup("_id").StringValue())
+		},
+	)
+
+	mt.Run(
+		"storage error", func(t *mtest.T) {
+			t.AddMockResponses(
+				mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "storage error"}),
+			)
+
+			repository := NewMongoJobRepository(t.DB, time.Hour)
+			errs := repository.Create(context.Background(), jobs)
+
+			require.Len(t, errs, 2)
+			assert.Equal(t, domain.CrawlErrorStorage, domain.CrawlErrorKindOf(errs[0]))
+			assert.Equal(t, domain.CrawlErrorStorage, domain.CrawlErrorKindOf(errs[1]))
+		},
+	)
+}
+
+func TestJobRepository_UpdateStatus(t *testing.T) {
+	options := mtest.NewOptions().ClientType(mtest.Mock).CollectionName(jobCollection)
+	mt := mtest.New(t, options)
+	defer mt.Close()
+
+	mt.Run(
+		"update job status successfully", func(t *mtest.T) {
+			t.AddMockResponses(
+				mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}),
+			)
+
+			repository := NewMongoJobRepository(t.DB, time.Hour)
+			err := repository.UpdateStatus(context.Background(), testRepoJobID, domain.JobStatusRunning, "")
+
+			require.NoError(t, err)
+		},
+	)
+
+	mt.Run(
+		"finished job is not active", func(t *mtest.T) {
+			t.AddMockResponses(
+				mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}),
+			)
+
+			repository := NewMongoJobRepository(t.DB, time.Hour)
+			err := repository.UpdateStatus(context.Background(), testRepoJobID, domain.JobStatusDone, "")
+
+			require.NoError(t, err)
+			update := t.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document()
+			_, err = update.LookupErr("u", "$unset", "active")
+			require.NoError(t, err)
+		},
+	)
+
+	mt.Run(
+		"job not found", func(t *mtest.T) {
+			t.AddMockResponses(
+				mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}, bson.E{Key: "nModified", Value: 0}),
+			)
+
+			repository := NewMongoJobRepository(t.DB, time.Hour)
+			err := repository.UpdateStatus(context.Background(), testRepoJobID, domain.JobStatusRunning, "")
+
+			require.ErrorIs(t, err, domain.ErrJobNotFound)
+		},
+	)
+}
+
+func TestJobRepository_FindByID(t *testing.T) {
+	options := mtest.NewOptions().ClientType(mtest.Mock).CollectionName(jobCollection)
+	mt := mtest.New(t, options)
+	defer mt.Close()
+
+	mt.Run(
+		"find job successfully", func(t *mtest.T) {
+			createdAt := time.Date(2022, 4, 1, 12, 0, 0, 0, time.UTC)
+			namespace := t.DB.Name() + "." + jobCollection
+
+			t.AddMockResponses(
+				mtest.CreateCursorResponse(
+					0, namespace, mtest.FirstBatch,
+					bson.D{
+						{Key: "_id", Value: string(testRepoJobID)},
+						{Key: "url", Value: string(testRepoChannelURL)},
+						{Key: "status", Value: string(domain.JobStatusFailed)},
+						{Key: "error", Value: "timeout"},
+						{Key: "artifactId", Value: "20220401T120000Z-0a1b2c3d"},
+						{Key: "createdAt", Value: createdAt},
+						{Key: "updatedAt", Value: createdAt},
+					},
+				),
+			)
+
+			repository := NewMongoJobRepository(t.DB, time.Hour)
+			job, err := repository.FindByID(context.Background(), testRepoJobID)
+
+			require.NoError(t, err)
+			assert.Equal(t, testRepoJobID, job.ID)
+			assert.Equal(t, testRepoChannelURL, job.Url)
+			assert.Equal(t, domain.JobStatusFailed, job.Status)
+			assert.Equal(t, domain.ArtifactID("20220401T120000Z-0a1b2c3d"), job.ArtifactID)
+			assert.Equal(t, "timeout", job.Error)
+			assert.Equal(t, domain.PriorityNormal, job.Priority, "job stored before the lanes has normal priority")
+		},
+	)
+
+	mt.Run(
+		"job not found", func(t *mtest.T) {
+			namespace := t.DB.Name() + "." + jobCollection
+			t.AddMockResponses(mtest.CreateCursorResponse(0, namespace, mtest.FirstBatch))
+
+			repository := NewMongoJobRepository(t.DB, time.Hour)
+			_, err := repository.FindByID(context.Background(), testRepoJobID)
+
+			require.ErrorIs(t, err, domain.ErrJobNotFound)
+		},
+	)
+}
@@ -0,0 +1,54 @@
+package infrastructure
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+const (
+	leaseCollection = "lease"
+)
+
+type mongoLeaseRepository struct {
+	db *mongo.Database
+}
+
+func NewMongoLeaseRepository(db *mongo.Database) *mongoLeaseRepository {
+	return &mongoLeaseRepository{
+		db: db,
+	}
+}
+
+// Acquire takes the lease when it's expired or extends it when it's already held by the holder. When another holder
+// keeps the lease, the upsert collides with the existing document on its id and the lease is not granted.
+func (r *mongoLeaseRepository) Acquire(ctx context.Context, name string, holder string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	upsert := true
+
+	_, err := r.getCollection().UpdateOne(
+		ctx,
+		bson.M{
+			"_id": name,
+			"$or": bson.A{
+				bson.M{"holder": holder},
+				bson.M{"expiresAt": bson.M{"$lt": now}},
+			},
+		},
+		bson.M{"$set": bson.M{"holder": holder, "expiresAt": now.Add(ttl)}},
+		&options.UpdateOptions{Upsert: &upsert},
+	)
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	} else if err != nil {
+		return false, storageError("failed to acquire lease %s, error: %w", name, err)
+	}
+
+	return true, nil
+}
+
+func (r *mongoLeaseRepository) getCollection() *mongo.Collection {
+	return r.db.Collection(leaseCollection)
+}
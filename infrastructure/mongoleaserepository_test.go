@@ -0,0 +1,51 @@
+package infrastructure
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+	"testing"
+	"time"
+)
+
+func TestLeaseRepository_Acquire(t *testing.T) {
+	options := mtest.NewOptions().ClientType(mtest.Mock).CollectionName(leaseCollection)
+	mt := mtest.New(t, options)
+	defer mt.Close()
+
+	mt.Run(
+		"lease acquired", func(t *mtest.T) {
+			t.AddMockResponses(
+				mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}),
+			)
+
+			repository := NewMongoLeaseRepository(t.DB)
+			acquired, err := repository.Acquire(context.Background(), "lease", "holder", time.Minute)
+
+			require.NoError(t, err)
+			assert.True(t, acquired)
+		},
+	)
+
+	mt.Run(
+		"lease held by another holder", func(t *mtest.T) {
+			t.AddMockResponses(
+				mtest.CreateWriteErrorsResponse(
+					mtest.WriteError{
+						Index:   0,
+						Code:    11000,
+						Message: "duplicate key error",
+					},
+				),
+			)
+
+			repository := NewMongoLeaseRepository(t.DB)
+			acquired, err := repository.Acquire(context.Background(), "lease", "holder", time.Minute)
+
+			require.NoError(t, err)
+			assert.False(t, acquired)
+		},
+	)
+}
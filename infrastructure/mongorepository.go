@@ -2,16 +2,34 @@ package infrastructure
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"go-web-crawler-service/domain"
+	"go-web-crawler-service/tracing"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"log"
+	"regexp"
+	"strconv"
 	"time"
 )
 
 const (
-	channelCollection = "channel"
+	channelCollection        = "channel"
+	channelHistoryCollection = "channel_history"
+
+	// storeChannelIDIndex is the unique index of the store channel id created before the channels were stored
+	// for every country
+	storeChannelIDIndex = "store_1_storeChannelId_1"
+	// applicationNameHistoryIndex is the index of the snapshots created before they were found by the store channel id
+	applicationNameHistoryIndex = "applicationName_1_crawledAt_1"
+
+	namespaceNotFoundCode = 26
+	indexNotFoundCode     = 27
 )
 
 type mongoChannelRepository struct {
@@ -25,40 +43,758 @@ func NewMongoChannelRepository(db *mongo.Database) *mongoChannelRepository {
 }
 
 type channelMongoDTO struct {
-	ApplicationName string    `bson:"applicationName"`
-	Url             string    `bson:"url"`
-	Rating          string    `bson:"rating"`
-	NumberOfRatings uint32    `bson:"numberOfRatings"`
-	UpdatedAt       time.Time `bson:"updatedAt"`
+	ID              primitive.ObjectID `bson:"_id,omitempty"`
+	ApplicationName string             `bson:"applicationName"`
+	Url             string             `bson:"url"`
+	Rating          string             `bson:"rating"`
+	NumberOfRatings uint32             `bson:"numberOfRatings"`
+	Store           string             `bson:"store"`
+	StoreChannelID  string             `bson:"storeChannelId"`
+	// Country of the locale identifies the channel together with the store channel id, channels crawled before
+	// the locales were tracked are assigned the store default by AssignCountry
+	Locale         string `bson:"locale"`
+	Country        string `bson:"country"`
+	ProfileVersion string `bson:"profileVersion,omitempty"`
+	// Proxy and details are stored even when they are empty, so the stale ones are cleared
+	Proxy            string   `bson:"proxy"`
+	Developer        string   `bson:"developer"`
+	Category         string   `bson:"category"`
+	Price            string   `bson:"price"`
+	Description      string   `bson:"description"`
+	ContentRating    string   `bson:"contentRating"`
+	StarDistribution []uint32 `bson:"starDistribution"`
+	// Status is missing in channels crawled before the delisting was detected, they are active
+	Status      string                      `bson:"status,omitempty"`
+	UpdatedAt   time.Time                   `bson:"updatedAt"`
+	DelistedAt  *time.Time                  `bson:"delistedAt,omitempty"`
+	NameChanges []channelNameChangeMongoDTO `bson:"nameChanges,omitempty"`
+}
+
+type channelNameChangeMongoDTO struct {
+	From      string    `bson:"from"`
+	To        string    `bson:"to"`
+	ChangedAt time.Time `bson:"changedAt"`
 }
 
-func newChannelMongoDTO(channel domain.Channel) channelMongoDTO {
+func newChannelMongoDTO(channel domain.Channel, updatedAt time.Time) channelMongoDTO {
 	return channelMongoDTO{
-		ApplicationName: string(channel.ApplicationName),
-		Url:             string(channel.Url),
-		Rating:          fmt.Sprintf("%.1f", channel.Rating),
-		NumberOfRatings: uint32(channel.NumberOfRatings),
-		UpdatedAt:       time.Now(),
+		ApplicationName:  string(channel.ApplicationName),
+		Url:              string(channel.Url),
+		Rating:           formatRating(channel.Rating),
+		NumberOfRatings:  uint32(channel.NumberOfRatings),
+		Store:            string(channel.Store),
+		StoreChannelID:   string(channel.StoreChannelID),
+		Locale:           string(channel.Locale),
+		Country:          string(channel.Locale.Country()),
+		ProfileVersion:   channel.ProfileVersion,
+		Proxy:            channel.Proxy,
+		Developer:        string(channel.Developer),
+		Category:         string(channel.Category),
+		Price:            string(channel.Price),
+		Description:      string(channel.Description),
+		ContentRating:    string(channel.ContentRating),
+		StarDistribution: newStarDistributionMongoDTO(channel.StarDistribution),
+		Status:           string(domain.ChannelStatusActive),
+		UpdatedAt:        updatedAt,
 	}
 }
 
-func (r *mongoChannelRepository) Save(ctx context.Context, channel domain.Channel) error {
-	dto := newChannelMongoDTO(channel)
-	upsert := true
-	_, err := r.getCollection().UpdateOne(
+func (d channelMongoDTO) toView() (*domain.ChannelView, error) {
+	rating, err := parseRating(d.Rating)
+	if err != nil {
+		return nil, err
+	}
+
+	channel := domain.NewChannel(
+		domain.ApplicationName(d.ApplicationName),
+		domain.Url(d.Url),
+		rating,
+		domain.RatingsAmount(d.NumberOfRatings),
+	)
+	channel.Store = domain.Store(d.Store)
+	channel.StoreChannelID = domain.StoreChannelID(d.StoreChannelID)
+	channel.Locale = domain.Locale(d.Locale)
+	channel.ProfileVersion = d.ProfileVersion
+	channel.Proxy = d.Proxy
+	channel.Developer = domain.Developer(d.Developer)
+	channel.Category = domain.Category(d.Category)
+	channel.Price = domain.Price(d.Price)
+	channel.Description = domain.Description(d.Description)
+	channel.ContentRating = domain.ContentRating(d.ContentRating)
+	channel.StarDistribution, err = parseStarDistribution(d.StarDistribution)
+	if err != nil {
+		return nil, err
+	}
+
+	status := domain.ChannelStatus(d.Status)
+	if status == "" {
+		status = domain.ChannelStatusActive
+	}
+
+	nameChanges := make([]domain.ChannelNameChange, 0, len(d.NameChanges))
+	for _, change := range d.NameChanges {
+		nameChanges = append(
+			nameChanges,
+			domain.ChannelNameChange{
+				From:      domain.ApplicationName(change.From),
+				To:        domain.ApplicationName(change.To),
+				ChangedAt: change.ChangedAt,
+			},
+		)
+	}
+
+	return &domain.ChannelView{
+		ID:          d.ID.Hex(),
+		Channel:     *channel,
+		Status:      status,
+		UpdatedAt:   d.UpdatedAt,
+		DelistedAt:  d.DelistedAt,
+		NameChanges: nameChanges,
+	}, nil
+}
+
+type channelSnapshotMongoDTO struct {
+	ApplicationName string `bson:"applicationName"`
+	Url             string `bson:"url"`
+	Rating          string `bson:"rating"`
+	NumberOfRatings uint32 `bson:"numberOfRatings"`
+	Store           string `bson:"store"`
+	StoreChannelID  string `bson:"storeChannelId,omitempty"`
+	Locale          string `bson:"locale,omitempty"`
+	// Country identifies the snapshots of the channel together with the store channel id like in the channels
+	Country        string `bson:"country"`
+	ProfileVersion string `bson:"profileVersion,omitempty"`
+	Proxy          string `bson:"proxy,omitempty"`
+	// StarDistribution holds amounts of 1 to 5 star ratings
+	StarDistribution []uint32  `bson:"starDistribution,omitempty"`
+	CrawledAt        time.Time `bson:"crawledAt"`
+}
+
+func newChannelSnapshotMongoDTO(snapshot domain.ChannelSnapshot) channelSnapshotMongoDTO {
+	return channelSnapshotMongoDTO{
+		ApplicationName:  string(snapshot.ApplicationName),
+		Url:              string(snapshot.Url),
+		Rating:           formatRating(snapshot.Rating),
+		NumberOfRatings:  uint32(snapshot.NumberOfRatings),
+		Store:            string(snapshot.Store),
+		StoreChannelID:   string(snapshot.StoreChannelID),
+		Locale:           string(snapshot.Locale),
+		Country:          string(snapshot.Locale.Country()),
+		ProfileVersion:   snapshot.ProfileVersion,
+		Proxy:            snapshot.Proxy,
+		StarDistribution: newStarDistributionMongoDTO(snapshot.StarDistribution),
+		CrawledAt:        snapshot.CrawledAt,
+	}
+}
+
+func (d channelSnapshotMongoDTO) toSnapshot() (*domain.ChannelSnapshot, error) {
+	rating, err := parseRating(d.Rating)
+	if err != nil {
+		return nil, err
+	}
+
+	starDistribution, err := parseStarDistribution(d.StarDistribution)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.ChannelSnapshot{
+		ApplicationName:  domain.ApplicationName(d.ApplicationName),
+		Url:              domain.Url(d.Url),
+		Rating:           rating,
+		NumberOfRatings:  domain.RatingsAmount(d.NumberOfRatings),
+		Store:            domain.Store(d.Store),
+		StoreChannelID:   domain.StoreChannelID(d.StoreChannelID),
+		Locale:           domain.Locale(d.Locale),
+		ProfileVersion:   d.ProfileVersion,
+		Proxy:            d.Proxy,
+		StarDistribution: starDistribution,
+		CrawledAt:        d.CrawledAt,
+	}, nil
+}
+
+// EnsureIndexes creates indexes required by the repository queries
+func (r *mongoChannelRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.getHistoryCollection().Indexes().CreateOne(
+		ctx, mongo.IndexModel{
+			Keys: bson.D{
+				{Key: "store", Value: 1},
+				{Key: "storeChannelId", Value: 1},
+				{Key: "country", Value: 1},
+				{Key: "crawledAt", Value: 1},
+			},
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create channel history index, error: %w", err)
+	}
+
+	_, err = r.getCollection().Indexes().CreateOne(
+		ctx, mongo.IndexModel{
+			Keys: bson.D{{Key: "updatedAt", Value: 1}},
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create channel update time index, error: %w", err)
+	}
+
+	// Channels which id could not be derived by AssignStoreChannelIDs are left out of the index
+	_, err = r.getCollection().Indexes().CreateOne(
+		ctx, mongo.IndexModel{
+			Keys: bson.D{{Key: "store", Value: 1}, {Key: "storeChannelId", Value: 1}, {Key: "country", Value: 1}},
+			Options: options.Index().
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"storeChannelId": bson.M{"$exists": true}}),
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create unique store channel id index, error: %w", err)
+	}
+
+	return nil
+}
+
+// AssignStore assigns the store to channels crawled before the stores were tracked, so they are updated
+// by the following crawls instead of being duplicated
+func (r *mongoChannelRepository) AssignStore(ctx context.Context, store domain.Store) error {
+	withoutStore := bson.M{"store": bson.M{"$exists": false}}
+	update := bson.M{"$set": bson.M{"store": store}}
+
+	_, err := r.getCollection().UpdateMany(ctx, withoutStore, update)
+	if err != nil {
+		return fmt.Errorf("failed to assign store to channels, error: %w", err)
+	}
+
+	_, err = r.getHistoryCollection().UpdateMany(ctx, withoutStore, update)
+	if err != nil {
+		return fmt.Errorf("failed to assign store to channel snapshots, error: %w", err)
+	}
+
+	return nil
+}
+
+// AssignStoreChannelIDs derives store channel ids of the channels saved before the channels were identified by them.
+// Channels are saved by their name up to now, so the same channel renamed in the past is stored more than once,
+// only its most recently updated copy is kept. The store has to be assigned to the channels first.
+func (r *mongoChannelRepository) AssignStoreChannelIDs(ctx context.Context) error {
+	cursor, err := r.getCollection().Find(
 		ctx,
-		bson.M{"applicationName": channel.ApplicationName}, // TODO: Create unique index on app name
-		bson.M{"$set": dto},
-		&options.UpdateOptions{Upsert: &upsert},
+		bson.M{"storeChannelId": bson.M{"$exists": false}},
+		options.Find().
+			SetSort(bson.D{{Key: "updatedAt", Value: -1}}).
+			SetProjection(bson.M{"url": 1, "store": 1, "applicationName": 1}),
 	)
+	if err != nil {
+		return fmt.Errorf("failed to find channels without store channel id, error: %w", err)
+	}
 
+	var dtos []channelMongoDTO
+	err = cursor.All(ctx, &dtos)
+	if err != nil {
+		return fmt.Errorf("failed to decode channels without store channel id, error: %w", err)
+	}
+
+	for _, dto := range dtos {
+		storeChannelID, err := domain.NewStoreChannelID(domain.Url(dto.Url))
+		if err != nil {
+			log.Printf("Could not assign store channel id to channel %s, error: %v\n", dto.ID.Hex(), err)
+			continue
+		}
+
+		// Channels without the id were crawled in the store default country, missing country is assigned later
+		duplicates, err := r.getCollection().CountDocuments(
+			ctx,
+			bson.M{"store": dto.Store, "storeChannelId": *storeChannelID, "country": bson.M{"$in": bson.A{"", nil}}},
+		)
+		if err != nil {
+			return fmt.Errorf("failed to find channels with store channel id %s, error: %w", *storeChannelID, err)
+		}
+
+		if duplicates > 0 {
+			log.Printf("Removing outdated copy of channel %s named %s\n", *storeChannelID, dto.ApplicationName)
+			_, err = r.getCollection().DeleteOne(ctx, bson.M{"_id": dto.ID})
+			if err != nil {
+				return fmt.Errorf("failed to remove outdated channel %s, error: %w", dto.ID.Hex(), err)
+			}
+			continue
+		}
+
+		_, err = r.getCollection().UpdateOne(
+			ctx,
+			bson.M{"_id": dto.ID},
+			bson.M{"$set": bson.M{"storeChannelId": *storeChannelID}},
+		)
+		if err != nil {
+			return fmt.Errorf("failed to assign store channel id to channel %s, error: %w", dto.ID.Hex(), err)
+		}
+	}
+
+	return r.assignSnapshotStoreChannelIDs(ctx)
+}
+
+// assignSnapshotStoreChannelIDs derives store channel ids of the snapshots appended before the snapshots were found
+// by them
+func (r *mongoChannelRepository) assignSnapshotStoreChannelIDs(ctx context.Context) error {
+	withoutID := bson.M{"storeChannelId": bson.M{"$exists": false}}
+	urls, err := r.getHistoryCollection().Distinct(ctx, "url", withoutID)
+	if err != nil {
+		return fmt.Errorf("failed to find snapshots without store channel id, error: %w", err)
+	}
+
+	for _, value := range urls {
+		url, _ := value.(string)
+		storeChannelID, err := domain.NewStoreChannelID(domain.Url(url))
+		if err != nil {
+			log.Printf("Could not assign store channel id to snapshots of url %s, error: %v\n", url, err)
+			continue
+		}
+
+		_, err = r.getHistoryCollection().UpdateMany(
+			ctx,
+			bson.M{"url": url, "storeChannelId": bson.M{"$exists": false}},
+			bson.M{"$set": bson.M{"storeChannelId": *storeChannelID}},
+		)
+		if err != nil {
+			return fmt.Errorf("failed to assign store channel id to snapshots of url %s, error: %w", url, err)
+		}
+	}
+
+	return nil
+}
+
+// AssignCountry assigns the store default country to channels crawled before the locales were tracked, so they are
+// updated by the following crawls in the store default locale. Snapshots get the country of their locale. Indexes
+// of the store channel id alone and of the snapshot names are dropped, as they were replaced by the indexes
+// of the store channel id in the country.
+func (r *mongoChannelRepository) AssignCountry(ctx context.Context) error {
+	withoutCountry := bson.M{"country": bson.M{"$exists": false}}
+	_, err := r.getCollection().UpdateMany(ctx, withoutCountry, bson.M{"$set": bson.M{"locale": "", "country": ""}})
+	if err != nil {
+		return fmt.Errorf("failed to assign country to channels, error: %w", err)
+	}
+
+	locales, err := r.getHistoryCollection().Distinct(ctx, "locale", withoutCountry)
+	if err != nil {
+		return fmt.Errorf("failed to find locales of snapshots without country, error: %w", err)
+	}
+
+	for _, value := range locales {
+		locale, _ := value.(string)
+		_, err = r.getHistoryCollection().UpdateMany(
+			ctx,
+			bson.M{"locale": locale, "country": bson.M{"$exists": false}},
+			bson.M{"$set": bson.M{"country": domain.Locale(locale).Country()}},
+		)
+		if err != nil {
+			return fmt.Errorf("failed to assign country to snapshots of locale %s, error: %w", locale, err)
+		}
+	}
+
+	// Snapshots without the locale were crawled in the store default
+	_, err = r.getHistoryCollection().UpdateMany(ctx, withoutCountry, bson.M{"$set": bson.M{"country": ""}})
+	if err != nil {
+		return fmt.Errorf("failed to assign country to snapshots, error: %w", err)
+	}
+
+	err = dropIndex(ctx, r.getCollection(), storeChannelIDIndex)
+	if err != nil {
+		return err
+	}
+
+	return dropIndex(ctx, r.getHistoryCollection(), applicationNameHistoryIndex)
+}
+
+// dropIndex drops the index of the collection, index or collection that does not exist is not an error
+func dropIndex(ctx context.Context, collection *mongo.Collection, name string) error {
+	_, err := collection.Indexes().DropOne(ctx, name)
+	var commandErr mongo.CommandError
+	notFound := errors.As(err, &commandErr) &&
+		(commandErr.Code == indexNotFoundCode || commandErr.Code == namespaceNotFoundCode)
+	if notFound {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to drop index %s, error: %w", name, err)
+	}
+
+	return nil
+}
+
+// Save updates the current state of the channel and then appends snapshot of the crawled channel to the history,
+// so the history has no snapshots of the crawls that were not stored. Channels are identified by their store,
+// store channel id and country, so the renames are recorded.
+func (r *mongoChannelRepository) Save(ctx context.Context, channel domain.Channel) (err error) {
+	ctx, span := tracing.Tracer().Start(
+		ctx,
+		"save channel",
+		trace.WithAttributes(
+			attribute.String("applicationName", string(channel.ApplicationName)),
+			attribute.String("store", string(channel.Store)),
+			attribute.String("locale", string(channel.Locale)),
+		),
+	)
+	defer func() {
+		tracing.End(span, err)
+	}()
+
+	if channel.StoreChannelID == "" {
+		return domain.NewCrawlError(
+			domain.CrawlErrorInvalidData,
+			fmt.Errorf("channel %s has no store channel id", channel.ApplicationName),
+		)
+	}
+
+	crawledAt := time.Now()
+
+	dto := newChannelMongoDTO(channel, crawledAt)
+	filter := bson.M{
+		"store":          channel.Store,
+		"storeChannelId": channel.StoreChannelID,
+		"country":        channel.Locale.Country(),
+	}
+	var previous channelMongoDTO
+	err = r.getCollection().FindOneAndUpdate(
+		ctx,
+		filter,
+		// Channel found again after it was delisted is restored
+		bson.M{"$set": dto, "$unset": bson.M{"delistedAt": ""}},
+		options.FindOneAndUpdate().
+			SetUpsert(true).
+			SetReturnDocument(options.Before).
+			SetProjection(bson.M{"applicationName": 1}),
+	).Decode(&previous)
+	created := errors.Is(err, mongo.ErrNoDocuments)
+	if err != nil && !created {
+		return storageError("failed to save channel in MongoDB collection: %v, error: %w", dto, err)
+	}
+
+	snapshotDTO := newChannelSnapshotMongoDTO(*domain.NewChannelSnapshot(channel, crawledAt))
+	_, err = r.getHistoryCollection().InsertOne(ctx, snapshotDTO)
+	if err != nil {
+		return storageError("failed to save channel snapshot in MongoDB collection: %v, error: %w", snapshotDTO, err)
+	}
+
+	// Delisted stub has no name to change
+	if created || previous.ApplicationName == "" || previous.ApplicationName == string(channel.ApplicationName) {
+		return nil
+	}
+
+	nameChange := channelNameChangeMongoDTO{
+		From:      previous.ApplicationName,
+		To:        string(channel.ApplicationName),
+		ChangedAt: crawledAt,
+	}
+	_, err = r.getCollection().UpdateOne(ctx, filter, bson.M{"$push": bson.M{"nameChanges": nameChange}})
 	if err != nil {
-		return fmt.Errorf("failed to save channel in MongoDB collection: %v, error: %w", dto, err)
+		return storageError("failed to save name change of channel %s, error: %w", channel.StoreChannelID, err)
 	}
 
 	return nil
 }
 
+// MarkDelisted marks the channel as delisted in the country, the time it was found delisted first is kept.
+// Channel never crawled in the country is stored as the delisted stub identified by its store channel id.
+func (r *mongoChannelRepository) MarkDelisted(
+	ctx context.Context,
+	store domain.Store,
+	url domain.Url,
+	locale domain.Locale,
+	delistedAt time.Time,
+) error {
+	storeChannelID, err := domain.NewStoreChannelID(url)
+	if err != nil {
+		return domain.NewCrawlError(domain.CrawlErrorInvalidData, err)
+	}
+
+	_, err = r.getCollection().UpdateOne(
+		ctx,
+		bson.M{"store": store, "storeChannelId": storeChannelID, "country": locale.Country()},
+		bson.M{
+			"$set": bson.M{"status": domain.ChannelStatusDelisted},
+			"$min": bson.M{"delistedAt": delistedAt},
+			"$setOnInsert": bson.M{
+				"url":             url,
+				"locale":          locale,
+				"rating":          formatRating(0),
+				"numberOfRatings": 0,
+				"updatedAt":       delistedAt,
+			},
+		},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return storageError("failed to mark channel %s as delisted, error: %w", url, err)
+	}
+
+	return nil
+}
+
+// FindSnapshots returns snapshots of the store channel crawled in the country in [from, to) time range ordered
+// from the oldest one
+func (r *mongoChannelRepository) FindSnapshots(
+	ctx context.Context,
+	store domain.Store,
+	storeChannelID domain.StoreChannelID,
+	country domain.Country,
+	from time.Time,
+	to time.Time,
+) ([]domain.ChannelSnapshot, error) {
+	cursor, err := r.getHistoryCollection().Find(
+		ctx,
+		bson.M{
+			"store":          store,
+			"storeChannelId": storeChannelID,
+			"country":        country,
+			"crawledAt":      bson.M{"$gte": from, "$lt": to},
+		},
+		options.Find().SetSort(bson.D{{Key: "crawledAt", Value: 1}}),
+	)
+	if err != nil {
+		return nil, storageError("failed to find snapshots of channel %s, error: %w", storeChannelID, err)
+	}
+
+	var dtos []channelSnapshotMongoDTO
+	err = cursor.All(ctx, &dtos)
+	if err != nil {
+		return nil, storageError("failed to decode snapshots of channel %s, error: %w", storeChannelID, err)
+	}
+
+	snapshots := make([]domain.ChannelSnapshot, 0, len(dtos))
+	for _, dto := range dtos {
+		snapshot, err := dto.toSnapshot()
+		if err != nil {
+			return nil, fmt.Errorf("invalid snapshot of channel %s, error: %w", storeChannelID, err)
+		}
+
+		snapshots = append(snapshots, *snapshot)
+	}
+
+	return snapshots, nil
+}
+
+func (r *mongoChannelRepository) FindByID(ctx context.Context, id string) (*domain.ChannelView, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, domain.ErrChannelNotFound
+	}
+
+	return r.findOne(ctx, bson.M{"_id": objectID})
+}
+
+func (r *mongoChannelRepository) FindByUrl(
+	ctx context.Context,
+	url domain.Url,
+	country domain.Country,
+) (*domain.ChannelView, error) {
+	return r.findOne(ctx, bson.M{"url": url, "country": country})
+}
+
+// List returns channels matching the filter ordered by their id, next page starts after the returned cursor
+func (r *mongoChannelRepository) List(ctx context.Context, filter domain.ChannelFilter) (*domain.ChannelPage, error) {
+	query := bson.M{}
+	if filter.MinRating != nil {
+		// Rating is stored as one decimal string of 0-5 value, so lexical order is the same as the numeric one
+		query["rating"] = bson.M{"$gte": formatRating(*filter.MinRating)}
+	}
+	if filter.MinNumberOfRatings != nil {
+		query["numberOfRatings"] = bson.M{"$gte": uint32(*filter.MinNumberOfRatings)}
+	}
+	if filter.NamePrefix != "" {
+		query["applicationName"] = bson.M{"$regex": "^" + regexp.QuoteMeta(filter.NamePrefix)}
+	}
+	if filter.UpdatedSince != nil {
+		query["updatedAt"] = bson.M{"$gte": *filter.UpdatedSince}
+	}
+	if filter.Country != nil {
+		query["country"] = *filter.Country
+	}
+	switch filter.Status {
+	case domain.ChannelStatusActive:
+		query["status"] = bson.M{"$ne": domain.ChannelStatusDelisted}
+	case domain.ChannelStatusDelisted:
+		query["status"] = domain.ChannelStatusDelisted
+	}
+	if filter.Cursor != "" {
+		after, err := primitive.ObjectIDFromHex(filter.Cursor)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", domain.ErrInvalidCursor, filter.Cursor)
+		}
+		query["_id"] = bson.M{"$gt": after}
+	}
+
+	// One more document is fetched to find out whether there is a next page
+	cursor, err := r.getCollection().Find(
+		ctx,
+		query,
+		options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(int64(filter.Limit+1)),
+	)
+	if err != nil {
+		return nil, storageError("failed to list channels, error: %w", err)
+	}
+
+	var dtos []channelMongoDTO
+	err = cursor.All(ctx, &dtos)
+	if err != nil {
+		return nil, storageError("failed to decode channels, error: %w", err)
+	}
+
+	page := &domain.ChannelPage{Channels: make([]domain.ChannelView, 0, len(dtos))}
+	if len(dtos) > filter.Limit {
+		dtos = dtos[:filter.Limit]
+		page.NextCursor = dtos[len(dtos)-1].ID.Hex()
+	}
+
+	for _, dto := range dtos {
+		view, err := dto.toView()
+		if err != nil {
+			return nil, fmt.Errorf("invalid channel %s, error: %w", dto.ID.Hex(), err)
+		}
+
+		page.Channels = append(page.Channels, *view)
+	}
+
+	return page, nil
+}
+
+// FindStale returns urls of stale active channels with the locales they were crawled in starting from the least
+// recently updated ones
+func (r *mongoChannelRepository) FindStale(
+	ctx context.Context,
+	criteria domain.StaleChannelCriteria,
+) ([]domain.CrawlTarget, error) {
+	numberOfRatings := bson.M{"$gte": uint32(criteria.MinNumberOfRatings)}
+	if criteria.MaxNumberOfRatings != nil {
+		numberOfRatings["$lt"] = uint32(*criteria.MaxNumberOfRatings)
+	}
+
+	cursor, err := r.getCollection().Find(
+		ctx,
+		bson.M{
+			"numberOfRatings": numberOfRatings,
+			"updatedAt":       bson.M{"$lt": criteria.StaleBefore},
+			"status":          bson.M{"$ne": domain.ChannelStatusDelisted},
+			"$or": bson.A{
+				bson.M{"scheduledAt": bson.M{"$exists": false}},
+				bson.M{"scheduledAt": bson.M{"$lt": criteria.StaleBefore}},
+			},
+		},
+		options.Find().
+			SetSort(bson.D{{Key: "updatedAt", Value: 1}}).
+			SetLimit(int64(criteria.Limit)).
+			SetProjection(bson.M{"url": 1, "locale": 1}),
+	)
+	if err != nil {
+		return nil, storageError("failed to find stale channels, error: %w", err)
+	}
+
+	var dtos []struct {
+		Url    string `bson:"url"`
+		Locale string `bson:"locale"`
+	}
+	err = cursor.All(ctx, &dtos)
+	if err != nil {
+		return nil, storageError("failed to decode stale channels, error: %w", err)
+	}
+
+	targets := make([]domain.CrawlTarget, 0, len(dtos))
+	for _, dto := range dtos {
+		targets = append(targets, domain.CrawlTarget{Url: domain.Url(dto.Url), Locale: domain.Locale(dto.Locale)})
+	}
+
+	return targets, nil
+}
+
+// MarkScheduled stores the time of scheduling the recrawl, so the channels are not scheduled again
+// until they become stale once more
+func (r *mongoChannelRepository) MarkScheduled(
+	ctx context.Context,
+	targets []domain.CrawlTarget,
+	scheduledAt time.Time,
+) error {
+	channels := make(bson.A, 0, len(targets))
+	for _, target := range targets {
+		channels = append(channels, bson.M{"url": target.Url, "country": target.Locale.Country()})
+	}
+
+	_, err := r.getCollection().UpdateMany(
+		ctx,
+		bson.M{"$or": channels},
+		bson.M{"$set": bson.M{"scheduledAt": scheduledAt}},
+	)
+	if err != nil {
+		return storageError("failed to mark %d channels as scheduled, error: %w", len(targets), err)
+	}
+
+	return nil
+}
+
+func (r *mongoChannelRepository) findOne(ctx context.Context, filter bson.M) (*domain.ChannelView, error) {
+	var dto channelMongoDTO
+	err := r.getCollection().FindOne(ctx, filter).Decode(&dto)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.ErrChannelNotFound
+		}
+
+		return nil, storageError("failed to find channel, error: %w", err)
+	}
+
+	view, err := dto.toView()
+	if err != nil {
+		return nil, fmt.Errorf("invalid channel %s, error: %w", dto.ID.Hex(), err)
+	}
+
+	return view, nil
+}
+
 func (r *mongoChannelRepository) getCollection() *mongo.Collection {
 	return r.db.Collection(channelCollection)
 }
+
+func (r *mongoChannelRepository) getHistoryCollection() *mongo.Collection {
+	return r.db.Collection(channelHistoryCollection)
+}
+
+// storageError marks the failure of the database, so it's handled as the storage failure by all the layers
+func storageError(format string, args ...interface{}) error {
+	return domain.NewCrawlError(domain.CrawlErrorStorage, fmt.Errorf(format, args...))
+}
+
+func formatRating(rating domain.Rating) string {
+	return fmt.Sprintf("%.1f", rating)
+}
+
+func newStarDistributionMongoDTO(distribution *domain.StarDistribution) []uint32 {
+	if distribution == nil {
+		return nil
+	}
+
+	amounts := make([]uint32, 0, len(distribution))
+	for _, amount := range distribution {
+		amounts = append(amounts, uint32(amount))
+	}
+
+	return amounts
+}
+
+// parseStarDistribution returns nil distribution for channels crawled without it
+func parseStarDistribution(amounts []uint32) (*domain.StarDistribution, error) {
+	if len(amounts) == 0 {
+		return nil, nil
+	}
+
+	distribution, err := domain.NewStarDistribution(amounts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse stored star distribution, error: %w", err)
+	}
+
+	return distribution, nil
+}
+
+func parseRating(value string) (domain.Rating, error) {
+	rating, err := strconv.ParseFloat(value, 32)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse stored rating %s, error: %w", value, err)
+	}
+
+	return domain.Rating(rating), nil
+}
@@ -6,8 +6,10 @@ import (
 	"github.com/stretchr/testify/require"
 	"go-web-crawler-service/domain"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
 	"testing"
+	"time"
 )
 
 const (
@@ -25,44 +27,88 @@ func TestChannelRepository_Save(t *testing.T) {
 	mt.Run(
 		"save channel successfully", func(t *mtest.T) {
 			ctx := context.Background()
-			channel := domain.NewChannel(
-				testRepoApplicationName,
-				testRepoChannelURL,
-				testRepoRating,
-				testRepoRatingsAmount,
-			)
+			channel := newTestRepoChannel()
 
 			t.AddMockResponses(
 				mtest.CreateSuccessResponse(
 					bson.E{
-						Key:   "n",
-						Value: 1,
-					},
-					bson.E{
-						Key:   "nModified",
-						Value: 1,
+						Key:   "value",
+						Value: bson.D{{Key: "applicationName", Value: string(testRepoApplicationName)}},
 					},
 				),
+				mtest.CreateSuccessResponse(),
 			)
 
 			repository := NewMongoChannelRepository(t.DB)
 			err := repository.Save(ctx, *channel)
 
 			require.NoError(t, err)
-			assert.NotNil(t, t.GetSucceededEvent())
+
+			updateEvent := t.GetStartedEvent()
+			require.NotNil(t, updateEvent)
+			assert.Equal(t, "findAndModify", updateEvent.CommandName)
+			assert.Equal(t, channelCollection, updateEvent.Command.Lookup("findAndModify").StringValue())
+			assert.Equal(t, "12", updateEvent.Command.Lookup("query", "storeChannelId").StringValue())
+			assert.Equal(t, "GB", updateEvent.Command.Lookup("query", "country").StringValue())
+
+			insertEvent := t.GetStartedEvent()
+			require.NotNil(t, insertEvent)
+			assert.Equal(t, "insert", insertEvent.CommandName)
+			assert.Equal(t, channelHistoryCollection, insertEvent.Command.Lookup("insert").StringValue())
+			snapshot := insertEvent.Command.Lookup("documents").Array().Index(0).Value().Document()
+			assert.Equal(t, "12", snapshot.Lookup("storeChannelId").StringValue())
+			assert.Equal(t, "GB", snapshot.Lookup("country").StringValue())
+			assert.Nil(t, t.GetStartedEvent(), "name change must not be recorded")
 		},
 	)
 
 	mt.Run(
-		"save channel error", func(t *mtest.T) {
+		"renamed channel", func(t *mtest.T) {
 			ctx := context.Background()
-			channel := domain.NewChannel(
-				testRepoApplicationName,
-				testRepoChannelURL,
-				testRepoRating,
-				testRepoRatingsAmount,
+			channel := newTestRepoChannel()
+
+			t.AddMockResponses(
+				mtest.CreateSuccessResponse(
+					bson.E{Key: "value", Value: bson.D{{Key: "applicationName", Value: "Google Play"}}},
+				),
+				mtest.CreateSuccessResponse(),
+				mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}),
 			)
 
+			repository := NewMongoChannelRepository(t.DB)
+			err := repository.Save(ctx, *channel)
+
+			require.NoError(t, err)
+
+			t.GetStartedEvent()
+			t.GetStartedEvent()
+			pushEvent := t.GetStartedEvent()
+			require.NotNil(t, pushEvent)
+			assert.Equal(t, "update", pushEvent.CommandName)
+			nameChange := pushEvent.Command.Lookup("updates").Array().Index(0).Value().Document().
+				Lookup("u", "$push", "nameChanges")
+			assert.Equal(t, "Google Play", nameChange.Document().Lookup("from").StringValue())
+			assert.Equal(t, string(testRepoApplicationName), nameChange.Document().Lookup("to").StringValue())
+		},
+	)
+
+	mt.Run(
+		"channel without store channel id", func(t *mtest.T) {
+			channel := newTestRepoChannel()
+			channel.StoreChannelID = ""
+
+			repository := NewMongoChannelRepository(t.DB)
+			err := repository.Save(context.Background(), *channel)
+
+			assert.Equal(t, domain.CrawlErrorInvalidData, domain.CrawlErrorKindOf(err))
+		},
+	)
+
+	mt.Run(
+		"save channel error", func(t *mtest.T) {
+			ctx := context.Background()
+			channel := newTestRepoChannel()
+
 			t.AddMockResponses(
 				mtest.CreateCommandErrorResponse(
 					mtest.CommandError{
@@ -77,6 +123,411 @@ func TestChannelRepository_Save(t *testing.T) {
 			err := repository.Save(ctx, *channel)
 
 			require.Error(t, err)
+
+			updateEvent := t.GetStartedEvent()
+			require.NotNil(t, updateEvent)
+			assert.Equal(t, "findAndModify", updateEvent.CommandName)
+			assert.Nil(t, t.GetStartedEvent(), "snapshot of the channel that was not saved must not be recorded")
 		},
 	)
+
+	mt.Run(
+		"new channel", func(t *mtest.T) {
+			ctx := context.Background()
+			channel := newTestRepoChannel()
+
+			t.AddMockResponses(
+				mtest.CreateSuccessResponse(bson.E{Key: "value", Value: nil}),
+				mtest.CreateSuccessResponse(),
+			)
+
+			repository := NewMongoChannelRepository(t.DB)
+			err := repository.Save(ctx, *channel)
+
+			require.NoError(t, err)
+
+			t.GetStartedEvent()
+			insertEvent := t.GetStartedEvent()
+			require.NotNil(t, insertEvent)
+			assert.Equal(t, "insert", insertEvent.CommandName)
+			assert.Nil(t, t.GetStartedEvent(), "name change of the new channel must not be recorded")
+		},
+	)
+}
+
+func TestChannelRepository_MarkDelisted(t *testing.T) {
+	options := mtest.NewOptions().ClientType(mtest.Mock).CollectionName(channelCollection)
+	mt := mtest.New(t, options)
+	defer mt.Close()
+
+	mt.Run(
+		"mark channel delisted", func(t *mtest.T) {
+			t.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}))
+
+			repository := NewMongoChannelRepository(t.DB)
+			err := repository.MarkDelisted(context.Background(), "roku", testRepoChannelURL, "en-GB", time.Now())
+
+			require.NoError(t, err)
+
+			updateEvent := t.GetStartedEvent()
+			require.NotNil(t, updateEvent)
+			statement := updateEvent.Command.Lookup("updates").Array().Index(0).Value().Document()
+			assert.Equal(t, "roku", statement.Lookup("q", "store").StringValue())
+			assert.Equal(t, "google.com/", statement.Lookup("q", "storeChannelId").StringValue())
+			assert.Equal(t, "GB", statement.Lookup("q", "country").StringValue())
+			assert.True(t, statement.Lookup("upsert").Boolean())
+			update := statement.Lookup("u").Document()
+			assert.Equal(t, string(domain.ChannelStatusDelisted), update.Lookup("$set", "status").StringValue())
+			assert.Equal(t, string(testRepoChannelURL), update.Lookup("$setOnInsert", "url").StringValue())
+		},
+	)
+
+	mt.Run(
+		"invalid url", func(t *mtest.T) {
+			repository := NewMongoChannelRepository(t.DB)
+			err := repository.MarkDelisted(context.Background(), "roku", "not a url", "", time.Now())
+
+			require.Equal(t, domain.CrawlErrorInvalidData, domain.CrawlErrorKindOf(err))
+			assert.Nil(t, t.GetStartedEvent())
+		},
+	)
+}
+
+func TestChannelRepository_FindSnapshots(t *testing.T) {
+	options := mtest.NewOptions().ClientType(mtest.Mock).CollectionName(channelHistoryCollection)
+	mt := mtest.New(t, options)
+	defer mt.Close()
+
+	mt.Run(
+		"find snapshots successfully", func(t *mtest.T) {
+			ctx := context.Background()
+			crawledAt := time.Date(2022, 4, 1, 12, 0, 0, 0, time.UTC)
+			namespace := t.DB.Name() + "." + channelHistoryCollection
+
+			t.AddMockResponses(
+				mtest.CreateCursorResponse(
+					0, namespace, mtest.FirstBatch,
+					bson.D{
+						{Key: "applicationName", Value: string(testRepoApplicationName)},
+						{Key: "url", Value: string(testRepoChannelURL)},
+						{Key: "rating", Value: "3.8"},
+						{Key: "numberOfRatings", Value: int64(testRepoRatingsAmount)},
+						{Key: "crawledAt", Value: crawledAt},
+					},
+				),
+			)
+
+			repository := NewMongoChannelRepository(t.DB)
+			snapshots, err := repository.FindSnapshots(
+				ctx,
+				"roku",
+				"12",
+				"GB",
+				crawledAt.Add(-time.Hour),
+				crawledAt.Add(time.Hour),
+			)
+
+			require.NoError(t, err)
+			findEvent := t.GetStartedEvent()
+			require.NotNil(t, findEvent)
+			filter := findEvent.Command.Lookup("filter").Document()
+			assert.Equal(t, "roku", filter.Lookup("store").StringValue())
+			assert.Equal(t, "12", filter.Lookup("storeChannelId").StringValue())
+			assert.Equal(t, "GB", filter.Lookup("country").StringValue())
+			_, err = filter.LookupErr("applicationName")
+			assert.Error(t, err, "snapshots of the renamed channel have to be found")
+			require.Len(t, snapshots, 1)
+			assert.Equal(t, testRepoApplicationName, snapshots[0].ApplicationName)
+			assert.Equal(t, testRepoChannelURL, snapshots[0].Url)
+			assert.Equal(t, testRepoRating, snapshots[0].Rating)
+			assert.Equal(t, testRepoRatingsAmount, snapshots[0].NumberOfRatings)
+			assert.True(t, crawledAt.Equal(snapshots[0].CrawledAt))
+		},
+	)
+
+	mt.Run(
+		"find snapshots error", func(t *mtest.T) {
+			ctx := context.Background()
+
+			t.AddMockResponses(
+				mtest.CreateCommandErrorResponse(
+					mtest.CommandError{
+						Code:    100,
+						Message: "test error",
+						Name:    "test",
+					},
+				),
+			)
+
+			repository := NewMongoChannelRepository(t.DB)
+			_, err := repository.FindSnapshots(ctx, "roku", "12", "", time.Now().Add(-time.Hour), time.Now())
+
+			require.Error(t, err)
+		},
+	)
+}
+
+func TestChannelRepository_FindByUrl(t *testing.T) {
+	options := mtest.NewOptions().ClientType(mtest.Mock).CollectionName(channelCollection)
+	mt := mtest.New(t, options)
+	defer mt.Close()
+
+	mt.Run(
+		"find channel successfully", func(t *mtest.T) {
+			ctx := context.Background()
+			id := primitive.NewObjectID()
+			namespace := t.DB.Name() + "." + channelCollection
+
+			t.AddMockResponses(
+				mtest.CreateCursorResponse(
+					0, namespace, mtest.FirstBatch,
+					newTestRepoChannelDocument(id, string(testRepoApplicationName)),
+				),
+			)
+
+			repository := NewMongoChannelRepository(t.DB)
+			channel, err := repository.FindByUrl(ctx, testRepoChannelURL, "GB")
+
+			require.NoError(t, err)
+			assert.Equal(t, id.Hex(), channel.ID)
+			assert.Equal(t, domain.Locale("en-GB"), channel.Channel.Locale)
+			assert.Equal(t, testRepoApplicationName, channel.Channel.ApplicationName)
+			assert.Equal(t, testRepoRating, channel.Channel.Rating)
+			assert.Equal(t, testRepoRatingsAmount, channel.Channel.NumberOfRatings)
+			assert.Equal(t, domain.ChannelStatusActive, channel.Status)
+			assert.Nil(t, channel.DelistedAt)
+			assert.EqualValues(t, "Google LLC", channel.Channel.Developer)
+			require.NotNil(t, channel.Channel.StarDistribution)
+			assert.Equal(t, domain.StarDistribution{1, 2, 3, 4, 989}, *channel.Channel.StarDistribution)
+		},
+	)
+
+	mt.Run(
+		"channel not found", func(t *mtest.T) {
+			ctx := context.Background()
+			namespace := t.DB.Name() + "." + channelCollection
+
+			t.AddMockResponses(mtest.CreateCursorResponse(0, namespace, mtest.FirstBatch))
+
+			repository := NewMongoChannelRepository(t.DB)
+			_, err := repository.FindByUrl(ctx, testRepoChannelURL, "")
+
+			require.ErrorIs(t, err, domain.ErrChannelNotFound)
+		},
+	)
+}
+
+func TestChannelRepository_List(t *testing.T) {
+	options := mtest.NewOptions().ClientType(mtest.Mock).CollectionName(channelCollection)
+	mt := mtest.New(t, options)
+	defer mt.Close()
+
+	mt.Run(
+		"list channels with next page", func(t *mtest.T) {
+			ctx := context.Background()
+			firstID := primitive.NewObjectID()
+			secondID := primitive.NewObjectID()
+			namespace := t.DB.Name() + "." + channelCollection
+
+			t.AddMockResponses(
+				mtest.CreateCursorResponse(
+					0, namespace, mtest.FirstBatch,
+					newTestRepoChannelDocument(firstID, "Google"),
+					newTestRepoChannelDocument(secondID, "Google TV"),
+				),
+			)
+
+			repository := NewMongoChannelRepository(t.DB)
+			page, err := repository.List(ctx, domain.ChannelFilter{NamePrefix: "Google", Limit: 1})
+
+			require.NoError(t, err)
+			require.Len(t, page.Channels, 1)
+			assert.Equal(t, firstID.Hex(), page.Channels[0].ID)
+			assert.Equal(t, firstID.Hex(), page.NextCursor)
+		},
+	)
+
+	mt.Run(
+		"list channels last page", func(t *mtest.T) {
+			ctx := context.Background()
+			namespace := t.DB.Name() + "." + channelCollection
+
+			t.AddMockResponses(
+				mtest.CreateCursorResponse(
+					0, namespace, mtest.FirstBatch,
+					newTestRepoChannelDocument(primitive.NewObjectID(), "Google"),
+				),
+			)
+
+			country := domain.Country("GB")
+			repository := NewMongoChannelRepository(t.DB)
+			page, err := repository.List(ctx, domain.ChannelFilter{Country: &country, Limit: 10})
+
+			require.NoError(t, err)
+			assert.Len(t, page.Channels, 1)
+			assert.Empty(t, page.NextCursor)
+
+			findEvent := t.GetStartedEvent()
+			require.NotNil(t, findEvent)
+			assert.Equal(t, "GB", findEvent.Command.Lookup("filter", "country").StringValue())
+		},
+	)
+
+	mt.Run(
+		"invalid cursor", func(t *mtest.T) {
+			repository := NewMongoChannelRepository(t.DB)
+			_, err := repository.List(context.Background(), domain.ChannelFilter{Cursor: "invalid", Limit: 10})
+
+			require.ErrorIs(t, err, domain.ErrInvalidCursor)
+		},
+	)
+}
+
+func TestChannelRepository_FindStale(t *testing.T) {
+	options := mtest.NewOptions().ClientType(mtest.Mock).CollectionName(channelCollection)
+	mt := mtest.New(t, options)
+	defer mt.Close()
+
+	mt.Run(
+		"stale channels with their locales", func(t *mtest.T) {
+			namespace := t.DB.Name() + "." + channelCollection
+			t.AddMockResponses(
+				mtest.CreateCursorResponse(
+					0, namespace, mtest.FirstBatch,
+					bson.D{{Key: "url", Value: string(testRepoChannelURL)}, {Key: "locale", Value: "en-GB"}},
+					bson.D{{Key: "url", Value: string(testRepoChannelURL)}, {Key: "locale", Value: ""}},
+				),
+			)
+
+			repository := NewMongoChannelRepository(t.DB)
+			targets, err := repository.FindStale(
+				context.Background(),
+				domain.StaleChannelCriteria{StaleBefore: time.Now(), Limit: 10},
+			)
+
+			require.NoError(t, err)
+			assert.Equal(
+				t,
+				[]domain.CrawlTarget{{Url: testRepoChannelURL, Locale: "en-GB"}, {Url: testRepoChannelURL}},
+				targets,
+			)
+		},
+	)
+}
+
+func TestChannelRepository_MarkScheduled(t *testing.T) {
+	options := mtest.NewOptions().ClientType(mtest.Mock).CollectionName(channelCollection)
+	mt := mtest.New(t, options)
+	defer mt.Close()
+
+	mt.Run(
+		"channels marked in their countries", func(t *mtest.T) {
+			t.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 2}))
+
+			repository := NewMongoChannelRepository(t.DB)
+			err := repository.MarkScheduled(
+				context.Background(),
+				[]domain.CrawlTarget{{Url: testRepoChannelURL, Locale: "en-GB"}, {Url: testRepoChannelURL}},
+				time.Now(),
+			)
+
+			require.NoError(t, err)
+
+			updateEvent := t.GetStartedEvent()
+			require.NotNil(t, updateEvent)
+			channels := updateEvent.Command.Lookup("updates").Array().Index(0).Value().Document().
+				Lookup("q", "$or").Array()
+			assert.Equal(t, "GB", channels.Index(0).Value().Document().Lookup("country").StringValue())
+			assert.Equal(t, "", channels.Index(1).Value().Document().Lookup("country").StringValue())
+		},
+	)
+}
+
+func TestChannelRepository_AssignCountry(t *testing.T) {
+	options := mtest.NewOptions().ClientType(mtest.Mock).CollectionName(channelCollection)
+	mt := mtest.New(t, options)
+	defer mt.Close()
+
+	mt.Run(
+		"store default country assigned and old indexes dropped", func(t *mtest.T) {
+			t.AddMockResponses(
+				mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 3}),
+				mtest.CreateSuccessResponse(bson.E{Key: "values", Value: bson.A{"en-GB"}}),
+				mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 2}),
+				mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}),
+				mtest.CreateSuccessResponse(),
+				mtest.CreateSuccessResponse(),
+			)
+
+			repository := NewMongoChannelRepository(t.DB)
+			err := repository.AssignCountry(context.Background())
+
+			require.NoError(t, err)
+
+			t.GetStartedEvent()
+			t.GetStartedEvent()
+			localeEvent := t.GetStartedEvent()
+			require.NotNil(t, localeEvent)
+			statement := localeEvent.Command.Lookup("updates").Array().Index(0).Value().Document()
+			assert.Equal(t, "en-GB", statement.Lookup("q", "locale").StringValue())
+			assert.Equal(t, "GB", statement.Lookup("u", "$set", "country").StringValue())
+			t.GetStartedEvent()
+			dropEvent := t.GetStartedEvent()
+			require.NotNil(t, dropEvent)
+			assert.Equal(t, "dropIndexes", dropEvent.CommandName)
+			assert.Equal(t, storeChannelIDIndex, dropEvent.Command.Lookup("index").StringValue())
+			dropEvent = t.GetStartedEvent()
+			require.NotNil(t, dropEvent)
+			assert.Equal(t, channelHistoryCollection, dropEvent.Command.Lookup("dropIndexes").StringValue())
+			assert.Equal(t, applicationNameHistoryIndex, dropEvent.Command.Lookup("index").StringValue())
+		},
+	)
+
+	mt.Run(
+		"old indexes already dropped", func(t *mtest.T) {
+			indexNotFound := mtest.CreateCommandErrorResponse(
+				mtest.CommandError{Code: indexNotFoundCode, Message: "index not found", Name: "IndexNotFound"},
+			)
+			t.AddMockResponses(
+				mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}),
+				mtest.CreateSuccessResponse(bson.E{Key: "values", Value: bson.A{}}),
+				mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}),
+				indexNotFound,
+				indexNotFound,
+			)
+
+			repository := NewMongoChannelRepository(t.DB)
+			err := repository.AssignCountry(context.Background())
+
+			require.NoError(t, err)
+		},
+	)
+}
+
+func newTestRepoChannel() *domain.Channel {
+	channel := domain.NewChannel(
+		testRepoApplicationName,
+		testRepoChannelURL,
+		testRepoRating,
+		testRepoRatingsAmount,
+	)
+	channel.StoreChannelID = "12"
+	channel.Locale = "en-GB"
+
+	return channel
+}
+
+func newTestRepoChannelDocument(id primitive.ObjectID, name string) bson.D {
+	return bson.D{
+		{Key: "_id", Value: id},
+		{Key: "applicationName", Value: name},
+		{Key: "url", Value: string(testRepoChannelURL)},
+		{Key: "locale", Value: "en-GB"},
+		{Key: "country", Value: "GB"},
+		{Key: "rating", Value: "3.8"},
+		{Key: "numberOfRatings", Value: int64(testRepoRatingsAmount)},
+		{Key: "developer", Value: "Google LLC"},
+		{Key: "starDistribution", Value: bson.A{int32(1), int32(2), int32(3), int32(4), int32(989)}},
+		{Key: "updatedAt", Value: time.Now()},
+	}
 }
@@ -5,93 +5,445 @@ import (
 	"errors"
 	"fmt"
 	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
 	"go-web-crawler-service/domain"
+	"go-web-crawler-service/metrics"
+	"go-web-crawler-service/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"log"
-	"regexp"
-	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
 const (
 	crawlerTTLSeconds = 20
+	// Elements inside already rendered hero section are looked up with shorter timeout
+	elementLookupTimeout = 2 * time.Second
+	// Page of the failed crawl is captured with its own timeout, as the crawl itself could have timed out
+	artifactCaptureTimeout = 10 * time.Second
 )
 
 type rodRokuWebCrawler struct {
-	browser *rod.Browser
+	browsers   *browserPool
+	profile    *ExtractionProfile
+	identities IdentityProvider
+	artifacts  domain.ArtifactStore
 }
 
-func NewRodRokuWebCrawler(browser *rod.Browser) *rodRokuWebCrawler {
+// NewRodRokuWebCrawler creates crawler rendering the details page in the browser taken from the pool with
+// the identity supplied by the provider, pages the channel could not be extracted from are captured
+// to the artifact store unless it's nil
+func NewRodRokuWebCrawler(
+	browsers *browserPool,
+	profile *ExtractionProfile,
+	identities IdentityProvider,
+	artifacts domain.ArtifactStore,
+) *rodRokuWebCrawler {
 	return &rodRokuWebCrawler{
-		browser: browser,
+		browsers:   browsers,
+		profile:    profile,
+		identities: identities,
+		artifacts:  artifacts,
 	}
 }
 
-func (c *rodRokuWebCrawler) CrawlChannel(ctx context.Context, url domain.Url) (*domain.Channel, error) {
+// CrawlChannel renders the page in the locale with the identity of the locale, the channel keeps the url it was
+// requested with
+func (c *rodRokuWebCrawler) CrawlChannel(
+	ctx context.Context,
+	url domain.Url,
+	locale domain.Locale,
+) (*domain.Channel, error) {
 	ctx, cancel := context.WithTimeout(ctx, crawlerTTLSeconds*time.Second)
 	defer cancel()
 
-	browser := c.browser.Context(ctx)
+	lease, err := c.browsers.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer lease.Release()
+
+	identity := c.identities.Next(locale)
+	channel, err := c.crawlPage(ctx, lease.Browser(), url, c.profile.localizedUrl(url, locale), identity)
+	c.identities.Report(identity, err)
+	if err != nil {
+		return nil, err
+	}
+
+	channel.Proxy = identity.Proxy.Server()
+
+	return channel, nil
+}
+
+// crawlPage opens the page in a new browser context of the identity, so the pages do not share cookies
+// and the context could use its own proxy. The channel is extracted from the page url under the channel url.
+func (c *rodRokuWebCrawler) crawlPage(
+	ctx context.Context,
+	browser *rod.Browser,
+	url domain.Url,
+	pageUrl domain.Url,
+	identity CrawlIdentity,
+) (*domain.Channel, error) {
+	identityBrowser, err := newIdentityBrowser(ctx, browser, identity)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = proto.TargetDisposeBrowserContext{BrowserContextID: identityBrowser.BrowserContextID}.Call(browser)
+	}()
+
+	events := &pageEvents{proxy: identity.Proxy}
+	page, root, err := c.loadPage(ctx, identityBrowser, pageUrl, identity, events)
+	if page != nil {
+		defer func() {
+			_ = rod.Try(
+				func() {
+					page.MustClose()
+				},
+			)
+		}()
+	}
+	if err != nil {
+		return nil, c.captureFailure(ctx, page, events, pageUrl, err)
+	}
+
+	document, err := getDocumentElement(page)
+	if err != nil {
+		return nil, c.captureFailure(ctx, page, events, pageUrl, err)
+	}
+
+	channel, err := c.profile.extractChannel(
+		ctx,
+		url,
+		&rodExtractionNode{element: document},
+		&rodExtractionNode{element: root},
+	)
+	if err != nil {
+		return nil, c.captureFailure(ctx, page, events, pageUrl, err)
+	}
+
+	return channel, nil
+}
+
+// newIdentityBrowser creates browser context using the proxy of the identity, pages opened by the returned browser
+// belong to the context
+func newIdentityBrowser(ctx context.Context, browser *rod.Browser, identity CrawlIdentity) (*rod.Browser, error) {
+	browserContext, err := proto.TargetCreateBrowserContext{
+		DisposeOnDetach: true,
+		ProxyServer:     identity.Proxy.BrowserServer(),
+	}.Call(browser.Context(ctx))
+	checkedErr := checkErr(err)
+	if checkedErr != nil {
+		return nil, checkedErr
+	}
+
+	identityBrowser := *browser
+	identityBrowser.BrowserContextID = browserContext.BrowserContextID
+
+	return &identityBrowser, nil
+}
+
+// loadPage opens the page with the identity and waits until its root element is rendered, the page is returned
+// even when the root element was not found, so it could be closed
+func (c *rodRokuWebCrawler) loadPage(
+	ctx context.Context,
+	browser *rod.Browser,
+	url domain.Url,
+	identity CrawlIdentity,
+	events *pageEvents,
+) (*rod.Page, *rod.Element, error) {
+	ctx, span := tracing.Tracer().Start(
+		ctx,
+		"load page",
+		trace.WithAttributes(
+			attribute.String("url", string(url)),
+			attribute.String("root", c.profile.Root),
+			attribute.String("proxy", identity.Proxy.Server()),
+		),
+	)
 
 	var page *rod.Page
 	err := rod.Try(
 		func() {
-			page = browser.MustPage(string(url))
+			page = browser.Context(ctx).MustPage("")
 		},
 	)
 	checkedErr := checkErr(err)
 	if checkedErr != nil {
-		return nil, checkedErr
+		tracing.End(span, checkedErr)
+		return nil, nil, checkedErr
 	}
-	defer func() {
-		_ = rod.Try(
-			func() {
-				page.MustClose()
-			},
-		)
-	}()
 
-	log.Printf("Successfully opened page with url: %s\n", url)
+	// Events are listened to before the navigation, so the ones emitted while the page loads are not missed
+	events.frameID = page.FrameID
+	events.page = page
+	listeners := []interface{}{events.recordConsole, events.recordResponse}
+	if _, _, ok := identity.Proxy.Credentials(); ok {
+		listeners = append(listeners, events.continueRequest, events.authenticate)
+	}
+	go page.EachEvent(listeners...)()
 
-	page = page.Context(ctx)
+	err = applyIdentity(page, identity)
+	checkedErr = checkErr(err)
+	if checkedErr != nil {
+		tracing.End(span, checkedErr)
+		return page, nil, checkedErr
+	}
 
-	var hero *rod.Element
 	err = rod.Try(
 		func() {
-			hero = page.MustElement(".Roku-Page-Details-Hero")
+			page.MustNavigate(string(url))
 		},
 	)
 	checkedErr = checkErr(err)
 	if checkedErr != nil {
-		return nil, checkedErr
+		tracing.End(span, checkedErr)
+		return page, nil, checkedErr
+	}
+
+	log.Printf("Successfully opened page with url: %s\n", url)
+
+	page = page.Context(ctx)
+
+	// Error pages are rendered without the root, so waiting for it is stopped as soon as the status
+	// of the document tells what happened instead of timing out
+	waitCtx, stopWaiting := context.WithCancel(ctx)
+	defer stopWaiting()
+	events.setOnErrorStatus(stopWaiting)
+
+	root, err := c.waitForRoot(page.Context(waitCtx))
+	if err != nil {
+		statusCode := events.documentStatus()
+		if kind := responseErrorKind(statusCode); kind != domain.CrawlErrorUnknown {
+			err = domain.NewCrawlError(kind, fmt.Errorf("page responded with status %d, %w", statusCode, err))
+		}
+	}
+	tracing.End(span, err)
+	if err != nil {
+		metrics.ExtractionFailures.WithLabelValues(fieldRoot).Inc()
+		return page, nil, err
 	}
 
-	appName, err := getAppName(hero)
+	return page, root, nil
+}
+
+// applyIdentity makes the page present itself with the user agent, language and viewport of the identity. Requests
+// of the page are paused until they are authenticated to the proxy when the proxy has credentials.
+func applyIdentity(page *rod.Page, identity CrawlIdentity) error {
+	err := proto.NetworkSetUserAgentOverride{
+		UserAgent:      identity.UserAgent,
+		AcceptLanguage: identity.AcceptLanguage,
+	}.Call(page)
+	if err != nil {
+		return err
+	}
+
+	err = page.SetViewport(
+		&proto.EmulationSetDeviceMetricsOverride{
+			Width:             identity.Viewport.Width,
+			Height:            identity.Viewport.Height,
+			DeviceScaleFactor: 1,
+		},
+	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get application name, %w", err)
+		return err
 	}
 
-	avgRating, err := getAvgRating(hero)
+	if _, _, ok := identity.Proxy.Credentials(); ok {
+		return proto.FetchEnable{HandleAuthRequests: true}.Call(page)
+	}
+
+	return nil
+}
+
+// waitForRoot waits until either the root element, or the element of blocked or not found page is rendered
+func (c *rodRokuWebCrawler) waitForRoot(page *rod.Page) (*rod.Element, error) {
+	race := page.Race().Element(c.profile.Root)
+	if c.profile.BlockedSelector != "" {
+		race = race.Element(c.profile.BlockedSelector).Handle(
+			func(*rod.Element) error {
+				return domain.NewCrawlError(
+					domain.CrawlErrorBlocked,
+					fmt.Errorf("blocked page: %s", c.profile.BlockedSelector),
+				)
+			},
+		)
+	}
+	if c.profile.NotFoundSelector != "" {
+		race = race.Element(c.profile.NotFoundSelector).Handle(
+			func(*rod.Element) error {
+				return domain.NewCrawlError(
+					domain.CrawlErrorNotFound,
+					fmt.Errorf("not found page: %s", c.profile.NotFoundSelector),
+				)
+			},
+		)
+	}
+
+	root, err := race.Do()
+	var crawlErr *domain.CrawlError
+	if errors.As(err, &crawlErr) {
+		return nil, err
+	}
+
+	return root, checkErr(err)
+}
+
+// captureFailure saves the state of the page the channel could not be extracted from, the crawl error is returned
+// with id of the artifact once it's saved. Crawls interrupted by the shutdown are not captured.
+func (c *rodRokuWebCrawler) captureFailure(
+	ctx context.Context,
+	page *rod.Page,
+	events *pageEvents,
+	url domain.Url,
+	crawlErr error,
+) error {
+	if c.artifacts == nil || page == nil || errors.Is(ctx.Err(), context.Canceled) {
+		return crawlErr
+	}
+
+	captureCtx, cancel := context.WithTimeout(context.Background(), artifactCaptureTimeout)
+	defer cancel()
+	page = page.Context(captureCtx)
+
+	capturedAt := time.Now()
+	artifact := domain.FailureArtifact{
+		ID:         domain.GenerateArtifactID(capturedAt),
+		Url:        url,
+		Reason:     crawlErr.Error(),
+		ConsoleLog: events.consoleLines(),
+		Proxy:      events.proxy.Server(),
+		CapturedAt: capturedAt,
+	}
+
+	screenshot, err := page.Screenshot(true, &proto.PageCaptureScreenshot{Format: proto.PageCaptureScreenshotFormatPng})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get average rating, %w", err)
+		log.Printf("Could not take screenshot of url: %s, error: %v\n", url, err)
 	}
+	artifact.Screenshot = screenshot
 
-	ratingsAmount, err := getRatingsAmount(hero)
+	dom, err := page.HTML()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get ratings amount, %w", err)
+		log.Printf("Could not read rendered DOM of url: %s, error: %v\n", url, err)
 	}
+	artifact.DOM = dom
 
-	channel, err := createChannel(url, appName, avgRating, ratingsAmount)
+	err = c.artifacts.Save(captureCtx, artifact)
 	if err != nil {
-		return nil, fmt.Errorf("unable to create channel entity from scrapped data, error: %w", err)
+		log.Printf("Could not save artifact of url: %s, error: %v\n", url, err)
+		return crawlErr
 	}
 
-	return channel, nil
+	log.Printf("Saved artifact %s of failed crawl of url: %s\n", artifact.ID, url)
+
+	return domain.NewArtifactError(artifact.ID, crawlErr)
+}
+
+// pageEvents records the events of the page the crawlers are interested in
+type pageEvents struct {
+	frameID proto.PageFrameID
+	page    *rod.Page
+	// proxy authenticates the requests of the page, it's nil for direct crawls
+	proxy *Proxy
+
+	mu         sync.Mutex
+	console    []string
+	statusCode int
+	// onErrorStatus is called when the document responded with status of the classified failure
+	onErrorStatus func()
+}
+
+// recordConsole records message the page logged to the console
+func (e *pageEvents) recordConsole(event *proto.RuntimeConsoleAPICalled) {
+	args := make([]string, 0, len(event.Args))
+	for _, arg := range event.Args {
+		if arg.Value.Nil() {
+			args = append(args, arg.Description)
+		} else {
+			args = append(args, arg.Value.Str())
+		}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.console = append(e.console, fmt.Sprintf("[%s] %s", event.Type, strings.Join(args, " ")))
+}
+
+// recordResponse records status of the page document, documents of the frames inside the page are skipped
+func (e *pageEvents) recordResponse(event *proto.NetworkResponseReceived) {
+	if event.Type != proto.NetworkResourceTypeDocument || event.FrameID != e.frameID {
+		return
+	}
+
+	e.mu.Lock()
+	e.statusCode = event.Response.Status
+	onErrorStatus := e.onErrorStatus
+	e.mu.Unlock()
+
+	if onErrorStatus != nil && responseErrorKind(event.Response.Status) != domain.CrawlErrorUnknown {
+		onErrorStatus()
+	}
+}
+
+// continueRequest continues the request paused to be authenticated to the proxy. The calls are made outside
+// of the event loop, so they do not block the following events.
+func (e *pageEvents) continueRequest(event *proto.FetchRequestPaused) {
+	go func() {
+		_ = proto.FetchContinueRequest{RequestID: event.RequestID}.Call(e.page)
+	}()
+}
+
+// authenticate answers the proxy challenge with its credentials, challenges of the store itself are cancelled
+func (e *pageEvents) authenticate(event *proto.FetchAuthRequired) {
+	response := &proto.FetchAuthChallengeResponse{Response: proto.FetchAuthChallengeResponseResponseCancelAuth}
+	username, password, ok := e.proxy.Credentials()
+	if ok && event.AuthChallenge.Source == proto.FetchAuthChallengeSourceProxy {
+		response = &proto.FetchAuthChallengeResponse{
+			Response: proto.FetchAuthChallengeResponseResponseProvideCredentials,
+			Username: username,
+			Password: password,
+		}
+	}
+
+	go func() {
+		_ = proto.FetchContinueWithAuth{RequestID: event.RequestID, AuthChallengeResponse: response}.Call(e.page)
+	}()
+}
+
+// setOnErrorStatus sets the callback, it's called right away when the error status was already received
+func (e *pageEvents) setOnErrorStatus(onErrorStatus func()) {
+	e.mu.Lock()
+	e.onErrorStatus = onErrorStatus
+	statusCode := e.statusCode
+	e.mu.Unlock()
+
+	if responseErrorKind(statusCode) != domain.CrawlErrorUnknown {
+		onErrorStatus()
+	}
+}
+
+func (e *pageEvents) consoleLines() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return append([]string(nil), e.console...)
+}
+
+// documentStatus returns status code of the page document, 0 when it was not received yet
+func (e *pageEvents) documentStatus() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.statusCode
 }
 
 func checkErr(err error) error {
 	var evalErr *rod.ErrEval
 	if errors.Is(err, context.DeadlineExceeded) {
-		return fmt.Errorf("timeout %w", err)
+		return domain.NewCrawlError(domain.CrawlErrorTimeout, fmt.Errorf("timeout %w", err))
 	} else if errors.As(err, &evalErr) {
 		return fmt.Errorf("evaluation error, line: %d, error: %w", evalErr.LineNumber, evalErr)
 	} else if err != nil {
@@ -101,131 +453,127 @@ func checkErr(err error) error {
 	return nil
 }
 
-func getAppName(hero *rod.Element) (string, error) {
-	var appNameElement *rod.Element
-	var appName string
-
-	err := rod.Try(
-		func() {
-			appNameElement = hero.MustElement("h1")
-		},
-	)
-	checkedErr := checkErr(err)
-	if checkedErr != nil {
-		return "", checkedErr
+// findElement waits for the element inside the parent, lack of the element after lookup timeout is reported
+// as domain.ErrElementNotFound unless the whole crawl timed out
+func findElement(ctx context.Context, parent *rod.Element, selector string) (*rod.Element, error) {
+	element, err := parent.Timeout(elementLookupTimeout).Element(selector)
+	if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+		return nil, fmt.Errorf("%w: %s", domain.ErrElementNotFound, selector)
 	}
 
-	err = rod.Try(
-		func() {
-			appName = appNameElement.MustText()
-		},
-	)
-	checkedErr = checkErr(err)
+	checkedErr := checkErr(err)
 	if checkedErr != nil {
-		return "", checkedErr
+		return nil, checkedErr
 	}
 
-	return appName, nil
+	return element.CancelTimeout(), nil
 }
 
-func getAvgRating(hero *rod.Element) (float32, error) {
-	var avgRatingElement *rod.Element
-	var avgRatingVal string
-
+// getDocumentElement returns the root element of the loaded page, fields outside the root are looked up in it
+func getDocumentElement(page *rod.Page) (*rod.Element, error) {
+	var document *rod.Element
 	err := rod.Try(
 		func() {
-			avgRatingElement = hero.MustElement(".average-rating")
+			document = page.MustElement("html")
 		},
 	)
-	checkedErr := checkErr(err)
-	if checkedErr != nil {
-		return 0, checkedErr
-	}
 
-	err = rod.Try(
-		func() {
-			avgRatingVal = avgRatingElement.MustText()
-		},
-	)
-	checkedErr = checkErr(err)
-	if checkedErr != nil {
-		return 0, checkedErr
-	}
+	return document, checkErr(err)
+}
+
+// rodExtractionNode is an element of the page rendered by the browser
+type rodExtractionNode struct {
+	element *rod.Element
+}
 
-	avgRating, err := strconv.ParseFloat(avgRatingVal, 32)
+func (n *rodExtractionNode) find(ctx context.Context, selector string) (extractionNode, error) {
+	element, err := findElement(ctx, n.element.Context(ctx), selector)
 	if err != nil {
-		return 0, fmt.Errorf(
-			"failed to create float value from average rating, value: %s, error: %w",
-			avgRatingVal,
-			err,
-		)
+		return nil, err
 	}
 
-	return float32(avgRating), nil
+	return &rodExtractionNode{element: element}, nil
 }
 
-func getRatingsAmount(hero *rod.Element) (uint32, error) {
-	var ratingsAmountCntElement *rod.Element
-	var ratingsAmountCntVal string
-
+func (n *rodExtractionNode) text() (string, error) {
+	var text string
 	err := rod.Try(
 		func() {
-			ratingsAmountCntElement = hero.MustElement("[itemprop=\"starRating\"]")
+			text = n.element.MustText()
 		},
 	)
-	checkedErr := checkErr(err)
-	if checkedErr != nil {
-		return 0, checkedErr
-	}
 
-	err = rod.Try(
-		func() {
-			ratingsAmountCntVal = ratingsAmountCntElement.MustText()
-		},
+	return text, checkErr(err)
+}
+
+func (n *rodExtractionNode) ownText() (string, error) {
+	result, err := n.element.Eval(
+		`() => Array.from(this.childNodes)
+			.filter(node => node.nodeType === Node.TEXT_NODE)
+			.map(node => node.textContent)
+			.join('')`,
 	)
-	checkedErr = checkErr(err)
+	checkedErr := checkErr(err)
 	if checkedErr != nil {
-		return 0, checkedErr
+		return "", checkedErr
 	}
 
-	var extractedRatingsAmount = "0"
+	return result.Value.Str(), nil
+}
 
-	r := regexp.MustCompile(`(\d+) ratings`)
-	extractedRating := r.FindStringSubmatch(ratingsAmountCntVal)
-	if len(extractedRating) == 2 {
-		extractedRatingsAmount = extractedRating[1]
+func (n *rodExtractionNode) attribute(name string) (string, error) {
+	value, err := n.element.Attribute(name)
+	checkedErr := checkErr(err)
+	if checkedErr != nil {
+		return "", checkedErr
 	}
 
-	ratingsAmount, err := strconv.Atoi(extractedRatingsAmount)
-	if err != nil {
-		return 0, fmt.Errorf(
-			"failed to create integer value from ratings amount, value: %d, error: %w",
-			ratingsAmount,
-			err,
-		)
+	if value == nil {
+		return "", nil
 	}
 
-	return uint32(ratingsAmount), nil
+	return *value, nil
 }
 
-func createChannel(url domain.Url, nameVal string, ratingVal float32, ratingsAmountVal uint32) (
-	*domain.Channel,
-	error,
-) {
+func createChannel(
+	url domain.Url,
+	nameVal string,
+	ratingVal float32,
+	ratingsAmountVal uint32,
+	profileVersion string,
+) (*domain.Channel, error) {
 	appName, err := domain.NewApplicationName(nameVal)
 	if err != nil {
-		return nil, fmt.Errorf("application name is invalid: %s, error: %w", nameVal, err)
+		return nil, domain.NewCrawlError(
+			domain.CrawlErrorInvalidData,
+			fmt.Errorf("application name is invalid: %s, error: %w", nameVal, err),
+		)
 	}
 
 	rating, err := domain.NewRating(ratingVal)
 	if err != nil {
-		return nil, fmt.Errorf("rating has invalid value: %f, error: %w", ratingVal, err)
+		return nil, domain.NewCrawlError(
+			domain.CrawlErrorInvalidData,
+			fmt.Errorf("rating has invalid value: %f, error: %w", ratingVal, err),
+		)
 	}
 
 	ratingsAmount, err := domain.NewRatingsAmount(ratingsAmountVal)
 	if err != nil {
-		return nil, fmt.Errorf("ratings amount has invalid value: %d, error: %w", ratingsAmountVal, err)
+		return nil, domain.NewCrawlError(
+			domain.CrawlErrorInvalidData,
+			fmt.Errorf("ratings amount has invalid value: %d, error: %w", ratingsAmountVal, err),
+		)
 	}
 
-	return domain.NewChannel(*appName, url, *rating, *ratingsAmount), nil
+	storeChannelID, err := domain.NewStoreChannelID(url)
+	if err != nil {
+		return nil, domain.NewCrawlError(domain.CrawlErrorInvalidData, err)
+	}
+
+	channel := domain.NewChannel(*appName, url, *rating, *ratingsAmount)
+	channel.StoreChannelID = *storeChannelID
+	channel.ProfileVersion = profileVersion
+
+	return channel, nil
 }
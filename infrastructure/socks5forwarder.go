@@ -0,0 +1,203 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"golang.org/x/net/proxy"
+	"io"
+	"log"
+	"net"
+	"strconv"
+	"time"
+)
+
+const (
+	socks5Version = 0x05
+
+	socks5MethodNoAuth       = 0x00
+	socks5MethodNoAcceptable = 0xff
+
+	socks5CommandConnect = 0x01
+
+	socks5AddressIPv4   = 0x01
+	socks5AddressDomain = 0x03
+	socks5AddressIPv6   = 0x04
+
+	socks5ReplySucceeded           = 0x00
+	socks5ReplyGeneralFailure      = 0x01
+	socks5ReplyCommandNotSupported = 0x07
+	socks5ReplyAddressNotSupported = 0x08
+
+	socks5UpstreamDialTimeout = 30 * time.Second
+)
+
+var (
+	errSocks5CommandNotSupported = errors.New("only connect command is supported")
+	errSocks5AddressNotSupported = errors.New("address type is not supported")
+)
+
+// socks5Forwarder is a local SOCKS5 proxy without authentication connecting the browser through the upstream
+// SOCKS5 proxy which requires credentials, as the browser does not authenticate to SOCKS5 proxies itself
+type socks5Forwarder struct {
+	listener net.Listener
+	upstream proxy.Dialer
+}
+
+// startSocks5Forwarder starts forwarder listening on the loopback interface, it's stopped once the context is done
+func startSocks5Forwarder(ctx context.Context, upstreamAddress string, auth *proxy.Auth) (*socks5Forwarder, error) {
+	upstream, err := proxy.SOCKS5("tcp", upstreamAddress, auth, &net.Dialer{Timeout: socks5UpstreamDialTimeout})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dialer of SOCKS5 proxy %s, error: %w", upstreamAddress, err)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for SOCKS5 proxy %s, error: %w", upstreamAddress, err)
+	}
+
+	forwarder := &socks5Forwarder{listener: listener, upstream: upstream}
+	go func() {
+		<-ctx.Done()
+		_ = listener.Close()
+	}()
+	go forwarder.serve()
+
+	return forwarder, nil
+}
+
+// Address returns the address the browser connects to
+func (f *socks5Forwarder) Address() string {
+	return f.listener.Addr().String()
+}
+
+func (f *socks5Forwarder) serve() {
+	for {
+		conn, err := f.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		go f.forward(conn)
+	}
+}
+
+// forward connects the browser to the target through the upstream proxy and copies the data both ways
+// until either side closes the connection
+func (f *socks5Forwarder) forward(conn net.Conn) {
+	defer conn.Close()
+
+	target, err := readSocks5Connect(conn)
+	if err != nil {
+		log.Printf("Rejecting SOCKS5 connection, error: %v\n", err)
+		return
+	}
+
+	upstreamConn, err := f.upstream.Dial("tcp", target)
+	if err != nil {
+		log.Printf("Could not connect to %s through SOCKS5 proxy, error: %v\n", target, err)
+		_ = writeSocks5Reply(conn, socks5ReplyGeneralFailure)
+		return
+	}
+	defer upstreamConn.Close()
+
+	err = writeSocks5Reply(conn, socks5ReplySucceeded)
+	if err != nil {
+		return
+	}
+
+	done := make(chan struct{}, 2)
+	go func() {
+		_, _ = io.Copy(upstreamConn, conn)
+		done <- struct{}{}
+	}()
+	go func() {
+		_, _ = io.Copy(conn, upstreamConn)
+		done <- struct{}{}
+	}()
+	<-done
+}
+
+// readSocks5Connect negotiates the connection without authentication and returns the address of the connect
+// request, unsupported requests are answered with the failure reply
+func readSocks5Connect(conn net.Conn) (string, error) {
+	header := make([]byte, 2)
+	_, err := io.ReadFull(conn, header)
+	if err != nil {
+		return "", fmt.Errorf("failed to read greeting, error: %w", err)
+	}
+	if header[0] != socks5Version {
+		return "", fmt.Errorf("unsupported SOCKS version %d", header[0])
+	}
+
+	methods := make([]byte, header[1])
+	_, err = io.ReadFull(conn, methods)
+	if err != nil {
+		return "", fmt.Errorf("failed to read authentication methods, error: %w", err)
+	}
+
+	method := byte(socks5MethodNoAcceptable)
+	for _, offered := range methods {
+		if offered == socks5MethodNoAuth {
+			method = socks5MethodNoAuth
+		}
+	}
+	_, err = conn.Write([]byte{socks5Version, method})
+	if err != nil {
+		return "", fmt.Errorf("failed to write authentication method, error: %w", err)
+	}
+	if method == socks5MethodNoAcceptable {
+		return "", errors.New("client does not support connection without authentication")
+	}
+
+	request := make([]byte, 4)
+	_, err = io.ReadFull(conn, request)
+	if err != nil {
+		return "", fmt.Errorf("failed to read request, error: %w", err)
+	}
+	if request[1] != socks5CommandConnect {
+		_ = writeSocks5Reply(conn, socks5ReplyCommandNotSupported)
+		return "", errSocks5CommandNotSupported
+	}
+
+	var host string
+	switch request[3] {
+	case socks5AddressIPv4, socks5AddressIPv6:
+		size := net.IPv4len
+		if request[3] == socks5AddressIPv6 {
+			size = net.IPv6len
+		}
+		ip := make([]byte, size)
+		_, err = io.ReadFull(conn, ip)
+		host = net.IP(ip).String()
+	case socks5AddressDomain:
+		size := make([]byte, 1)
+		_, err = io.ReadFull(conn, size)
+		if err == nil {
+			domain := make([]byte, size[0])
+			_, err = io.ReadFull(conn, domain)
+			host = string(domain)
+		}
+	default:
+		_ = writeSocks5Reply(conn, socks5ReplyAddressNotSupported)
+		return "", errSocks5AddressNotSupported
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read address, error: %w", err)
+	}
+
+	port := make([]byte, 2)
+	_, err = io.ReadFull(conn, port)
+	if err != nil {
+		return "", fmt.Errorf("failed to read port, error: %w", err)
+	}
+
+	return net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port)))), nil
+}
+
+// writeSocks5Reply answers the connect request, bound address is not used by the clients so it's left empty
+func writeSocks5Reply(conn net.Conn, reply byte) error {
+	_, err := conn.Write([]byte{socks5Version, reply, 0x00, socks5AddressIPv4, 0, 0, 0, 0, 0, 0})
+	return err
+}
@@ -0,0 +1,129 @@
+package infrastructure
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/proxy"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+// startAuthenticatingSocks5Proxy starts SOCKS5 proxy accepting only the username and password authentication
+// with the credentials, it returns its address
+func startAuthenticatingSocks5Proxy(t *testing.T, username string, password string) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer conn.Close()
+
+				greeting := make([]byte, 2)
+				_, _ = io.ReadFull(conn, greeting)
+				_, _ = io.ReadFull(conn, make([]byte, greeting[1]))
+				_, _ = conn.Write([]byte{socks5Version, 0x02})
+
+				// Username and password authentication (RFC 1929)
+				header := make([]byte, 2)
+				_, _ = io.ReadFull(conn, header)
+				user := make([]byte, header[1])
+				_, _ = io.ReadFull(conn, user)
+				size := make([]byte, 1)
+				_, _ = io.ReadFull(conn, size)
+				pass := make([]byte, size[0])
+				_, _ = io.ReadFull(conn, pass)
+				if string(user) != username || string(pass) != password {
+					_, _ = conn.Write([]byte{0x01, 0x01})
+					return
+				}
+				_, _ = conn.Write([]byte{0x01, 0x00})
+
+				request := make([]byte, 4)
+				_, _ = io.ReadFull(conn, request)
+				size = make([]byte, 1)
+				_, _ = io.ReadFull(conn, size)
+				_, _ = io.ReadFull(conn, make([]byte, int(size[0])+2))
+
+				// Every connection is answered by the proxy itself
+				_ = writeSocks5Reply(conn, socks5ReplySucceeded)
+				_, _ = conn.Write([]byte("hello"))
+			}()
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+func TestSocks5Forwarder(t *testing.T) {
+	upstream := startAuthenticatingSocks5Proxy(t, "user", "secret")
+
+	testCases := []struct {
+		name      string
+		auth      *proxy.Auth
+		connected bool
+	}{
+		{
+			name:      "forwarder authenticates to the proxy",
+			auth:      &proxy.Auth{User: "user", Password: "secret"},
+			connected: true,
+		},
+		{name: "invalid credentials", auth: &proxy.Auth{User: "user", Password: "invalid"}, connected: false},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(
+			testCase.name, func(t *testing.T) {
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+
+				forwarder, err := startSocks5Forwarder(ctx, upstream, testCase.auth)
+				require.NoError(t, err)
+
+				// Browser connects to the forwarder without authentication
+				dialer, err := proxy.SOCKS5("tcp", forwarder.Address(), nil, proxy.Direct)
+				require.NoError(t, err)
+
+				conn, err := dialer.Dial("tcp", "channelstore.roku.com:443")
+				if !testCase.connected {
+					require.Error(t, err)
+					return
+				}
+				require.NoError(t, err)
+				defer conn.Close()
+
+				greeting := make([]byte, 5)
+				_, err = io.ReadFull(conn, greeting)
+				require.NoError(t, err)
+				assert.Equal(t, "hello", string(greeting))
+			},
+		)
+	}
+}
+
+func TestSocks5Forwarder_StoppedWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	forwarder, err := startSocks5Forwarder(ctx, "127.0.0.1:1080", &proxy.Auth{User: "user", Password: "secret"})
+	require.NoError(t, err)
+
+	cancel()
+
+	require.Eventually(
+		t, func() bool {
+			conn, err := net.Dial("tcp", forwarder.Address())
+			if err == nil {
+				_ = conn.Close()
+			}
+			return err != nil
+		}, time.Second, 10*time.Millisecond,
+	)
+}
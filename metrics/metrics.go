@@ -0,0 +1,185 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+)
+
+const namespace = "webcrawler"
+
+// Outcomes of the processed crawl request
+const (
+	OutcomeSuccess        = "success"
+	OutcomeRetryableError = "retryable_error"
+	OutcomePermanentError = "permanent_error"
+)
+
+// Reasons of the browser launch
+const (
+	BrowserLaunchInitial      = "initial"
+	BrowserLaunchRecycled     = "recycled"
+	BrowserLaunchUnhealthy    = "unhealthy"
+	BrowserLaunchDisconnected = "disconnected"
+)
+
+// Results of the consumed message handling
+const (
+	MessageAck     = "ack"
+	MessageNack    = "nack"
+	MessageRequeue = "requeue"
+)
+
+var (
+	CrawlDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "worker",
+			Name:      "crawl_duration_seconds",
+			Help:      "Duration of the crawl request processing by its outcome.",
+			Buckets:   []float64{0.1, 0.25, 0.5, 1, 2, 5, 10, 20, 30},
+		},
+		[]string{"outcome"},
+	)
+
+	UnhealthyProxies = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "crawler",
+			Name:      "unhealthy_proxies_total",
+			Help:      "Amount of times the proxy was taken out of the rotation as it kept failing.",
+		},
+		[]string{"proxy"},
+	)
+
+	ExtractionFailures = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "crawler",
+			Name:      "extraction_failures_total",
+			Help:      "Amount of channel fields that could not be extracted from the crawled page.",
+		},
+		[]string{"field"},
+	)
+
+	Messages = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "worker",
+			Name:      "messages_total",
+			Help:      "Amount of consumed messages by the way they were acknowledged.",
+		},
+		[]string{"result"},
+	)
+
+	QueueDepth = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "worker",
+			Name:      "queue_depth",
+			Help:      "Amount of the crawl requests waiting in the lane of the priority.",
+		},
+		[]string{"priority"},
+	)
+
+	WorkersInFlight = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "worker",
+			Name:      "in_flight",
+			Help:      "Amount of workers processing crawl request at the moment.",
+		},
+	)
+
+	PublishDuration = promauto.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "publisher",
+			Name:      "publish_duration_seconds",
+			Help:      "Time from publishing the crawl request until it was confirmed by the broker.",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 2, 12),
+		},
+	)
+
+	PublishFailures = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "publisher",
+			Name:      "publish_failures_total",
+			Help:      "Amount of crawl requests that were not published or not confirmed by the broker.",
+		},
+	)
+
+	DuplicateRequests = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "api",
+			Name:      "duplicate_requests_total",
+			Help:      "Amount of submitted urls skipped as they were scheduled within the dedup window.",
+		},
+	)
+
+	RateLimitDelay = promauto.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "rate_limiter",
+			Name:      "delay_seconds",
+			Help:      "Time the crawl waited for the free slot of its host, including the jitter.",
+			Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2, 5, 10, 30, 60},
+		},
+	)
+
+	RateLimitBackoffs = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "rate_limiter",
+			Name:      "backoffs_total",
+			Help:      "Amount of times the crawls of a host were slowed down as the host was overloaded.",
+		},
+	)
+)
+
+var (
+	BrowsersRunning = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "browser_pool",
+			Name:      "browsers",
+			Help:      "Amount of running browsers, including the retired ones still rendering pages.",
+		},
+	)
+
+	BrowserPagesInUse = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "browser_pool",
+			Name:      "pages_in_use",
+			Help:      "Amount of browser tabs used by the crawlers at the moment.",
+		},
+	)
+
+	BrowserLaunches = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "browser_pool",
+			Name:      "launches_total",
+			Help:      "Amount of launched browsers by the reason of the launch.",
+		},
+		[]string{"reason"},
+	)
+
+	BrowserLaunchFailures = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "browser_pool",
+			Name:      "launch_failures_total",
+			Help:      "Amount of browsers that could not be launched.",
+		},
+	)
+)
+
+// Handler exposes all the registered metrics
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
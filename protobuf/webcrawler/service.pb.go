@@ -1,6 +1,6 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.27.1
+// 	protoc-gen-go v1.28.0
 // 	protoc        (unknown)
 // source: webcrawler/service.proto
 
@@ -9,6 +9,7 @@ package webcrawler
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 )
@@ -20,31 +21,1154 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// Lane the crawl request waits in, requests of higher priority are consumed more often
+type CrawlPriority int32
+
+const (
+	// Unspecified priority is the normal one
+	CrawlPriority_CRAWL_PRIORITY_UNSPECIFIED CrawlPriority = 0
+	CrawlPriority_CRAWL_PRIORITY_LOW         CrawlPriority = 1
+	CrawlPriority_CRAWL_PRIORITY_NORMAL      CrawlPriority = 2
+	CrawlPriority_CRAWL_PRIORITY_HIGH        CrawlPriority = 3
+)
+
+// Enum value maps for CrawlPriority.
+var (
+	CrawlPriority_name = map[int32]string{
+		0: "CRAWL_PRIORITY_UNSPECIFIED",
+		1: "CRAWL_PRIORITY_LOW",
+		2: "CRAWL_PRIORITY_NORMAL",
+		3: "CRAWL_PRIORITY_HIGH",
+	}
+	CrawlPriority_value = map[string]int32{
+		"CRAWL_PRIORITY_UNSPECIFIED": 0,
+		"CRAWL_PRIORITY_LOW":         1,
+		"CRAWL_PRIORITY_NORMAL":      2,
+		"CRAWL_PRIORITY_HIGH":        3,
+	}
+)
+
+func (x CrawlPriority) Enum() *CrawlPriority {
+	p := new(CrawlPriority)
+	*p = x
+	return p
+}
+
+func (x CrawlPriority) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (CrawlPriority) Descriptor() protoreflect.EnumDescriptor {
+	return file_webcrawler_service_proto_enumTypes[0].Descriptor()
+}
+
+func (CrawlPriority) Type() protoreflect.EnumType {
+	return &file_webcrawler_service_proto_enumTypes[0]
+}
+
+func (x CrawlPriority) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use CrawlPriority.Descriptor instead.
+func (CrawlPriority) EnumDescriptor() ([]byte, []int) {
+	return file_webcrawler_service_proto_rawDescGZIP(), []int{0}
+}
+
+type CrawlStatus int32
+
+const (
+	CrawlStatus_CRAWL_STATUS_UNSPECIFIED    CrawlStatus = 0
+	CrawlStatus_CRAWL_STATUS_ACCEPTED       CrawlStatus = 1
+	CrawlStatus_CRAWL_STATUS_INVALID        CrawlStatus = 2
+	CrawlStatus_CRAWL_STATUS_PUBLISH_FAILED CrawlStatus = 3
+	CrawlStatus_CRAWL_STATUS_DUPLICATE      CrawlStatus = 4
+)
+
+// Enum value maps for CrawlStatus.
+var (
+	CrawlStatus_name = map[int32]string{
+		0: "CRAWL_STATUS_UNSPECIFIED",
+		1: "CRAWL_STATUS_ACCEPTED",
+		2: "CRAWL_STATUS_INVALID",
+		3: "CRAWL_STATUS_PUBLISH_FAILED",
+		4: "CRAWL_STATUS_DUPLICATE",
+	}
+	CrawlStatus_value = map[string]int32{
+		"CRAWL_STATUS_UNSPECIFIED":    0,
+		"CRAWL_STATUS_ACCEPTED":       1,
+		"CRAWL_STATUS_INVALID":        2,
+		"CRAWL_STATUS_PUBLISH_FAILED": 3,
+		"CRAWL_STATUS_DUPLICATE":      4,
+	}
+)
+
+func (x CrawlStatus) Enum() *CrawlStatus {
+	p := new(CrawlStatus)
+	*p = x
+	return p
+}
+
+func (x CrawlStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (CrawlStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_webcrawler_service_proto_enumTypes[1].Descriptor()
+}
+
+func (CrawlStatus) Type() protoreflect.EnumType {
+	return &file_webcrawler_service_proto_enumTypes[1]
+}
+
+func (x CrawlStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use CrawlStatus.Descriptor instead.
+func (CrawlStatus) EnumDescriptor() ([]byte, []int) {
+	return file_webcrawler_service_proto_rawDescGZIP(), []int{1}
+}
+
+type JobStatus int32
+
+const (
+	JobStatus_JOB_STATUS_UNSPECIFIED JobStatus = 0
+	JobStatus_JOB_STATUS_QUEUED      JobStatus = 1
+	JobStatus_JOB_STATUS_RUNNING     JobStatus = 2
+	JobStatus_JOB_STATUS_FAILED      JobStatus = 3
+	JobStatus_JOB_STATUS_DONE        JobStatus = 4
+)
+
+// Enum value maps for JobStatus.
+var (
+	JobStatus_name = map[int32]string{
+		0: "JOB_STATUS_UNSPECIFIED",
+		1: "JOB_STATUS_QUEUED",
+		2: "JOB_STATUS_RUNNING",
+		3: "JOB_STATUS_FAILED",
+		4: "JOB_STATUS_DONE",
+	}
+	JobStatus_value = map[string]int32{
+		"JOB_STATUS_UNSPECIFIED": 0,
+		"JOB_STATUS_QUEUED":      1,
+		"JOB_STATUS_RUNNING":     2,
+		"JOB_STATUS_FAILED":      3,
+		"JOB_STATUS_DONE":        4,
+	}
+)
+
+func (x JobStatus) Enum() *JobStatus {
+	p := new(JobStatus)
+	*p = x
+	return p
+}
+
+func (x JobStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (JobStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_webcrawler_service_proto_enumTypes[2].Descriptor()
+}
+
+func (JobStatus) Type() protoreflect.EnumType {
+	return &file_webcrawler_service_proto_enumTypes[2]
+}
+
+func (x JobStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use JobStatus.Descriptor instead.
+func (JobStatus) EnumDescriptor() ([]byte, []int) {
+	return file_webcrawler_service_proto_rawDescGZIP(), []int{2}
+}
+
+type ChannelStatus int32
+
+const (
+	ChannelStatus_CHANNEL_STATUS_UNSPECIFIED ChannelStatus = 0
+	ChannelStatus_CHANNEL_STATUS_ACTIVE      ChannelStatus = 1
+	// Channel page is not found in the store anymore, delisted channels are not recrawled periodically
+	ChannelStatus_CHANNEL_STATUS_DELISTED ChannelStatus = 2
+)
+
+// Enum value maps for ChannelStatus.
+var (
+	ChannelStatus_name = map[int32]string{
+		0: "CHANNEL_STATUS_UNSPECIFIED",
+		1: "CHANNEL_STATUS_ACTIVE",
+		2: "CHANNEL_STATUS_DELISTED",
+	}
+	ChannelStatus_value = map[string]int32{
+		"CHANNEL_STATUS_UNSPECIFIED": 0,
+		"CHANNEL_STATUS_ACTIVE":      1,
+		"CHANNEL_STATUS_DELISTED":    2,
+	}
+)
+
+func (x ChannelStatus) Enum() *ChannelStatus {
+	p := new(ChannelStatus)
+	*p = x
+	return p
+}
+
+func (x ChannelStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ChannelStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_webcrawler_service_proto_enumTypes[3].Descriptor()
+}
+
+func (ChannelStatus) Type() protoreflect.EnumType {
+	return &file_webcrawler_service_proto_enumTypes[3]
+}
+
+func (x ChannelStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ChannelStatus.Descriptor instead.
+func (ChannelStatus) EnumDescriptor() ([]byte, []int) {
+	return file_webcrawler_service_proto_rawDescGZIP(), []int{3}
+}
+
 type CrawlerRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Url string `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	Url string `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	// Locale the channel page is crawled in, e.g. en-GB, the store default is used when it's empty
+	Locale   string        `protobuf:"bytes,2,opt,name=locale,proto3" json:"locale,omitempty"`
+	Priority CrawlPriority `protobuf:"varint,3,opt,name=priority,proto3,enum=webcrawler.CrawlPriority" json:"priority,omitempty"`
+}
+
+func (x *CrawlerRequest) Reset() {
+	*x = CrawlerRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_webcrawler_service_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CrawlerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CrawlerRequest) ProtoMessage() {}
+
+func (x *CrawlerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_webcrawler_service_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CrawlerRequest.ProtoReflect.Descriptor instead.
+func (*CrawlerRequest) Descriptor() ([]byte, []int) {
+	return file_webcrawler_service_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CrawlerRequest) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *CrawlerRequest) GetLocale() string {
+	if x != nil {
+		return x.Locale
+	}
+	return ""
+}
+
+func (x *CrawlerRequest) GetPriority() CrawlPriority {
+	if x != nil {
+		return x.Priority
+	}
+	return CrawlPriority_CRAWL_PRIORITY_UNSPECIFIED
+}
+
+type BatchCrawlerRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Urls []*CrawlerRequest `protobuf:"bytes,1,rep,name=urls,proto3" json:"urls,omitempty"`
+}
+
+func (x *BatchCrawlerRequest) Reset() {
+	*x = BatchCrawlerRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_webcrawler_service_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BatchCrawlerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchCrawlerRequest) ProtoMessage() {}
+
+func (x *BatchCrawlerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_webcrawler_service_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchCrawlerRequest.ProtoReflect.Descriptor instead.
+func (*BatchCrawlerRequest) Descriptor() ([]byte, []int) {
+	return file_webcrawler_service_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *BatchCrawlerRequest) GetUrls() []*CrawlerRequest {
+	if x != nil {
+		return x.Urls
+	}
+	return nil
+}
+
+type Empty struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *Empty) Reset() {
+	*x = Empty{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_webcrawler_service_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Empty) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Empty) ProtoMessage() {}
+
+func (x *Empty) ProtoReflect() protoreflect.Message {
+	mi := &file_webcrawler_service_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Empty.ProtoReflect.Descriptor instead.
+func (*Empty) Descriptor() ([]byte, []int) {
+	return file_webcrawler_service_proto_rawDescGZIP(), []int{2}
+}
+
+type CrawlResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Url    string      `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	Status CrawlStatus `protobuf:"varint,2,opt,name=status,proto3,enum=webcrawler.CrawlStatus" json:"status,omitempty"`
+	JobId  string      `protobuf:"bytes,3,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	Error  string      `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+}
+
+func (x *CrawlResult) Reset() {
+	*x = CrawlResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_webcrawler_service_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CrawlResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CrawlResult) ProtoMessage() {}
+
+func (x *CrawlResult) ProtoReflect() protoreflect.Message {
+	mi := &file_webcrawler_service_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CrawlResult.ProtoReflect.Descriptor instead.
+func (*CrawlResult) Descriptor() ([]byte, []int) {
+	return file_webcrawler_service_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *CrawlResult) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *CrawlResult) GetStatus() CrawlStatus {
+	if x != nil {
+		return x.Status
+	}
+	return CrawlStatus_CRAWL_STATUS_UNSPECIFIED
+}
+
+func (x *CrawlResult) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *CrawlResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type BatchCrawlerResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Results []*CrawlResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+}
+
+func (x *BatchCrawlerResponse) Reset() {
+	*x = BatchCrawlerResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_webcrawler_service_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BatchCrawlerResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchCrawlerResponse) ProtoMessage() {}
+
+func (x *BatchCrawlerResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_webcrawler_service_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchCrawlerResponse.ProtoReflect.Descriptor instead.
+func (*BatchCrawlerResponse) Descriptor() ([]byte, []int) {
+	return file_webcrawler_service_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *BatchCrawlerResponse) GetResults() []*CrawlResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type SubmitUrlsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Position of the url in the submitted list, the acknowledgement of the url refers to it
+	Offset uint64 `protobuf:"varint,1,opt,name=offset,proto3" json:"offset,omitempty"`
+	Url    string `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	// Locale the channel page is crawled in, the store default is used when it's empty
+	Locale   string        `protobuf:"bytes,3,opt,name=locale,proto3" json:"locale,omitempty"`
+	Priority CrawlPriority `protobuf:"varint,4,opt,name=priority,proto3,enum=webcrawler.CrawlPriority" json:"priority,omitempty"`
+}
+
+func (x *SubmitUrlsRequest) Reset() {
+	*x = SubmitUrlsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_webcrawler_service_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SubmitUrlsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitUrlsRequest) ProtoMessage() {}
+
+func (x *SubmitUrlsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_webcrawler_service_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitUrlsRequest.ProtoReflect.Descriptor instead.
+func (*SubmitUrlsRequest) Descriptor() ([]byte, []int) {
+	return file_webcrawler_service_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *SubmitUrlsRequest) GetOffset() uint64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *SubmitUrlsRequest) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *SubmitUrlsRequest) GetLocale() string {
+	if x != nil {
+		return x.Locale
+	}
+	return ""
+}
+
+func (x *SubmitUrlsRequest) GetPriority() CrawlPriority {
+	if x != nil {
+		return x.Priority
+	}
+	return CrawlPriority_CRAWL_PRIORITY_UNSPECIFIED
+}
+
+type SubmitUrlsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Offset uint64       `protobuf:"varint,1,opt,name=offset,proto3" json:"offset,omitempty"`
+	Result *CrawlResult `protobuf:"bytes,2,opt,name=result,proto3" json:"result,omitempty"`
+}
+
+func (x *SubmitUrlsResponse) Reset() {
+	*x = SubmitUrlsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_webcrawler_service_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SubmitUrlsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitUrlsResponse) ProtoMessage() {}
+
+func (x *SubmitUrlsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_webcrawler_service_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitUrlsResponse.ProtoReflect.Descriptor instead.
+func (*SubmitUrlsResponse) Descriptor() ([]byte, []int) {
+	return file_webcrawler_service_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *SubmitUrlsResponse) GetOffset() uint64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *SubmitUrlsResponse) GetResult() *CrawlResult {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+type Job struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id        string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Url       string                 `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	Status    JobStatus              `protobuf:"varint,3,opt,name=status,proto3,enum=webcrawler.JobStatus" json:"status,omitempty"`
+	Error     string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	CreatedAt *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	// Id of the page artifact captured when the last crawl attempt failed
+	ArtifactId string        `protobuf:"bytes,7,opt,name=artifact_id,json=artifactId,proto3" json:"artifact_id,omitempty"`
+	Locale     string        `protobuf:"bytes,8,opt,name=locale,proto3" json:"locale,omitempty"`
+	Priority   CrawlPriority `protobuf:"varint,9,opt,name=priority,proto3,enum=webcrawler.CrawlPriority" json:"priority,omitempty"`
+}
+
+func (x *Job) Reset() {
+	*x = Job{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_webcrawler_service_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Job) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Job) ProtoMessage() {}
+
+func (x *Job) ProtoReflect() protoreflect.Message {
+	mi := &file_webcrawler_service_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Job.ProtoReflect.Descriptor instead.
+func (*Job) Descriptor() ([]byte, []int) {
+	return file_webcrawler_service_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Job) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Job) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Job) GetStatus() JobStatus {
+	if x != nil {
+		return x.Status
+	}
+	return JobStatus_JOB_STATUS_UNSPECIFIED
+}
+
+func (x *Job) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *Job) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Job) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *Job) GetArtifactId() string {
+	if x != nil {
+		return x.ArtifactId
+	}
+	return ""
+}
+
+func (x *Job) GetLocale() string {
+	if x != nil {
+		return x.Locale
+	}
+	return ""
+}
+
+func (x *Job) GetPriority() CrawlPriority {
+	if x != nil {
+		return x.Priority
+	}
+	return CrawlPriority_CRAWL_PRIORITY_UNSPECIFIED
+}
+
+type GetJobRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	JobId string `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+}
+
+func (x *GetJobRequest) Reset() {
+	*x = GetJobRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_webcrawler_service_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetJobRequest) ProtoMessage() {}
+
+func (x *GetJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_webcrawler_service_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetJobRequest.ProtoReflect.Descriptor instead.
+func (*GetJobRequest) Descriptor() ([]byte, []int) {
+	return file_webcrawler_service_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GetJobRequest) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+type WatchJobsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	JobIds []string `protobuf:"bytes,1,rep,name=job_ids,json=jobIds,proto3" json:"job_ids,omitempty"`
+}
+
+func (x *WatchJobsRequest) Reset() {
+	*x = WatchJobsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_webcrawler_service_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WatchJobsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchJobsRequest) ProtoMessage() {}
+
+func (x *WatchJobsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_webcrawler_service_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchJobsRequest.ProtoReflect.Descriptor instead.
+func (*WatchJobsRequest) Descriptor() ([]byte, []int) {
+	return file_webcrawler_service_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *WatchJobsRequest) GetJobIds() []string {
+	if x != nil {
+		return x.JobIds
+	}
+	return nil
+}
+
+type GetChannelRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Identifier:
+	//	*GetChannelRequest_Url
+	//	*GetChannelRequest_ChannelId
+	Identifier isGetChannelRequest_Identifier `protobuf_oneof:"identifier"`
+	// Country the channel with the url was crawled in, e.g. GB, the store default is used when it's empty
+	Country string `protobuf:"bytes,3,opt,name=country,proto3" json:"country,omitempty"`
+}
+
+func (x *GetChannelRequest) Reset() {
+	*x = GetChannelRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_webcrawler_service_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetChannelRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetChannelRequest) ProtoMessage() {}
+
+func (x *GetChannelRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_webcrawler_service_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetChannelRequest.ProtoReflect.Descriptor instead.
+func (*GetChannelRequest) Descriptor() ([]byte, []int) {
+	return file_webcrawler_service_proto_rawDescGZIP(), []int{10}
+}
+
+func (m *GetChannelRequest) GetIdentifier() isGetChannelRequest_Identifier {
+	if m != nil {
+		return m.Identifier
+	}
+	return nil
+}
+
+func (x *GetChannelRequest) GetUrl() string {
+	if x, ok := x.GetIdentifier().(*GetChannelRequest_Url); ok {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *GetChannelRequest) GetChannelId() string {
+	if x, ok := x.GetIdentifier().(*GetChannelRequest_ChannelId); ok {
+		return x.ChannelId
+	}
+	return ""
+}
+
+func (x *GetChannelRequest) GetCountry() string {
+	if x != nil {
+		return x.Country
+	}
+	return ""
+}
+
+type isGetChannelRequest_Identifier interface {
+	isGetChannelRequest_Identifier()
+}
+
+type GetChannelRequest_Url struct {
+	Url string `protobuf:"bytes,1,opt,name=url,proto3,oneof"`
+}
+
+type GetChannelRequest_ChannelId struct {
+	ChannelId string `protobuf:"bytes,2,opt,name=channel_id,json=channelId,proto3,oneof"`
+}
+
+func (*GetChannelRequest_Url) isGetChannelRequest_Identifier() {}
+
+func (*GetChannelRequest_ChannelId) isGetChannelRequest_Identifier() {}
+
+type Channel struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id              string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	ApplicationName string                 `protobuf:"bytes,2,opt,name=application_name,json=applicationName,proto3" json:"application_name,omitempty"`
+	Url             string                 `protobuf:"bytes,3,opt,name=url,proto3" json:"url,omitempty"`
+	Rating          float32                `protobuf:"fixed32,4,opt,name=rating,proto3" json:"rating,omitempty"`
+	NumberOfRatings uint32                 `protobuf:"varint,5,opt,name=number_of_ratings,json=numberOfRatings,proto3" json:"number_of_ratings,omitempty"`
+	UpdatedAt       *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	// Version of the extraction profile the channel was crawled with
+	ProfileVersion string `protobuf:"bytes,7,opt,name=profile_version,json=profileVersion,proto3" json:"profile_version,omitempty"`
+	// Identifier of the app store the channel was crawled from
+	Store  string        `protobuf:"bytes,8,opt,name=store,proto3" json:"store,omitempty"`
+	Status ChannelStatus `protobuf:"varint,9,opt,name=status,proto3,enum=webcrawler.ChannelStatus" json:"status,omitempty"`
+	// Time the channel was found delisted, set only for delisted channels
+	DelistedAt *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=delisted_at,json=delistedAt,proto3" json:"delisted_at,omitempty"`
+	// Details are empty when the store page does not show them
+	Developer     string `protobuf:"bytes,11,opt,name=developer,proto3" json:"developer,omitempty"`
+	Category      string `protobuf:"bytes,12,opt,name=category,proto3" json:"category,omitempty"`
+	Price         string `protobuf:"bytes,13,opt,name=price,proto3" json:"price,omitempty"`
+	Description   string `protobuf:"bytes,14,opt,name=description,proto3" json:"description,omitempty"`
+	ContentRating string `protobuf:"bytes,15,opt,name=content_rating,json=contentRating,proto3" json:"content_rating,omitempty"`
+	// Amounts of 1 to 5 star ratings, empty when the store page does not show them
+	StarDistribution []uint32 `protobuf:"varint,16,rep,packed,name=star_distribution,json=starDistribution,proto3" json:"star_distribution,omitempty"`
+	// Identifier of the channel in the store derived from the url, stable across renames
+	StoreChannelId string `protobuf:"bytes,17,opt,name=store_channel_id,json=storeChannelId,proto3" json:"store_channel_id,omitempty"`
+	// Renames of the channel from the oldest one
+	NameChanges []*ChannelNameChange `protobuf:"bytes,18,rep,name=name_changes,json=nameChanges,proto3" json:"name_changes,omitempty"`
+	// Proxy the page was last crawled through, empty when it was crawled directly
+	Proxy string `protobuf:"bytes,19,opt,name=proxy,proto3" json:"proxy,omitempty"`
+	// Locale the page was crawled in, the channel is stored for every country separately
+	Locale string `protobuf:"bytes,20,opt,name=locale,proto3" json:"locale,omitempty"`
+	// Country of the locale, empty for the store default
+	Country string `protobuf:"bytes,21,opt,name=country,proto3" json:"country,omitempty"`
+}
+
+func (x *Channel) Reset() {
+	*x = Channel{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_webcrawler_service_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Channel) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Channel) ProtoMessage() {}
+
+func (x *Channel) ProtoReflect() protoreflect.Message {
+	mi := &file_webcrawler_service_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Channel.ProtoReflect.Descriptor instead.
+func (*Channel) Descriptor() ([]byte, []int) {
+	return file_webcrawler_service_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *Channel) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Channel) GetApplicationName() string {
+	if x != nil {
+		return x.ApplicationName
+	}
+	return ""
+}
+
+func (x *Channel) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Channel) GetRating() float32 {
+	if x != nil {
+		return x.Rating
+	}
+	return 0
+}
+
+func (x *Channel) GetNumberOfRatings() uint32 {
+	if x != nil {
+		return x.NumberOfRatings
+	}
+	return 0
+}
+
+func (x *Channel) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *Channel) GetProfileVersion() string {
+	if x != nil {
+		return x.ProfileVersion
+	}
+	return ""
+}
+
+func (x *Channel) GetStore() string {
+	if x != nil {
+		return x.Store
+	}
+	return ""
+}
+
+func (x *Channel) GetStatus() ChannelStatus {
+	if x != nil {
+		return x.Status
+	}
+	return ChannelStatus_CHANNEL_STATUS_UNSPECIFIED
+}
+
+func (x *Channel) GetDelistedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DelistedAt
+	}
+	return nil
+}
+
+func (x *Channel) GetDeveloper() string {
+	if x != nil {
+		return x.Developer
+	}
+	return ""
+}
+
+func (x *Channel) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *Channel) GetPrice() string {
+	if x != nil {
+		return x.Price
+	}
+	return ""
+}
+
+func (x *Channel) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Channel) GetContentRating() string {
+	if x != nil {
+		return x.ContentRating
+	}
+	return ""
+}
+
+func (x *Channel) GetStarDistribution() []uint32 {
+	if x != nil {
+		return x.StarDistribution
+	}
+	return nil
+}
+
+func (x *Channel) GetStoreChannelId() string {
+	if x != nil {
+		return x.StoreChannelId
+	}
+	return ""
+}
+
+func (x *Channel) GetNameChanges() []*ChannelNameChange {
+	if x != nil {
+		return x.NameChanges
+	}
+	return nil
+}
+
+func (x *Channel) GetProxy() string {
+	if x != nil {
+		return x.Proxy
+	}
+	return ""
+}
+
+func (x *Channel) GetLocale() string {
+	if x != nil {
+		return x.Locale
+	}
+	return ""
+}
+
+func (x *Channel) GetCountry() string {
+	if x != nil {
+		return x.Country
+	}
+	return ""
+}
+
+type ChannelNameChange struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	From      string                 `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	To        string                 `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	ChangedAt *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=changed_at,json=changedAt,proto3" json:"changed_at,omitempty"`
 }
 
-func (x *CrawlerRequest) Reset() {
-	*x = CrawlerRequest{}
+func (x *ChannelNameChange) Reset() {
+	*x = ChannelNameChange{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_webcrawler_service_proto_msgTypes[0]
+		mi := &file_webcrawler_service_proto_msgTypes[12]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *CrawlerRequest) String() string {
+func (x *ChannelNameChange) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CrawlerRequest) ProtoMessage() {}
+func (*ChannelNameChange) ProtoMessage() {}
 
-func (x *CrawlerRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_webcrawler_service_proto_msgTypes[0]
+func (x *ChannelNameChange) ProtoReflect() protoreflect.Message {
+	mi := &file_webcrawler_service_proto_msgTypes[12]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -55,43 +1179,67 @@ func (x *CrawlerRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CrawlerRequest.ProtoReflect.Descriptor instead.
-func (*CrawlerRequest) Descriptor() ([]byte, []int) {
-	return file_webcrawler_service_proto_rawDescGZIP(), []int{0}
+// Deprecated: Use ChannelNameChange.ProtoReflect.Descriptor instead.
+func (*ChannelNameChange) Descriptor() ([]byte, []int) {
+	return file_webcrawler_service_proto_rawDescGZIP(), []int{12}
 }
 
-func (x *CrawlerRequest) GetUrl() string {
+func (x *ChannelNameChange) GetFrom() string {
 	if x != nil {
-		return x.Url
+		return x.From
 	}
 	return ""
 }
 
-type BatchCrawlerRequest struct {
+func (x *ChannelNameChange) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *ChannelNameChange) GetChangedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ChangedAt
+	}
+	return nil
+}
+
+type ListChannelsRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Urls []*CrawlerRequest `protobuf:"bytes,1,rep,name=urls,proto3" json:"urls,omitempty"`
+	MinRating          *float32               `protobuf:"fixed32,1,opt,name=min_rating,json=minRating,proto3,oneof" json:"min_rating,omitempty"`
+	MinNumberOfRatings *uint32                `protobuf:"varint,2,opt,name=min_number_of_ratings,json=minNumberOfRatings,proto3,oneof" json:"min_number_of_ratings,omitempty"`
+	NamePrefix         string                 `protobuf:"bytes,3,opt,name=name_prefix,json=namePrefix,proto3" json:"name_prefix,omitempty"`
+	UpdatedSince       *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=updated_since,json=updatedSince,proto3" json:"updated_since,omitempty"`
+	PageSize           uint32                 `protobuf:"varint,5,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	Cursor             string                 `protobuf:"bytes,6,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	// Lists channels of any status when unspecified
+	Status ChannelStatus `protobuf:"varint,7,opt,name=status,proto3,enum=webcrawler.ChannelStatus" json:"status,omitempty"`
+	// Lists channels crawled in the country, channels of all the countries are listed when unset,
+	// empty country lists the ones crawled in the store default
+	Country *string `protobuf:"bytes,8,opt,name=country,proto3,oneof" json:"country,omitempty"`
 }
 
-func (x *BatchCrawlerRequest) Reset() {
-	*x = BatchCrawlerRequest{}
+func (x *ListChannelsRequest) Reset() {
+	*x = ListChannelsRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_webcrawler_service_proto_msgTypes[1]
+		mi := &file_webcrawler_service_proto_msgTypes[13]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *BatchCrawlerRequest) String() string {
+func (x *ListChannelsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*BatchCrawlerRequest) ProtoMessage() {}
+func (*ListChannelsRequest) ProtoMessage() {}
 
-func (x *BatchCrawlerRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_webcrawler_service_proto_msgTypes[1]
+func (x *ListChannelsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_webcrawler_service_proto_msgTypes[13]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -102,41 +1250,93 @@ func (x *BatchCrawlerRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use BatchCrawlerRequest.ProtoReflect.Descriptor instead.
-func (*BatchCrawlerRequest) Descriptor() ([]byte, []int) {
-	return file_webcrawler_service_proto_rawDescGZIP(), []int{1}
+// Deprecated: Use ListChannelsRequest.ProtoReflect.Descriptor instead.
+func (*ListChannelsRequest) Descriptor() ([]byte, []int) {
+	return file_webcrawler_service_proto_rawDescGZIP(), []int{13}
 }
 
-func (x *BatchCrawlerRequest) GetUrls() []*CrawlerRequest {
+func (x *ListChannelsRequest) GetMinRating() float32 {
+	if x != nil && x.MinRating != nil {
+		return *x.MinRating
+	}
+	return 0
+}
+
+func (x *ListChannelsRequest) GetMinNumberOfRatings() uint32 {
+	if x != nil && x.MinNumberOfRatings != nil {
+		return *x.MinNumberOfRatings
+	}
+	return 0
+}
+
+func (x *ListChannelsRequest) GetNamePrefix() string {
 	if x != nil {
-		return x.Urls
+		return x.NamePrefix
+	}
+	return ""
+}
+
+func (x *ListChannelsRequest) GetUpdatedSince() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedSince
 	}
 	return nil
 }
 
-type Empty struct {
+func (x *ListChannelsRequest) GetPageSize() uint32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListChannelsRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *ListChannelsRequest) GetStatus() ChannelStatus {
+	if x != nil {
+		return x.Status
+	}
+	return ChannelStatus_CHANNEL_STATUS_UNSPECIFIED
+}
+
+func (x *ListChannelsRequest) GetCountry() string {
+	if x != nil && x.Country != nil {
+		return *x.Country
+	}
+	return ""
+}
+
+type ListChannelsResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
+
+	Channels   []*Channel `protobuf:"bytes,1,rep,name=channels,proto3" json:"channels,omitempty"`
+	NextCursor string     `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
 }
 
-func (x *Empty) Reset() {
-	*x = Empty{}
+func (x *ListChannelsResponse) Reset() {
+	*x = ListChannelsResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_webcrawler_service_proto_msgTypes[2]
+		mi := &file_webcrawler_service_proto_msgTypes[14]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *Empty) String() string {
+func (x *ListChannelsResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Empty) ProtoMessage() {}
+func (*ListChannelsResponse) ProtoMessage() {}
 
-func (x *Empty) ProtoReflect() protoreflect.Message {
-	mi := &file_webcrawler_service_proto_msgTypes[2]
+func (x *ListChannelsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_webcrawler_service_proto_msgTypes[14]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -147,9 +1347,23 @@ func (x *Empty) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Empty.ProtoReflect.Descriptor instead.
-func (*Empty) Descriptor() ([]byte, []int) {
-	return file_webcrawler_service_proto_rawDescGZIP(), []int{2}
+// Deprecated: Use ListChannelsResponse.ProtoReflect.Descriptor instead.
+func (*ListChannelsResponse) Descriptor() ([]byte, []int) {
+	return file_webcrawler_service_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ListChannelsResponse) GetChannels() []*Channel {
+	if x != nil {
+		return x.Channels
+	}
+	return nil
+}
+
+func (x *ListChannelsResponse) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
 }
 
 var File_webcrawler_service_proto protoreflect.FileDescriptor
@@ -157,25 +1371,235 @@ var File_webcrawler_service_proto protoreflect.FileDescriptor
 var file_webcrawler_service_proto_rawDesc = []byte{
 	0x0a, 0x18, 0x77, 0x65, 0x62, 0x63, 0x72, 0x61, 0x77, 0x6c, 0x65, 0x72, 0x2f, 0x73, 0x65, 0x72,
 	0x76, 0x69, 0x63, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0a, 0x77, 0x65, 0x62, 0x63,
-	0x72, 0x61, 0x77, 0x6c, 0x65, 0x72, 0x22, 0x22, 0x0a, 0x0e, 0x43, 0x72, 0x61, 0x77, 0x6c, 0x65,
-	0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x75, 0x72, 0x6c, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x75, 0x72, 0x6c, 0x22, 0x45, 0x0a, 0x13, 0x42, 0x61,
+	0x72, 0x61, 0x77, 0x6c, 0x65, 0x72, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
+	0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x71, 0x0a, 0x0e, 0x43, 0x72, 0x61, 0x77, 0x6c,
+	0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x75, 0x72, 0x6c,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x75, 0x72, 0x6c, 0x12, 0x16, 0x0a, 0x06, 0x6c,
+	0x6f, 0x63, 0x61, 0x6c, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6c, 0x6f, 0x63,
+	0x61, 0x6c, 0x65, 0x12, 0x35, 0x0a, 0x08, 0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x19, 0x2e, 0x77, 0x65, 0x62, 0x63, 0x72, 0x61, 0x77, 0x6c,
+	0x65, 0x72, 0x2e, 0x43, 0x72, 0x61, 0x77, 0x6c, 0x50, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79,
+	0x52, 0x08, 0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x22, 0x45, 0x0a, 0x13, 0x42, 0x61,
 	0x74, 0x63, 0x68, 0x43, 0x72, 0x61, 0x77, 0x6c, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
 	0x74, 0x12, 0x2e, 0x0a, 0x04, 0x75, 0x72, 0x6c, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32,
 	0x1a, 0x2e, 0x77, 0x65, 0x62, 0x63, 0x72, 0x61, 0x77, 0x6c, 0x65, 0x72, 0x2e, 0x43, 0x72, 0x61,
 	0x77, 0x6c, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x52, 0x04, 0x75, 0x72, 0x6c,
-	0x73, 0x22, 0x07, 0x0a, 0x05, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x32, 0x8d, 0x01, 0x0a, 0x11, 0x77,
+	0x73, 0x22, 0x07, 0x0a, 0x05, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x7d, 0x0a, 0x0b, 0x43, 0x72,
+	0x61, 0x77, 0x6c, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x75, 0x72, 0x6c,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x75, 0x72, 0x6c, 0x12, 0x2f, 0x0a, 0x06, 0x73,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x17, 0x2e, 0x77, 0x65,
+	0x62, 0x63, 0x72, 0x61, 0x77, 0x6c, 0x65, 0x72, 0x2e, 0x43, 0x72, 0x61, 0x77, 0x6c, 0x53, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x15, 0x0a, 0x06,
+	0x6a, 0x6f, 0x62, 0x5f, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6a, 0x6f,
+	0x62, 0x49, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x22, 0x49, 0x0a, 0x14, 0x42, 0x61, 0x74,
+	0x63, 0x68, 0x43, 0x72, 0x61, 0x77, 0x6c, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x31, 0x0a, 0x07, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x17, 0x2e, 0x77, 0x65, 0x62, 0x63, 0x72, 0x61, 0x77, 0x6c, 0x65, 0x72, 0x2e,
+	0x43, 0x72, 0x61, 0x77, 0x6c, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x52, 0x07, 0x72, 0x65, 0x73,
+	0x75, 0x6c, 0x74, 0x73, 0x22, 0x8c, 0x01, 0x0a, 0x11, 0x53, 0x75, 0x62, 0x6d, 0x69, 0x74, 0x55,
+	0x72, 0x6c, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x6f, 0x66,
+	0x66, 0x73, 0x65, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x6f, 0x66, 0x66, 0x73,
+	0x65, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x75, 0x72, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x03, 0x75, 0x72, 0x6c, 0x12, 0x16, 0x0a, 0x06, 0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x65, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x65, 0x12, 0x35, 0x0a, 0x08,
+	0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x19,
+	0x2e, 0x77, 0x65, 0x62, 0x63, 0x72, 0x61, 0x77, 0x6c, 0x65, 0x72, 0x2e, 0x43, 0x72, 0x61, 0x77,
+	0x6c, 0x50, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x52, 0x08, 0x70, 0x72, 0x69, 0x6f, 0x72,
+	0x69, 0x74, 0x79, 0x22, 0x5d, 0x0a, 0x12, 0x53, 0x75, 0x62, 0x6d, 0x69, 0x74, 0x55, 0x72, 0x6c,
+	0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x6f, 0x66, 0x66,
+	0x73, 0x65, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x6f, 0x66, 0x66, 0x73, 0x65,
+	0x74, 0x12, 0x2f, 0x0a, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x17, 0x2e, 0x77, 0x65, 0x62, 0x63, 0x72, 0x61, 0x77, 0x6c, 0x65, 0x72, 0x2e, 0x43,
+	0x72, 0x61, 0x77, 0x6c, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x52, 0x06, 0x72, 0x65, 0x73, 0x75,
+	0x6c, 0x74, 0x22, 0xd2, 0x02, 0x0a, 0x03, 0x4a, 0x6f, 0x62, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x10, 0x0a, 0x03, 0x75, 0x72,
+	0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x75, 0x72, 0x6c, 0x12, 0x2d, 0x0a, 0x06,
+	0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x15, 0x2e, 0x77,
+	0x65, 0x62, 0x63, 0x72, 0x61, 0x77, 0x6c, 0x65, 0x72, 0x2e, 0x4a, 0x6f, 0x62, 0x53, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x65,
+	0x72, 0x72, 0x6f, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f,
+	0x72, 0x12, 0x39, 0x0a, 0x0a, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
+	0x70, 0x52, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x12, 0x39, 0x0a, 0x0a,
+	0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x75, 0x70,
+	0x64, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x61, 0x72, 0x74, 0x69, 0x66,
+	0x61, 0x63, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x61, 0x72,
+	0x74, 0x69, 0x66, 0x61, 0x63, 0x74, 0x49, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x6c, 0x6f, 0x63, 0x61,
+	0x6c, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x65,
+	0x12, 0x35, 0x0a, 0x08, 0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x18, 0x09, 0x20, 0x01,
+	0x28, 0x0e, 0x32, 0x19, 0x2e, 0x77, 0x65, 0x62, 0x63, 0x72, 0x61, 0x77, 0x6c, 0x65, 0x72, 0x2e,
+	0x43, 0x72, 0x61, 0x77, 0x6c, 0x50, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x52, 0x08, 0x70,
+	0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x22, 0x26, 0x0a, 0x0d, 0x47, 0x65, 0x74, 0x4a, 0x6f,
+	0x62, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x15, 0x0a, 0x06, 0x6a, 0x6f, 0x62, 0x5f,
+	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6a, 0x6f, 0x62, 0x49, 0x64, 0x22,
+	0x2b, 0x0a, 0x10, 0x57, 0x61, 0x74, 0x63, 0x68, 0x4a, 0x6f, 0x62, 0x73, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x17, 0x0a, 0x07, 0x6a, 0x6f, 0x62, 0x5f, 0x69, 0x64, 0x73, 0x18, 0x01,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x06, 0x6a, 0x6f, 0x62, 0x49, 0x64, 0x73, 0x22, 0x70, 0x0a, 0x11,
+	0x47, 0x65, 0x74, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x12, 0x0a, 0x03, 0x75, 0x72, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00,
+	0x52, 0x03, 0x75, 0x72, 0x6c, 0x12, 0x1f, 0x0a, 0x0a, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c,
+	0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x09, 0x63, 0x68, 0x61,
+	0x6e, 0x6e, 0x65, 0x6c, 0x49, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x72,
+	0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x72, 0x79,
+	0x42, 0x0c, 0x0a, 0x0a, 0x69, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x22, 0xfe,
+	0x05, 0x0a, 0x07, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x29, 0x0a, 0x10, 0x61, 0x70,
+	0x70, 0x6c, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x61, 0x70, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x75, 0x72, 0x6c, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x03, 0x75, 0x72, 0x6c, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x61, 0x74, 0x69, 0x6e,
+	0x67, 0x18, 0x04, 0x20, 0x01, 0x28, 0x02, 0x52, 0x06, 0x72, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x12,
+	0x2a, 0x0a, 0x11, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x5f, 0x6f, 0x66, 0x5f, 0x72, 0x61, 0x74,
+	0x69, 0x6e, 0x67, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0f, 0x6e, 0x75, 0x6d, 0x62,
+	0x65, 0x72, 0x4f, 0x66, 0x52, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x73, 0x12, 0x39, 0x0a, 0x0a, 0x75,
+	0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x75, 0x70, 0x64,
+	0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x12, 0x27, 0x0a, 0x0f, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c,
+	0x65, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0e, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12,
+	0x14, 0x0a, 0x05, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05,
+	0x73, 0x74, 0x6f, 0x72, 0x65, 0x12, 0x31, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18,
+	0x09, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x19, 0x2e, 0x77, 0x65, 0x62, 0x63, 0x72, 0x61, 0x77, 0x6c,
+	0x65, 0x72, 0x2e, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73,
+	0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x3b, 0x0a, 0x0b, 0x64, 0x65, 0x6c, 0x69,
+	0x73, 0x74, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0a, 0x64, 0x65, 0x6c, 0x69, 0x73,
+	0x74, 0x65, 0x64, 0x41, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x64, 0x65, 0x76, 0x65, 0x6c, 0x6f, 0x70,
+	0x65, 0x72, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x64, 0x65, 0x76, 0x65, 0x6c, 0x6f,
+	0x70, 0x65, 0x72, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x61, 0x74, 0x65, 0x67, 0x6f, 0x72, 0x79, 0x18,
+	0x0c, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x63, 0x61, 0x74, 0x65, 0x67, 0x6f, 0x72, 0x79, 0x12,
+	0x14, 0x0a, 0x05, 0x70, 0x72, 0x69, 0x63, 0x65, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05,
+	0x70, 0x72, 0x69, 0x63, 0x65, 0x12, 0x20, 0x0a, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70,
+	0x74, 0x69, 0x6f, 0x6e, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x73, 0x63,
+	0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x25, 0x0a, 0x0e, 0x63, 0x6f, 0x6e, 0x74, 0x65,
+	0x6e, 0x74, 0x5f, 0x72, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0d, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x52, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x12, 0x2b,
+	0x0a, 0x11, 0x73, 0x74, 0x61, 0x72, 0x5f, 0x64, 0x69, 0x73, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74,
+	0x69, 0x6f, 0x6e, 0x18, 0x10, 0x20, 0x03, 0x28, 0x0d, 0x52, 0x10, 0x73, 0x74, 0x61, 0x72, 0x44,
+	0x69, 0x73, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x28, 0x0a, 0x10, 0x73,
+	0x74, 0x6f, 0x72, 0x65, 0x5f, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x5f, 0x69, 0x64, 0x18,
+	0x11, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x43, 0x68, 0x61, 0x6e,
+	0x6e, 0x65, 0x6c, 0x49, 0x64, 0x12, 0x40, 0x0a, 0x0c, 0x6e, 0x61, 0x6d, 0x65, 0x5f, 0x63, 0x68,
+	0x61, 0x6e, 0x67, 0x65, 0x73, 0x18, 0x12, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x77, 0x65,
+	0x62, 0x63, 0x72, 0x61, 0x77, 0x6c, 0x65, 0x72, 0x2e, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c,
+	0x4e, 0x61, 0x6d, 0x65, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x52, 0x0b, 0x6e, 0x61, 0x6d, 0x65,
+	0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x70, 0x72, 0x6f, 0x78, 0x79,
+	0x18, 0x13, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x70, 0x72, 0x6f, 0x78, 0x79, 0x12, 0x16, 0x0a,
+	0x06, 0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x65, 0x18, 0x14, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6c,
+	0x6f, 0x63, 0x61, 0x6c, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x72, 0x79,
+	0x18, 0x15, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x72, 0x79, 0x22,
+	0x72, 0x0a, 0x11, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x4e, 0x61, 0x6d, 0x65, 0x43, 0x68,
+	0x61, 0x6e, 0x67, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x12, 0x0e, 0x0a, 0x02, 0x74, 0x6f, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x74, 0x6f, 0x12, 0x39, 0x0a, 0x0a, 0x63, 0x68, 0x61, 0x6e,
+	0x67, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65,
+	0x64, 0x41, 0x74, 0x22, 0x8f, 0x03, 0x0a, 0x13, 0x4c, 0x69, 0x73, 0x74, 0x43, 0x68, 0x61, 0x6e,
+	0x6e, 0x65, 0x6c, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x22, 0x0a, 0x0a, 0x6d,
+	0x69, 0x6e, 0x5f, 0x72, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x02, 0x48,
+	0x00, 0x52, 0x09, 0x6d, 0x69, 0x6e, 0x52, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x88, 0x01, 0x01, 0x12,
+	0x36, 0x0a, 0x15, 0x6d, 0x69, 0x6e, 0x5f, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x5f, 0x6f, 0x66,
+	0x5f, 0x72, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x48, 0x01,
+	0x52, 0x12, 0x6d, 0x69, 0x6e, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x4f, 0x66, 0x52, 0x61, 0x74,
+	0x69, 0x6e, 0x67, 0x73, 0x88, 0x01, 0x01, 0x12, 0x1f, 0x0a, 0x0b, 0x6e, 0x61, 0x6d, 0x65, 0x5f,
+	0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x6e, 0x61,
+	0x6d, 0x65, 0x50, 0x72, 0x65, 0x66, 0x69, 0x78, 0x12, 0x3f, 0x0a, 0x0d, 0x75, 0x70, 0x64, 0x61,
+	0x74, 0x65, 0x64, 0x5f, 0x73, 0x69, 0x6e, 0x63, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0c, 0x75, 0x70, 0x64,
+	0x61, 0x74, 0x65, 0x64, 0x53, 0x69, 0x6e, 0x63, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x70, 0x61, 0x67,
+	0x65, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x70, 0x61,
+	0x67, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x75, 0x72, 0x73, 0x6f, 0x72,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x63, 0x75, 0x72, 0x73, 0x6f, 0x72, 0x12, 0x31,
+	0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x19,
+	0x2e, 0x77, 0x65, 0x62, 0x63, 0x72, 0x61, 0x77, 0x6c, 0x65, 0x72, 0x2e, 0x43, 0x68, 0x61, 0x6e,
+	0x6e, 0x65, 0x6c, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x12, 0x1d, 0x0a, 0x07, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x72, 0x79, 0x18, 0x08, 0x20, 0x01,
+	0x28, 0x09, 0x48, 0x02, 0x52, 0x07, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x72, 0x79, 0x88, 0x01, 0x01,
+	0x42, 0x0d, 0x0a, 0x0b, 0x5f, 0x6d, 0x69, 0x6e, 0x5f, 0x72, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x42,
+	0x18, 0x0a, 0x16, 0x5f, 0x6d, 0x69, 0x6e, 0x5f, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x5f, 0x6f,
+	0x66, 0x5f, 0x72, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x73, 0x42, 0x0a, 0x0a, 0x08, 0x5f, 0x63, 0x6f,
+	0x75, 0x6e, 0x74, 0x72, 0x79, 0x22, 0x68, 0x0a, 0x14, 0x4c, 0x69, 0x73, 0x74, 0x43, 0x68, 0x61,
+	0x6e, 0x6e, 0x65, 0x6c, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2f, 0x0a,
+	0x08, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x13, 0x2e, 0x77, 0x65, 0x62, 0x63, 0x72, 0x61, 0x77, 0x6c, 0x65, 0x72, 0x2e, 0x43, 0x68, 0x61,
+	0x6e, 0x6e, 0x65, 0x6c, 0x52, 0x08, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x73, 0x12, 0x1f,
+	0x0a, 0x0b, 0x6e, 0x65, 0x78, 0x74, 0x5f, 0x63, 0x75, 0x72, 0x73, 0x6f, 0x72, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0a, 0x6e, 0x65, 0x78, 0x74, 0x43, 0x75, 0x72, 0x73, 0x6f, 0x72, 0x2a,
+	0x7b, 0x0a, 0x0d, 0x43, 0x72, 0x61, 0x77, 0x6c, 0x50, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79,
+	0x12, 0x1e, 0x0a, 0x1a, 0x43, 0x52, 0x41, 0x57, 0x4c, 0x5f, 0x50, 0x52, 0x49, 0x4f, 0x52, 0x49,
+	0x54, 0x59, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00,
+	0x12, 0x16, 0x0a, 0x12, 0x43, 0x52, 0x41, 0x57, 0x4c, 0x5f, 0x50, 0x52, 0x49, 0x4f, 0x52, 0x49,
+	0x54, 0x59, 0x5f, 0x4c, 0x4f, 0x57, 0x10, 0x01, 0x12, 0x19, 0x0a, 0x15, 0x43, 0x52, 0x41, 0x57,
+	0x4c, 0x5f, 0x50, 0x52, 0x49, 0x4f, 0x52, 0x49, 0x54, 0x59, 0x5f, 0x4e, 0x4f, 0x52, 0x4d, 0x41,
+	0x4c, 0x10, 0x02, 0x12, 0x17, 0x0a, 0x13, 0x43, 0x52, 0x41, 0x57, 0x4c, 0x5f, 0x50, 0x52, 0x49,
+	0x4f, 0x52, 0x49, 0x54, 0x59, 0x5f, 0x48, 0x49, 0x47, 0x48, 0x10, 0x03, 0x2a, 0x9d, 0x01, 0x0a,
+	0x0b, 0x43, 0x72, 0x61, 0x77, 0x6c, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x1c, 0x0a, 0x18,
+	0x43, 0x52, 0x41, 0x57, 0x4c, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x55, 0x4e, 0x53,
+	0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x19, 0x0a, 0x15, 0x43, 0x52,
+	0x41, 0x57, 0x4c, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x41, 0x43, 0x43, 0x45, 0x50,
+	0x54, 0x45, 0x44, 0x10, 0x01, 0x12, 0x18, 0x0a, 0x14, 0x43, 0x52, 0x41, 0x57, 0x4c, 0x5f, 0x53,
+	0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x49, 0x4e, 0x56, 0x41, 0x4c, 0x49, 0x44, 0x10, 0x02, 0x12,
+	0x1f, 0x0a, 0x1b, 0x43, 0x52, 0x41, 0x57, 0x4c, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f,
+	0x50, 0x55, 0x42, 0x4c, 0x49, 0x53, 0x48, 0x5f, 0x46, 0x41, 0x49, 0x4c, 0x45, 0x44, 0x10, 0x03,
+	0x12, 0x1a, 0x0a, 0x16, 0x43, 0x52, 0x41, 0x57, 0x4c, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53,
+	0x5f, 0x44, 0x55, 0x50, 0x4c, 0x49, 0x43, 0x41, 0x54, 0x45, 0x10, 0x04, 0x2a, 0x82, 0x01, 0x0a,
+	0x09, 0x4a, 0x6f, 0x62, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x1a, 0x0a, 0x16, 0x4a, 0x4f,
+	0x42, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49,
+	0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x15, 0x0a, 0x11, 0x4a, 0x4f, 0x42, 0x5f, 0x53, 0x54,
+	0x41, 0x54, 0x55, 0x53, 0x5f, 0x51, 0x55, 0x45, 0x55, 0x45, 0x44, 0x10, 0x01, 0x12, 0x16, 0x0a,
+	0x12, 0x4a, 0x4f, 0x42, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x52, 0x55, 0x4e, 0x4e,
+	0x49, 0x4e, 0x47, 0x10, 0x02, 0x12, 0x15, 0x0a, 0x11, 0x4a, 0x4f, 0x42, 0x5f, 0x53, 0x54, 0x41,
+	0x54, 0x55, 0x53, 0x5f, 0x46, 0x41, 0x49, 0x4c, 0x45, 0x44, 0x10, 0x03, 0x12, 0x13, 0x0a, 0x0f,
+	0x4a, 0x4f, 0x42, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x44, 0x4f, 0x4e, 0x45, 0x10,
+	0x04, 0x2a, 0x67, 0x0a, 0x0d, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x53, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x12, 0x1e, 0x0a, 0x1a, 0x43, 0x48, 0x41, 0x4e, 0x4e, 0x45, 0x4c, 0x5f, 0x53, 0x54,
+	0x41, 0x54, 0x55, 0x53, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44,
+	0x10, 0x00, 0x12, 0x19, 0x0a, 0x15, 0x43, 0x48, 0x41, 0x4e, 0x4e, 0x45, 0x4c, 0x5f, 0x53, 0x54,
+	0x41, 0x54, 0x55, 0x53, 0x5f, 0x41, 0x43, 0x54, 0x49, 0x56, 0x45, 0x10, 0x01, 0x12, 0x1b, 0x0a,
+	0x17, 0x43, 0x48, 0x41, 0x4e, 0x4e, 0x45, 0x4c, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f,
+	0x44, 0x45, 0x4c, 0x49, 0x53, 0x54, 0x45, 0x44, 0x10, 0x02, 0x32, 0xfc, 0x03, 0x0a, 0x11, 0x77,
 	0x65, 0x62, 0x43, 0x72, 0x61, 0x77, 0x6c, 0x65, 0x72, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65,
-	0x12, 0x36, 0x0a, 0x05, 0x43, 0x72, 0x61, 0x77, 0x6c, 0x12, 0x1a, 0x2e, 0x77, 0x65, 0x62, 0x63,
+	0x12, 0x3c, 0x0a, 0x05, 0x43, 0x72, 0x61, 0x77, 0x6c, 0x12, 0x1a, 0x2e, 0x77, 0x65, 0x62, 0x63,
 	0x72, 0x61, 0x77, 0x6c, 0x65, 0x72, 0x2e, 0x43, 0x72, 0x61, 0x77, 0x6c, 0x65, 0x72, 0x52, 0x65,
-	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x11, 0x2e, 0x77, 0x65, 0x62, 0x63, 0x72, 0x61, 0x77, 0x6c,
-	0x65, 0x72, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x40, 0x0a, 0x0a, 0x43, 0x72, 0x61, 0x77,
-	0x6c, 0x42, 0x61, 0x74, 0x63, 0x68, 0x12, 0x1f, 0x2e, 0x77, 0x65, 0x62, 0x63, 0x72, 0x61, 0x77,
-	0x6c, 0x65, 0x72, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x43, 0x72, 0x61, 0x77, 0x6c, 0x65, 0x72,
-	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x11, 0x2e, 0x77, 0x65, 0x62, 0x63, 0x72, 0x61,
-	0x77, 0x6c, 0x65, 0x72, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x42, 0x0d, 0x5a, 0x0b, 0x2f, 0x77,
-	0x65, 0x62, 0x63, 0x72, 0x61, 0x77, 0x6c, 0x65, 0x72, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x33,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x17, 0x2e, 0x77, 0x65, 0x62, 0x63, 0x72, 0x61, 0x77, 0x6c,
+	0x65, 0x72, 0x2e, 0x43, 0x72, 0x61, 0x77, 0x6c, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x4f,
+	0x0a, 0x0a, 0x43, 0x72, 0x61, 0x77, 0x6c, 0x42, 0x61, 0x74, 0x63, 0x68, 0x12, 0x1f, 0x2e, 0x77,
+	0x65, 0x62, 0x63, 0x72, 0x61, 0x77, 0x6c, 0x65, 0x72, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x43,
+	0x72, 0x61, 0x77, 0x6c, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x20, 0x2e,
+	0x77, 0x65, 0x62, 0x63, 0x72, 0x61, 0x77, 0x6c, 0x65, 0x72, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68,
+	0x43, 0x72, 0x61, 0x77, 0x6c, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x4f, 0x0a, 0x0a, 0x53, 0x75, 0x62, 0x6d, 0x69, 0x74, 0x55, 0x72, 0x6c, 0x73, 0x12, 0x1d, 0x2e,
+	0x77, 0x65, 0x62, 0x63, 0x72, 0x61, 0x77, 0x6c, 0x65, 0x72, 0x2e, 0x53, 0x75, 0x62, 0x6d, 0x69,
+	0x74, 0x55, 0x72, 0x6c, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1e, 0x2e, 0x77,
+	0x65, 0x62, 0x63, 0x72, 0x61, 0x77, 0x6c, 0x65, 0x72, 0x2e, 0x53, 0x75, 0x62, 0x6d, 0x69, 0x74,
+	0x55, 0x72, 0x6c, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x28, 0x01, 0x30, 0x01,
+	0x12, 0x40, 0x0a, 0x0a, 0x47, 0x65, 0x74, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x12, 0x1d,
+	0x2e, 0x77, 0x65, 0x62, 0x63, 0x72, 0x61, 0x77, 0x6c, 0x65, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x43,
+	0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x13, 0x2e,
+	0x77, 0x65, 0x62, 0x63, 0x72, 0x61, 0x77, 0x6c, 0x65, 0x72, 0x2e, 0x43, 0x68, 0x61, 0x6e, 0x6e,
+	0x65, 0x6c, 0x12, 0x51, 0x0a, 0x0c, 0x4c, 0x69, 0x73, 0x74, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65,
+	0x6c, 0x73, 0x12, 0x1f, 0x2e, 0x77, 0x65, 0x62, 0x63, 0x72, 0x61, 0x77, 0x6c, 0x65, 0x72, 0x2e,
+	0x4c, 0x69, 0x73, 0x74, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x73, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x20, 0x2e, 0x77, 0x65, 0x62, 0x63, 0x72, 0x61, 0x77, 0x6c, 0x65, 0x72,
+	0x2e, 0x4c, 0x69, 0x73, 0x74, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x73, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x34, 0x0a, 0x06, 0x47, 0x65, 0x74, 0x4a, 0x6f, 0x62, 0x12,
+	0x19, 0x2e, 0x77, 0x65, 0x62, 0x63, 0x72, 0x61, 0x77, 0x6c, 0x65, 0x72, 0x2e, 0x47, 0x65, 0x74,
+	0x4a, 0x6f, 0x62, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0f, 0x2e, 0x77, 0x65, 0x62,
+	0x63, 0x72, 0x61, 0x77, 0x6c, 0x65, 0x72, 0x2e, 0x4a, 0x6f, 0x62, 0x12, 0x3c, 0x0a, 0x09, 0x57,
+	0x61, 0x74, 0x63, 0x68, 0x4a, 0x6f, 0x62, 0x73, 0x12, 0x1c, 0x2e, 0x77, 0x65, 0x62, 0x63, 0x72,
+	0x61, 0x77, 0x6c, 0x65, 0x72, 0x2e, 0x57, 0x61, 0x74, 0x63, 0x68, 0x4a, 0x6f, 0x62, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0f, 0x2e, 0x77, 0x65, 0x62, 0x63, 0x72, 0x61, 0x77,
+	0x6c, 0x65, 0x72, 0x2e, 0x4a, 0x6f, 0x62, 0x30, 0x01, 0x42, 0x0d, 0x5a, 0x0b, 0x2f, 0x77, 0x65,
+	0x62, 0x63, 0x72, 0x61, 0x77, 0x6c, 0x65, 0x72, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -190,23 +1614,68 @@ func file_webcrawler_service_proto_rawDescGZIP() []byte {
 	return file_webcrawler_service_proto_rawDescData
 }
 
-var file_webcrawler_service_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_webcrawler_service_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
+var file_webcrawler_service_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
 var file_webcrawler_service_proto_goTypes = []interface{}{
-	(*CrawlerRequest)(nil),      // 0: webcrawler.CrawlerRequest
-	(*BatchCrawlerRequest)(nil), // 1: webcrawler.BatchCrawlerRequest
-	(*Empty)(nil),               // 2: webcrawler.Empty
+	(CrawlPriority)(0),            // 0: webcrawler.CrawlPriority
+	(CrawlStatus)(0),              // 1: webcrawler.CrawlStatus
+	(JobStatus)(0),                // 2: webcrawler.JobStatus
+	(ChannelStatus)(0),            // 3: webcrawler.ChannelStatus
+	(*CrawlerRequest)(nil),        // 4: webcrawler.CrawlerRequest
+	(*BatchCrawlerRequest)(nil),   // 5: webcrawler.BatchCrawlerRequest
+	(*Empty)(nil),                 // 6: webcrawler.Empty
+	(*CrawlResult)(nil),           // 7: webcrawler.CrawlResult
+	(*BatchCrawlerResponse)(nil),  // 8: webcrawler.BatchCrawlerResponse
+	(*SubmitUrlsRequest)(nil),     // 9: webcrawler.SubmitUrlsRequest
+	(*SubmitUrlsResponse)(nil),    // 10: webcrawler.SubmitUrlsResponse
+	(*Job)(nil),                   // 11: webcrawler.Job
+	(*GetJobRequest)(nil),         // 12: webcrawler.GetJobRequest
+	(*WatchJobsRequest)(nil),      // 13: webcrawler.WatchJobsRequest
+	(*GetChannelRequest)(nil),     // 14: webcrawler.GetChannelRequest
+	(*Channel)(nil),               // 15: webcrawler.Channel
+	(*ChannelNameChange)(nil),     // 16: webcrawler.ChannelNameChange
+	(*ListChannelsRequest)(nil),   // 17: webcrawler.ListChannelsRequest
+	(*ListChannelsResponse)(nil),  // 18: webcrawler.ListChannelsResponse
+	(*timestamppb.Timestamp)(nil), // 19: google.protobuf.Timestamp
 }
 var file_webcrawler_service_proto_depIdxs = []int32{
-	0, // 0: webcrawler.BatchCrawlerRequest.urls:type_name -> webcrawler.CrawlerRequest
-	0, // 1: webcrawler.webCrawlerService.Crawl:input_type -> webcrawler.CrawlerRequest
-	1, // 2: webcrawler.webCrawlerService.CrawlBatch:input_type -> webcrawler.BatchCrawlerRequest
-	2, // 3: webcrawler.webCrawlerService.Crawl:output_type -> webcrawler.Empty
-	2, // 4: webcrawler.webCrawlerService.CrawlBatch:output_type -> webcrawler.Empty
-	3, // [3:5] is the sub-list for method output_type
-	1, // [1:3] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	0,  // 0: webcrawler.CrawlerRequest.priority:type_name -> webcrawler.CrawlPriority
+	4,  // 1: webcrawler.BatchCrawlerRequest.urls:type_name -> webcrawler.CrawlerRequest
+	1,  // 2: webcrawler.CrawlResult.status:type_name -> webcrawler.CrawlStatus
+	7,  // 3: webcrawler.BatchCrawlerResponse.results:type_name -> webcrawler.CrawlResult
+	0,  // 4: webcrawler.SubmitUrlsRequest.priority:type_name -> webcrawler.CrawlPriority
+	7,  // 5: webcrawler.SubmitUrlsResponse.result:type_name -> webcrawler.CrawlResult
+	2,  // 6: webcrawler.Job.status:type_name -> webcrawler.JobStatus
+	19, // 7: webcrawler.Job.created_at:type_name -> google.protobuf.Timestamp
+	19, // 8: webcrawler.Job.updated_at:type_name -> google.protobuf.Timestamp
+	0,  // 9: webcrawler.Job.priority:type_name -> webcrawler.CrawlPriority
+	19, // 10: webcrawler.Channel.updated_at:type_name -> google.protobuf.Timestamp
+	3,  // 11: webcrawler.Channel.status:type_name -> webcrawler.ChannelStatus
+	19, // 12: webcrawler.Channel.delisted_at:type_name -> google.protobuf.Timestamp
+	16, // 13: webcrawler.Channel.name_changes:type_name -> webcrawler.ChannelNameChange
+	19, // 14: webcrawler.ChannelNameChange.changed_at:type_name -> google.protobuf.Timestamp
+	19, // 15: webcrawler.ListChannelsRequest.updated_since:type_name -> google.protobuf.Timestamp
+	3,  // 16: webcrawler.ListChannelsRequest.status:type_name -> webcrawler.ChannelStatus
+	15, // 17: webcrawler.ListChannelsResponse.channels:type_name -> webcrawler.Channel
+	4,  // 18: webcrawler.webCrawlerService.Crawl:input_type -> webcrawler.CrawlerRequest
+	5,  // 19: webcrawler.webCrawlerService.CrawlBatch:input_type -> webcrawler.BatchCrawlerRequest
+	9,  // 20: webcrawler.webCrawlerService.SubmitUrls:input_type -> webcrawler.SubmitUrlsRequest
+	14, // 21: webcrawler.webCrawlerService.GetChannel:input_type -> webcrawler.GetChannelRequest
+	17, // 22: webcrawler.webCrawlerService.ListChannels:input_type -> webcrawler.ListChannelsRequest
+	12, // 23: webcrawler.webCrawlerService.GetJob:input_type -> webcrawler.GetJobRequest
+	13, // 24: webcrawler.webCrawlerService.WatchJobs:input_type -> webcrawler.WatchJobsRequest
+	7,  // 25: webcrawler.webCrawlerService.Crawl:output_type -> webcrawler.CrawlResult
+	8,  // 26: webcrawler.webCrawlerService.CrawlBatch:output_type -> webcrawler.BatchCrawlerResponse
+	10, // 27: webcrawler.webCrawlerService.SubmitUrls:output_type -> webcrawler.SubmitUrlsResponse
+	15, // 28: webcrawler.webCrawlerService.GetChannel:output_type -> webcrawler.Channel
+	18, // 29: webcrawler.webCrawlerService.ListChannels:output_type -> webcrawler.ListChannelsResponse
+	11, // 30: webcrawler.webCrawlerService.GetJob:output_type -> webcrawler.Job
+	11, // 31: webcrawler.webCrawlerService.WatchJobs:output_type -> webcrawler.Job
+	25, // [25:32] is the sub-list for method output_type
+	18, // [18:25] is the sub-list for method input_type
+	18, // [18:18] is the sub-list for extension type_name
+	18, // [18:18] is the sub-list for extension extendee
+	0,  // [0:18] is the sub-list for field type_name
 }
 
 func init() { file_webcrawler_service_proto_init() }
@@ -251,19 +1720,169 @@ func file_webcrawler_service_proto_init() {
 				return nil
 			}
 		}
+		file_webcrawler_service_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CrawlResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_webcrawler_service_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BatchCrawlerResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_webcrawler_service_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SubmitUrlsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_webcrawler_service_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SubmitUrlsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_webcrawler_service_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Job); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_webcrawler_service_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetJobRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_webcrawler_service_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WatchJobsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_webcrawler_service_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetChannelRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_webcrawler_service_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Channel); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_webcrawler_service_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ChannelNameChange); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_webcrawler_service_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListChannelsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_webcrawler_service_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListChannelsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_webcrawler_service_proto_msgTypes[10].OneofWrappers = []interface{}{
+		(*GetChannelRequest_Url)(nil),
+		(*GetChannelRequest_ChannelId)(nil),
 	}
+	file_webcrawler_service_proto_msgTypes[13].OneofWrappers = []interface{}{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_webcrawler_service_proto_rawDesc,
-			NumEnums:      0,
-			NumMessages:   3,
+			NumEnums:      4,
+			NumMessages:   15,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_webcrawler_service_proto_goTypes,
 		DependencyIndexes: file_webcrawler_service_proto_depIdxs,
+		EnumInfos:         file_webcrawler_service_proto_enumTypes,
 		MessageInfos:      file_webcrawler_service_proto_msgTypes,
 	}.Build()
 	File_webcrawler_service_proto = out.File
@@ -18,8 +18,16 @@ const _ = grpc.SupportPackageIsVersion7
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type WebCrawlerServiceClient interface {
-	Crawl(ctx context.Context, in *CrawlerRequest, opts ...grpc.CallOption) (*Empty, error)
-	CrawlBatch(ctx context.Context, in *BatchCrawlerRequest, opts ...grpc.CallOption) (*Empty, error)
+	Crawl(ctx context.Context, in *CrawlerRequest, opts ...grpc.CallOption) (*CrawlResult, error)
+	CrawlBatch(ctx context.Context, in *BatchCrawlerRequest, opts ...grpc.CallOption) (*BatchCrawlerResponse, error)
+	// SubmitUrls schedules the streamed urls and acknowledges each of them in the order they were sent,
+	// submission interrupted by a disconnect could be resumed from the offset following the last acknowledged one
+	SubmitUrls(ctx context.Context, opts ...grpc.CallOption) (WebCrawlerService_SubmitUrlsClient, error)
+	GetChannel(ctx context.Context, in *GetChannelRequest, opts ...grpc.CallOption) (*Channel, error)
+	ListChannels(ctx context.Context, in *ListChannelsRequest, opts ...grpc.CallOption) (*ListChannelsResponse, error)
+	GetJob(ctx context.Context, in *GetJobRequest, opts ...grpc.CallOption) (*Job, error)
+	// WatchJobs streams every change of the watched jobs and ends once all of them are finished
+	WatchJobs(ctx context.Context, in *WatchJobsRequest, opts ...grpc.CallOption) (WebCrawlerService_WatchJobsClient, error)
 }
 
 type webCrawlerServiceClient struct {
@@ -30,8 +38,8 @@ func NewWebCrawlerServiceClient(cc grpc.ClientConnInterface) WebCrawlerServiceCl
 	return &webCrawlerServiceClient{cc}
 }
 
-func (c *webCrawlerServiceClient) Crawl(ctx context.Context, in *CrawlerRequest, opts ...grpc.CallOption) (*Empty, error) {
-	out := new(Empty)
+func (c *webCrawlerServiceClient) Crawl(ctx context.Context, in *CrawlerRequest, opts ...grpc.CallOption) (*CrawlResult, error) {
+	out := new(CrawlResult)
 	err := c.cc.Invoke(ctx, "/webcrawler.webCrawlerService/Crawl", in, out, opts...)
 	if err != nil {
 		return nil, err
@@ -39,8 +47,8 @@ func (c *webCrawlerServiceClient) Crawl(ctx context.Context, in *CrawlerRequest,
 	return out, nil
 }
 
-func (c *webCrawlerServiceClient) CrawlBatch(ctx context.Context, in *BatchCrawlerRequest, opts ...grpc.CallOption) (*Empty, error) {
-	out := new(Empty)
+func (c *webCrawlerServiceClient) CrawlBatch(ctx context.Context, in *BatchCrawlerRequest, opts ...grpc.CallOption) (*BatchCrawlerResponse, error) {
+	out := new(BatchCrawlerResponse)
 	err := c.cc.Invoke(ctx, "/webcrawler.webCrawlerService/CrawlBatch", in, out, opts...)
 	if err != nil {
 		return nil, err
@@ -48,12 +56,110 @@ func (c *webCrawlerServiceClient) CrawlBatch(ctx context.Context, in *BatchCrawl
 	return out, nil
 }
 
+func (c *webCrawlerServiceClient) SubmitUrls(ctx context.Context, opts ...grpc.CallOption) (WebCrawlerService_SubmitUrlsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &WebCrawlerService_ServiceDesc.Streams[0], "/webcrawler.webCrawlerService/SubmitUrls", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &webCrawlerServiceSubmitUrlsClient{stream}
+	return x, nil
+}
+
+type WebCrawlerService_SubmitUrlsClient interface {
+	Send(*SubmitUrlsRequest) error
+	Recv() (*SubmitUrlsResponse, error)
+	grpc.ClientStream
+}
+
+type webCrawlerServiceSubmitUrlsClient struct {
+	grpc.ClientStream
+}
+
+func (x *webCrawlerServiceSubmitUrlsClient) Send(m *SubmitUrlsRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *webCrawlerServiceSubmitUrlsClient) Recv() (*SubmitUrlsResponse, error) {
+	m := new(SubmitUrlsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *webCrawlerServiceClient) GetChannel(ctx context.Context, in *GetChannelRequest, opts ...grpc.CallOption) (*Channel, error) {
+	out := new(Channel)
+	err := c.cc.Invoke(ctx, "/webcrawler.webCrawlerService/GetChannel", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *webCrawlerServiceClient) ListChannels(ctx context.Context, in *ListChannelsRequest, opts ...grpc.CallOption) (*ListChannelsResponse, error) {
+	out := new(ListChannelsResponse)
+	err := c.cc.Invoke(ctx, "/webcrawler.webCrawlerService/ListChannels", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *webCrawlerServiceClient) GetJob(ctx context.Context, in *GetJobRequest, opts ...grpc.CallOption) (*Job, error) {
+	out := new(Job)
+	err := c.cc.Invoke(ctx, "/webcrawler.webCrawlerService/GetJob", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *webCrawlerServiceClient) WatchJobs(ctx context.Context, in *WatchJobsRequest, opts ...grpc.CallOption) (WebCrawlerService_WatchJobsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &WebCrawlerService_ServiceDesc.Streams[1], "/webcrawler.webCrawlerService/WatchJobs", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &webCrawlerServiceWatchJobsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type WebCrawlerService_WatchJobsClient interface {
+	Recv() (*Job, error)
+	grpc.ClientStream
+}
+
+type webCrawlerServiceWatchJobsClient struct {
+	grpc.ClientStream
+}
+
+func (x *webCrawlerServiceWatchJobsClient) Recv() (*Job, error) {
+	m := new(Job)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // WebCrawlerServiceServer is the server API for WebCrawlerService service.
 // All implementations must embed UnimplementedWebCrawlerServiceServer
 // for forward compatibility
 type WebCrawlerServiceServer interface {
-	Crawl(context.Context, *CrawlerRequest) (*Empty, error)
-	CrawlBatch(context.Context, *BatchCrawlerRequest) (*Empty, error)
+	Crawl(context.Context, *CrawlerRequest) (*CrawlResult, error)
+	CrawlBatch(context.Context, *BatchCrawlerRequest) (*BatchCrawlerResponse, error)
+	// SubmitUrls schedules the streamed urls and acknowledges each of them in the order they were sent,
+	// submission interrupted by a disconnect could be resumed from the offset following the last acknowledged one
+	SubmitUrls(WebCrawlerService_SubmitUrlsServer) error
+	GetChannel(context.Context, *GetChannelRequest) (*Channel, error)
+	ListChannels(context.Context, *ListChannelsRequest) (*ListChannelsResponse, error)
+	GetJob(context.Context, *GetJobRequest) (*Job, error)
+	// WatchJobs streams every change of the watched jobs and ends once all of them are finished
+	WatchJobs(*WatchJobsRequest, WebCrawlerService_WatchJobsServer) error
 	mustEmbedUnimplementedWebCrawlerServiceServer()
 }
 
@@ -61,12 +167,27 @@ type WebCrawlerServiceServer interface {
 type UnimplementedWebCrawlerServiceServer struct {
 }
 
-func (UnimplementedWebCrawlerServiceServer) Crawl(context.Context, *CrawlerRequest) (*Empty, error) {
+func (UnimplementedWebCrawlerServiceServer) Crawl(context.Context, *CrawlerRequest) (*CrawlResult, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Crawl not implemented")
 }
-func (UnimplementedWebCrawlerServiceServer) CrawlBatch(context.Context, *BatchCrawlerRequest) (*Empty, error) {
+func (UnimplementedWebCrawlerServiceServer) CrawlBatch(context.Context, *BatchCrawlerRequest) (*BatchCrawlerResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CrawlBatch not implemented")
 }
+func (UnimplementedWebCrawlerServiceServer) SubmitUrls(WebCrawlerService_SubmitUrlsServer) error {
+	return status.Errorf(codes.Unimplemented, "method SubmitUrls not implemented")
+}
+func (UnimplementedWebCrawlerServiceServer) GetChannel(context.Context, *GetChannelRequest) (*Channel, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetChannel not implemented")
+}
+func (UnimplementedWebCrawlerServiceServer) ListChannels(context.Context, *ListChannelsRequest) (*ListChannelsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListChannels not implemented")
+}
+func (UnimplementedWebCrawlerServiceServer) GetJob(context.Context, *GetJobRequest) (*Job, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetJob not implemented")
+}
+func (UnimplementedWebCrawlerServiceServer) WatchJobs(*WatchJobsRequest, WebCrawlerService_WatchJobsServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchJobs not implemented")
+}
 func (UnimplementedWebCrawlerServiceServer) mustEmbedUnimplementedWebCrawlerServiceServer() {}
 
 // UnsafeWebCrawlerServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -116,6 +237,107 @@ func _WebCrawlerService_CrawlBatch_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _WebCrawlerService_SubmitUrls_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(WebCrawlerServiceServer).SubmitUrls(&webCrawlerServiceSubmitUrlsServer{stream})
+}
+
+type WebCrawlerService_SubmitUrlsServer interface {
+	Send(*SubmitUrlsResponse) error
+	Recv() (*SubmitUrlsRequest, error)
+	grpc.ServerStream
+}
+
+type webCrawlerServiceSubmitUrlsServer struct {
+	grpc.ServerStream
+}
+
+func (x *webCrawlerServiceSubmitUrlsServer) Send(m *SubmitUrlsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *webCrawlerServiceSubmitUrlsServer) Recv() (*SubmitUrlsRequest, error) {
+	m := new(SubmitUrlsRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _WebCrawlerService_GetChannel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetChannelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WebCrawlerServiceServer).GetChannel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/webcrawler.webCrawlerService/GetChannel",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WebCrawlerServiceServer).GetChannel(ctx, req.(*GetChannelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WebCrawlerService_ListChannels_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListChannelsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WebCrawlerServiceServer).ListChannels(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/webcrawler.webCrawlerService/ListChannels",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WebCrawlerServiceServer).ListChannels(ctx, req.(*ListChannelsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WebCrawlerService_GetJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WebCrawlerServiceServer).GetJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/webcrawler.webCrawlerService/GetJob",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WebCrawlerServiceServer).GetJob(ctx, req.(*GetJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WebCrawlerService_WatchJobs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchJobsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(WebCrawlerServiceServer).WatchJobs(m, &webCrawlerServiceWatchJobsServer{stream})
+}
+
+type WebCrawlerService_WatchJobsServer interface {
+	Send(*Job) error
+	grpc.ServerStream
+}
+
+type webCrawlerServiceWatchJobsServer struct {
+	grpc.ServerStream
+}
+
+func (x *webCrawlerServiceWatchJobsServer) Send(m *Job) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 // WebCrawlerService_ServiceDesc is the grpc.ServiceDesc for WebCrawlerService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -131,7 +353,31 @@ var WebCrawlerService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CrawlBatch",
 			Handler:    _WebCrawlerService_CrawlBatch_Handler,
 		},
+		{
+			MethodName: "GetChannel",
+			Handler:    _WebCrawlerService_GetChannel_Handler,
+		},
+		{
+			MethodName: "ListChannels",
+			Handler:    _WebCrawlerService_ListChannels_Handler,
+		},
+		{
+			MethodName: "GetJob",
+			Handler:    _WebCrawlerService_GetJob_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SubmitUrls",
+			Handler:       _WebCrawlerService_SubmitUrls_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "WatchJobs",
+			Handler:       _WebCrawlerService_WatchJobs_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "webcrawler/service.proto",
 }
@@ -3,10 +3,10 @@ package tests
 import (
 	"context"
 	"fmt"
-	"github.com/go-rod/rod"
-	"github.com/go-rod/rod/lib/launcher"
 	"github.com/stretchr/testify/require"
 	"go-web-crawler-service/config"
+	"go-web-crawler-service/domain"
+	"go-web-crawler-service/infrastructure"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"google.golang.org/grpc"
@@ -28,17 +28,19 @@ func resolveFakeSiteURL(t *testing.T) string {
 	return url
 }
 
-func getHeadlessBrowser(ctx context.Context) *rod.Browser {
-	u := launcher.New().Bin("/usr/bin/chromium-browser").MustLaunch()
+func getRodWebCrawler(t *testing.T) domain.ChannelWebCrawler {
+	browsers := infrastructure.NewBrowserPool("/usr/bin/chromium-browser", 1, 1, 0)
+	t.Cleanup(browsers.Close)
 
-	browser := rod.New().ControlURL(u).MustConnect()
-
-	go func() {
-		<-ctx.Done()
-		browser.MustClose()
-	}()
+	identities, err := infrastructure.DefaultIdentityPool()
+	require.NoError(t, err)
 
-	return browser
+	return infrastructure.NewRodRokuWebCrawler(
+		browsers,
+		getExtractionProfile(t),
+		infrastructure.NewRotatingIdentityProvider(identities, nil, 0, 0),
+		nil,
+	)
 }
 
 func connectGRPC(t *testing.T, ctx context.Context, url string, wg *sync.WaitGroup) *grpc.ClientConn {
@@ -82,3 +84,10 @@ func createDB(t *testing.T, ctx context.Context, cfg *config.Config, wg *sync.Wa
 
 	return client.Database(cfg.Database.DatabaseName)
 }
+
+func getExtractionProfile(t *testing.T) *infrastructure.ExtractionProfile {
+	profile, err := infrastructure.DefaultExtractionProfile()
+	require.NoError(t, err)
+
+	return profile
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"go-web-crawler-service/domain"
 	"go-web-crawler-service/infrastructure"
+	"net/http"
 	"testing"
 )
 
@@ -25,13 +26,12 @@ func TestIntegrationRodRokuWebCrawler_CrawlChannel_Success(t *testing.T) {
 	defer ctx.Done()
 
 	fakeSiteURL := resolveFakeSiteURL(t)
-	browser := getHeadlessBrowser(ctx)
-	crawler := infrastructure.NewRodRokuWebCrawler(browser)
+	crawler := getRodWebCrawler(t)
 
 	url, err := domain.NewURL(fakeSiteURL)
 	require.NoError(t, err)
 
-	channel, err := crawler.CrawlChannel(ctx, *url)
+	channel, err := crawler.CrawlChannel(ctx, *url, "")
 	require.NoError(t, err)
 	assert.EqualValues(t, testIntegrationApplicationName, channel.ApplicationName)
 	assert.EqualValues(t, testIntegrationApplicationRating, channel.Rating)
@@ -48,13 +48,12 @@ func TestIntegrationRodRokuWebCrawler_CrawlChannel_EmptyRating_Success(t *testin
 	defer ctx.Done()
 
 	fakeSiteURL := resolveFakeSiteURL(t)
-	browser := getHeadlessBrowser(ctx)
-	crawler := infrastructure.NewRodRokuWebCrawler(browser)
+	crawler := getRodWebCrawler(t)
 
 	url, err := domain.NewURL(fmt.Sprintf("%s/no-data.html", fakeSiteURL))
 	require.NoError(t, err)
 
-	channel, err := crawler.CrawlChannel(ctx, *url)
+	channel, err := crawler.CrawlChannel(ctx, *url, "")
 	require.NoError(t, err)
 	assert.EqualValues(t, 0, channel.Rating)
 	assert.EqualValues(t, 0, channel.NumberOfRatings)
@@ -69,17 +68,35 @@ func TestIntegrationRodRokuWebCrawler_CrawlChannel_NoElementsFound_ReturnsError(
 	defer ctx.Done()
 
 	fakeSiteURL := resolveFakeSiteURL(t)
-	browser := getHeadlessBrowser(ctx)
-	crawler := infrastructure.NewRodRokuWebCrawler(browser)
+	crawler := getRodWebCrawler(t)
 
 	url, err := domain.NewURL(fmt.Sprintf("%s/invalid.html", fakeSiteURL))
 	require.NoError(t, err)
 
-	_, err = crawler.CrawlChannel(ctx, *url)
+	_, err = crawler.CrawlChannel(ctx, *url, "")
 	require.Error(t, err)
 	require.ErrorIs(t, err, context.DeadlineExceeded)
 }
 
+func TestIntegrationRodRokuWebCrawler_CrawlChannel_ChannelUnavailable_ReturnsNotFound(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+	defer ctx.Done()
+
+	fakeSiteURL := resolveFakeSiteURL(t)
+	crawler := getRodWebCrawler(t)
+
+	url, err := domain.NewURL(fmt.Sprintf("%s/unavailable.html", fakeSiteURL))
+	require.NoError(t, err)
+
+	_, err = crawler.CrawlChannel(ctx, *url, "")
+	require.Error(t, err)
+	assert.Equal(t, domain.CrawlErrorNotFound, domain.CrawlErrorKindOf(err))
+}
+
 func TestIntegrationRodRokuWebCrawler_CrawlChannel_PageNotFound_ReturnsError(t *testing.T) {
 	if testing.Short() {
 		t.Skip()
@@ -88,12 +105,32 @@ func TestIntegrationRodRokuWebCrawler_CrawlChannel_PageNotFound_ReturnsError(t *
 	ctx := context.Background()
 	defer ctx.Done()
 
-	browser := getHeadlessBrowser(ctx)
-	crawler := infrastructure.NewRodRokuWebCrawler(browser)
+	crawler := getRodWebCrawler(t)
 
 	url, err := domain.NewURL("http://localhost:9999")
 	require.NoError(t, err)
 
-	_, err = crawler.CrawlChannel(ctx, *url)
+	_, err = crawler.CrawlChannel(ctx, *url, "")
 	require.Error(t, err)
 }
+
+func TestIntegrationHttpRokuWebCrawler_CrawlChannel_Success(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+
+	fakeSiteURL := resolveFakeSiteURL(t)
+	crawler := infrastructure.NewHttpRokuWebCrawler(&http.Client{}, getExtractionProfile(t))
+
+	url, err := domain.NewURL(fakeSiteURL)
+	require.NoError(t, err)
+
+	channel, err := crawler.CrawlChannel(ctx, *url, "")
+	require.NoError(t, err)
+	assert.EqualValues(t, testIntegrationApplicationName, channel.ApplicationName)
+	assert.EqualValues(t, testIntegrationApplicationRating, channel.Rating)
+	assert.EqualValues(t, testIntegrationApplicationRatingsAmount, channel.NumberOfRatings)
+	assert.EqualValues(t, fakeSiteURL, channel.Url)
+}
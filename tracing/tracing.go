@@ -0,0 +1,99 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "go-web-crawler-service"
+
+// Span exporters
+const (
+	ExporterOTLP = "otlp"
+	ExporterNoop = "noop"
+)
+
+// Init installs the global tracer provider exporting spans with given exporter and the W3C trace context
+// propagator, the returned function flushes the remaining spans
+func Init(ctx context.Context, serviceName string, exporter string, endpoint string, sampleRatio float64) (
+	func(ctx context.Context) error,
+	error,
+) {
+	spanExporter, err := newExporter(ctx, exporter, endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(spanExporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio))),
+		sdktrace.WithResource(
+			resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceNameKey.String(serviceName)),
+		),
+	)
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(
+		propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}),
+	)
+
+	return provider.Shutdown, nil
+}
+
+func newExporter(ctx context.Context, exporter string, endpoint string) (sdktrace.SpanExporter, error) {
+	switch exporter {
+	case ExporterOTLP:
+		otlpExporter, err := otlptracegrpc.New(
+			ctx,
+			otlptracegrpc.WithEndpoint(endpoint),
+			otlptracegrpc.WithInsecure(),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OTLP exporter, error: %w", err)
+		}
+
+		return otlpExporter, nil
+	case ExporterNoop:
+		return NewNoopExporter(), nil
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter: %s", exporter)
+	}
+}
+
+// Tracer returns the tracer of the service, spans are dropped until Init is called
+func Tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// End records the error, if any, on the span and ends it
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
+
+type noopExporter struct{}
+
+// NewNoopExporter creates exporter dropping all the spans, so the code is traced without a collector, e.g. in tests
+func NewNoopExporter() *noopExporter {
+	return &noopExporter{}
+}
+
+func (e *noopExporter) ExportSpans(context.Context, []sdktrace.ReadOnlySpan) error {
+	return nil
+}
+
+func (e *noopExporter) Shutdown(context.Context) error {
+	return nil
+}